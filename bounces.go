@@ -0,0 +1,288 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+// BounceType classifies why a message bounced, mirroring listmonk's
+// hard/soft/complaint split: hard bounces (mailbox doesn't exist) should
+// stop further sends immediately, soft bounces (mailbox full, greylisted)
+// are retried, and complaints (recipient hit "report spam") should stop
+// sends without necessarily meaning the address is dead.
+type BounceType string
+
+const (
+	BounceHard      BounceType = "hard"
+	BounceSoft      BounceType = "soft"
+	BounceComplaint BounceType = "complaint"
+)
+
+// Bounce is one row of the bounces table: a single DSN, VERP hit, or
+// webhook delivery naming one recipient.
+type Bounce struct {
+	MessageID string
+	Recipient string
+	Type      BounceType
+	Reason    string
+	CreatedAt time.Time
+}
+
+// defaultBounceBlockThreshold is how many bounces a recipient accumulates
+// before being auto-flagged blocked, unless BOUNCE_BLOCK_THRESHOLD
+// overrides it.
+const defaultBounceBlockThreshold = 5
+
+// bounceBlockThreshold reads BOUNCE_BLOCK_THRESHOLD, falling back to
+// defaultBounceBlockThreshold for an unset or invalid value.
+func bounceBlockThreshold() int {
+	raw := os.Getenv("BOUNCE_BLOCK_THRESHOLD")
+	if raw == "" {
+		return defaultBounceBlockThreshold
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n <= 0 {
+		return defaultBounceBlockThreshold
+	}
+	return n
+}
+
+// createBounceTables creates the bounces and recipients tables used by the
+// bounce-handling subsystem, if they don't already exist.
+func createBounceTables(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS bounces (
+			message_id TEXT NOT NULL,
+			recipient TEXT NOT NULL,
+			type TEXT NOT NULL,
+			reason TEXT,
+			created_at INTEGER NOT NULL,
+			PRIMARY KEY (message_id, recipient, type)
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create bounces table: %v", err)
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS recipients (
+			email TEXT PRIMARY KEY,
+			bounce_count INTEGER NOT NULL DEFAULT 0,
+			status TEXT NOT NULL DEFAULT 'active',
+			last_bounce_at INTEGER
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create recipients table: %v", err)
+	}
+
+	_, err = db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_bounces_recipient ON bounces(recipient);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create bounces index: %v", err)
+	}
+
+	return nil
+}
+
+// recordBounce inserts b into bounces and bumps recipients' running count,
+// flagging the recipient blocked once it reaches bounceBlockThreshold.
+func (db *DB) recordBounce(b *Bounce) error {
+	if b.CreatedAt.IsZero() {
+		b.CreatedAt = time.Now()
+	}
+
+	_, err := db.Exec(`
+		INSERT OR IGNORE INTO bounces (message_id, recipient, type, reason, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, b.MessageID, b.Recipient, string(b.Type), b.Reason, b.CreatedAt.Unix())
+	if err != nil {
+		return fmt.Errorf("failed to insert bounce: %v", err)
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO recipients (email, bounce_count, status, last_bounce_at)
+		VALUES (?, 1, 'active', ?)
+		ON CONFLICT(email) DO UPDATE SET
+			bounce_count = bounce_count + 1,
+			last_bounce_at = excluded.last_bounce_at
+	`, b.Recipient, b.CreatedAt.Unix())
+	if err != nil {
+		return fmt.Errorf("failed to upsert recipient: %v", err)
+	}
+
+	var count int
+	if err := db.QueryRow(`SELECT bounce_count FROM recipients WHERE email = ?`, b.Recipient).Scan(&count); err != nil {
+		return fmt.Errorf("failed to read recipient bounce count: %v", err)
+	}
+	if count >= bounceBlockThreshold() {
+		if _, err := db.Exec(`UPDATE recipients SET status = 'blocked' WHERE email = ?`, b.Recipient); err != nil {
+			return fmt.Errorf("failed to block recipient: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// headerValue returns the first header in headers named name
+// (case-insensitive), or "".
+func headerValue(headers []*gmail.MessagePartHeader, name string) string {
+	for _, h := range headers {
+		if strings.EqualFold(h.Name, name) {
+			return h.Value
+		}
+	}
+	return ""
+}
+
+// findPart does a depth-first search of root for the first part whose
+// MimeType matches mimeType.
+func findPart(root *gmail.MessagePart, mimeType string) *gmail.MessagePart {
+	if root == nil {
+		return nil
+	}
+	if strings.EqualFold(root.MimeType, mimeType) {
+		return root
+	}
+	for _, part := range root.Parts {
+		if found := findPart(part, mimeType); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// decodePartBody returns part's base64url-decoded body as a string, or ""
+// if part has none.
+func decodePartBody(part *gmail.MessagePart) string {
+	if part == nil || part.Body == nil || part.Body.Data == "" {
+		return ""
+	}
+	data, err := base64.URLEncoding.DecodeString(part.Body.Data)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// deliveryStatusFields parses an RFC 3464 message/delivery-status body
+// into its per-recipient field block (the second block onward; the first
+// is the per-message block). It returns the fields of the first
+// per-recipient block, which is enough for the single-recipient case this
+// module deals with (one Gmail message, one "To").
+func deliveryStatusFields(body string) map[string]string {
+	blocks := strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n\n")
+	for _, block := range blocks {
+		fields := make(map[string]string)
+		for _, line := range strings.Split(block, "\n") {
+			name, value, ok := strings.Cut(line, ":")
+			if !ok {
+				continue
+			}
+			fields[strings.ToLower(strings.TrimSpace(name))] = strings.TrimSpace(value)
+		}
+		if _, ok := fields["action"]; ok {
+			return fields
+		}
+	}
+	return nil
+}
+
+// statusBounceType maps an RFC 3464 Action/Status pair to a BounceType.
+// Status' first digit (enhanced status code class, RFC 3463) takes
+// precedence over Action since some MTAs report "failed" for what's
+// really a 4.x.x transient condition.
+func statusBounceType(action, status string) BounceType {
+	if strings.HasPrefix(status, "5.") {
+		return BounceHard
+	}
+	if strings.HasPrefix(status, "4.") {
+		return BounceSoft
+	}
+	if strings.EqualFold(action, "delayed") {
+		return BounceSoft
+	}
+	return BounceHard
+}
+
+// verpRecipient extracts the original recipient address from a VERP
+// Return-Path such as "bounce+alice=example.com@mail.example.org",
+// returning ("alice@example.com", true). It reports false for a
+// Return-Path that isn't VERP-encoded.
+var verpPattern = regexp.MustCompile(`^[^@+]+\+([^=@]+)=([^@]+)@`)
+
+func verpRecipient(returnPath string) (string, bool) {
+	returnPath = strings.Trim(returnPath, "<>")
+	m := verpPattern.FindStringSubmatch(returnPath)
+	if m == nil {
+		return "", false
+	}
+	return m[1] + "@" + m[2], true
+}
+
+// detectBounce inspects msg for an RFC 3464 delivery-status report, an
+// RFC 3465/5965 feedback report (a spam complaint), or a VERP-encoded
+// Return-Path, returning the Bounce it describes if any of those match.
+func detectBounce(msg *gmail.Message) (*Bounce, bool) {
+	if msg.Payload == nil {
+		return nil, false
+	}
+
+	if dsn := findPart(msg.Payload, "message/delivery-status"); dsn != nil {
+		fields := deliveryStatusFields(decodePartBody(dsn))
+		if fields != nil {
+			recipient := fields["final-recipient"]
+			if idx := strings.LastIndex(recipient, ";"); idx != -1 {
+				recipient = strings.TrimSpace(recipient[idx+1:])
+			}
+			if recipient == "" {
+				recipient = headerValue(msg.Payload.Headers, "To")
+			}
+			return &Bounce{
+				MessageID: msg.Id,
+				Recipient: recipient,
+				Type:      statusBounceType(fields["action"], fields["status"]),
+				Reason:    fields["diagnostic-code"],
+			}, true
+		}
+	}
+
+	if findPart(msg.Payload, "message/feedback-report") != nil {
+		return &Bounce{
+			MessageID: msg.Id,
+			Recipient: headerValue(msg.Payload.Headers, "To"),
+			Type:      BounceComplaint,
+			Reason:    "abuse feedback report (ARF)",
+		}, true
+	}
+
+	if returnPath := headerValue(msg.Payload.Headers, "Return-Path"); returnPath != "" {
+		if recipient, ok := verpRecipient(returnPath); ok {
+			return &Bounce{
+				MessageID: msg.Id,
+				Recipient: recipient,
+				Type:      BounceSoft,
+				Reason:    "VERP return-path with no parseable DSN",
+			}, true
+		}
+	}
+
+	return nil, false
+}
+
+// logBounceDetectionFailure is a small seam so the webhook handlers (which
+// don't have a *Bounce to detect, only a payload to parse) can log
+// consistently with detectBounce's callers.
+func logBounceDetectionFailure(source string, err error) {
+	log.Printf("bounces: failed to parse %s payload: %v", source, err)
+}
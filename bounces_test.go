@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+func encodeBase64URL(s string) string {
+	return base64.URLEncoding.EncodeToString([]byte(s))
+}
+
+func TestVerpRecipient(t *testing.T) {
+	cases := []struct {
+		returnPath string
+		want       string
+		ok         bool
+	}{
+		{"<bounce+alice=example.com@mail.example.org>", "alice@example.com", true},
+		{"bounce+bob=example.org@relay.example.com", "bob@example.org", true},
+		{"<no-verp@example.com>", "", false},
+	}
+	for _, c := range cases {
+		got, ok := verpRecipient(c.returnPath)
+		if ok != c.ok || got != c.want {
+			t.Errorf("verpRecipient(%q) = (%q, %v), want (%q, %v)", c.returnPath, got, ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestStatusBounceType(t *testing.T) {
+	cases := []struct {
+		action, status string
+		want           BounceType
+	}{
+		{"failed", "5.1.1", BounceHard},
+		{"delayed", "4.2.2", BounceSoft},
+		{"delayed", "", BounceSoft},
+		{"failed", "", BounceHard},
+	}
+	for _, c := range cases {
+		if got := statusBounceType(c.action, c.status); got != c.want {
+			t.Errorf("statusBounceType(%q, %q) = %q, want %q", c.action, c.status, got, c.want)
+		}
+	}
+}
+
+func TestDeliveryStatusFields(t *testing.T) {
+	body := "Reporting-MTA: dns; mail.example.com\r\n\r\n" +
+		"Final-Recipient: rfc822; bob@example.com\r\n" +
+		"Action: failed\r\n" +
+		"Status: 5.1.1\r\n" +
+		"Diagnostic-Code: smtp; 550 5.1.1 unknown user\r\n"
+
+	fields := deliveryStatusFields(body)
+	if fields == nil {
+		t.Fatal("expected a non-nil recipient block")
+	}
+	if fields["action"] != "failed" || fields["status"] != "5.1.1" {
+		t.Errorf("fields = %+v, want action=failed status=5.1.1", fields)
+	}
+}
+
+func TestDetectBounceFindsDSN(t *testing.T) {
+	msg := &gmail.Message{
+		Id: "msg-1",
+		Payload: &gmail.MessagePart{
+			MimeType: "multipart/report",
+			Headers: []*gmail.MessagePartHeader{
+				{Name: "To", Value: "sender@example.com"},
+			},
+			Parts: []*gmail.MessagePart{
+				{MimeType: "text/plain"},
+				{
+					MimeType: "message/delivery-status",
+					Body: &gmail.MessagePartBody{
+						Data: encodeBase64URL("Final-Recipient: rfc822; bob@example.com\r\nAction: failed\r\nStatus: 5.1.1\r\n"),
+					},
+				},
+			},
+		},
+	}
+
+	bounce, ok := detectBounce(msg)
+	if !ok {
+		t.Fatal("expected detectBounce to find a DSN")
+	}
+	if bounce.Recipient != "bob@example.com" || bounce.Type != BounceHard {
+		t.Errorf("bounce = %+v, want recipient bob@example.com, type hard", bounce)
+	}
+}
+
+func TestDetectBounceIgnoresOrdinaryMessage(t *testing.T) {
+	msg := &gmail.Message{
+		Id: "msg-2",
+		Payload: &gmail.MessagePart{
+			MimeType: "text/plain",
+			Headers: []*gmail.MessagePartHeader{
+				{Name: "To", Value: "sender@example.com"},
+			},
+		},
+	}
+
+	if _, ok := detectBounce(msg); ok {
+		t.Error("expected detectBounce to ignore a plain message with no DSN or VERP return-path")
+	}
+}
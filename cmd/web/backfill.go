@@ -0,0 +1,478 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/types"
+	"github.com/go-chi/chi/v5"
+	"golang.org/x/sync/errgroup"
+)
+
+// defaultBackfillWorkers bounds how many symbols a BackfillJob fetches
+// concurrently when the request doesn't specify "workers".
+const defaultBackfillWorkers = 8
+
+// backfillCancels holds the cancel func for every currently running
+// BackfillJob, keyed by job id, so DELETE /backfill/{id} can stop one.
+// Entries are removed once the job's run loop returns.
+var (
+	backfillMu      sync.Mutex
+	backfillCancels = make(map[int64]context.CancelFunc)
+)
+
+// ensureBackfillTables creates backfill_jobs and backfill_job_symbols if
+// they don't already exist. backfill_jobs tracks one row per run;
+// backfill_job_symbols tracks each symbol's own status and last completed
+// bar date, so a re-run of the same job parameters (new job row, same
+// tickers) can resume from where a prior run left off instead of
+// re-fetching data that's already in stock_historical_data.
+func ensureBackfillTables(ctx context.Context, db *sql.DB) error {
+	if _, err := db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS backfill_jobs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			status TEXT NOT NULL,
+			provider TEXT,
+			interval TEXT,
+			from_date INTEGER,
+			to_date INTEGER,
+			total INTEGER NOT NULL,
+			processed INTEGER NOT NULL DEFAULT 0,
+			failed INTEGER NOT NULL DEFAULT 0,
+			error TEXT,
+			created_at INTEGER NOT NULL,
+			updated_at INTEGER NOT NULL
+		)
+	`); err != nil {
+		return fmt.Errorf("backfill: failed to create backfill_jobs: %w", err)
+	}
+
+	if _, err := db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS backfill_job_symbols (
+			job_id INTEGER NOT NULL,
+			symbol TEXT NOT NULL,
+			status TEXT NOT NULL,
+			rows INTEGER NOT NULL DEFAULT 0,
+			last_bar_date INTEGER,
+			error TEXT,
+			updated_at INTEGER NOT NULL,
+			PRIMARY KEY (job_id, symbol)
+		)
+	`); err != nil {
+		return fmt.Errorf("backfill: failed to create backfill_job_symbols: %w", err)
+	}
+
+	return nil
+}
+
+// backfillStartHandler inserts a new BackfillJob row (and one
+// backfill_job_symbols row per ticker, all "pending"), then starts its
+// worker pool in the background and returns the job id immediately rather
+// than blocking for the whole run. Query params mirror
+// fillHistoricalDataHandler's: "provider", "interval", "from"/"to"
+// (default last 2 years), "table" (symbols source, default the active
+// S&P 500 tickers), and "workers" (default defaultBackfillWorkers).
+func backfillStartHandler(w http.ResponseWriter, r *http.Request) {
+	if err := ensureBackfillTables(r.Context(), BacktestDB.DB()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	providerName := r.URL.Query().Get("provider")
+	interval := r.URL.Query().Get("interval")
+	endDate, startDate, err := parseHistoricalWindow(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	workers := defaultBackfillWorkers
+	if raw := r.URL.Query().Get("workers"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			http.Error(w, "workers must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		workers = n
+	}
+
+	var symbols []string
+	if table := r.URL.Query().Get("table"); table != "" {
+		symbols, err = getSymbolsFromTable(r.Context(), BacktestDB, table)
+	} else {
+		symbols, err = getActiveSP500Tickers(r.Context(), BacktestDB)
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to load symbols: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if len(symbols) == 0 {
+		http.Error(w, "no symbols to back fill", http.StatusBadRequest)
+		return
+	}
+
+	jobID, err := insertBackfillJob(r.Context(), BacktestDB.DB(), providerName, interval, startDate, endDate, symbols)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	backfillMu.Lock()
+	backfillCancels[jobID] = cancel
+	backfillMu.Unlock()
+
+	go runBackfillJob(ctx, jobID, providerName, interval, startDate, endDate, symbols, workers)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]int64{"id": jobID})
+}
+
+// insertBackfillJob records a new job row (status "pending") plus one
+// "pending" backfill_job_symbols row per symbol, returning the job's id.
+func insertBackfillJob(ctx context.Context, db *sql.DB, provider, interval string, start, end time.Time, symbols []string) (int64, error) {
+	now := time.Now().Unix()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("backfill: failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO backfill_jobs (status, provider, interval, from_date, to_date, total, created_at, updated_at)
+		VALUES ('pending', ?, ?, ?, ?, ?, ?, ?)
+	`, provider, interval, start.Unix(), end.Unix(), len(symbols), now, now)
+	if err != nil {
+		return 0, fmt.Errorf("backfill: failed to insert job: %w", err)
+	}
+	jobID, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("backfill: failed to read new job id: %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO backfill_job_symbols (job_id, symbol, status, updated_at)
+		VALUES (?, ?, 'pending', ?)
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("backfill: failed to prepare symbol insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, symbol := range symbols {
+		if _, err := stmt.ExecContext(ctx, jobID, symbol, now); err != nil {
+			return 0, fmt.Errorf("backfill: failed to insert symbol row for %s: %w", symbol, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("backfill: failed to commit job insert: %w", err)
+	}
+	return jobID, nil
+}
+
+// runBackfillJob processes every symbol through a bounded worker pool,
+// persisting each symbol's outcome as it finishes, then finalizes the job
+// row once all symbols are done or ctx is canceled. It always returns
+// (errors are per-symbol, see processBackfillSymbol), so callers run it in
+// its own goroutine and learn the outcome via GET /backfill/{id}.
+func runBackfillJob(ctx context.Context, jobID int64, provider, interval string, start, end time.Time, symbols []string, workers int) {
+	markBackfillJobStatus(context.Background(), jobID, "running")
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(workers)
+
+	for _, symbol := range symbols {
+		symbol := symbol
+		g.Go(func() error {
+			if gctx.Err() != nil {
+				return nil
+			}
+			processBackfillSymbol(gctx, jobID, provider, interval, symbol, start, end)
+			return nil
+		})
+	}
+	g.Wait()
+
+	backfillMu.Lock()
+	delete(backfillCancels, jobID)
+	backfillMu.Unlock()
+
+	finalizeBackfillJob(context.Background(), jobID, ctx.Err() != nil)
+}
+
+// processBackfillSymbol fetches and saves one symbol's missing range,
+// resuming from the latest bar already in stock_historical_data (if any)
+// instead of start, then records the outcome on its backfill_job_symbols
+// row and rolls it into backfill_jobs' processed/failed counters.
+func processBackfillSymbol(ctx context.Context, jobID int64, provider, interval, symbol string, start, end time.Time) {
+	markBackfillSymbolStatus(ctx, jobID, symbol, "running", "")
+
+	from := start
+	if last, ok, err := lastBackfilledBar(ctx, symbol); err == nil && ok {
+		resumeFrom := last.AddDate(0, 0, 1)
+		if resumeFrom.After(from) {
+			from = resumeFrom
+		}
+	}
+
+	if !from.Before(end) {
+		completeBackfillSymbol(ctx, jobID, symbol, 0, last(from, end))
+		return
+	}
+
+	data, err := fetchHistoricalData(ctx, symbol, provider, from, end, interval)
+	if err != nil {
+		failBackfillSymbol(ctx, jobID, symbol, err)
+		return
+	}
+
+	if err := saveHistoricalData(ctx, BacktestDB, symbol, data); err != nil {
+		failBackfillSymbol(ctx, jobID, symbol, err)
+		return
+	}
+
+	newest := from
+	for _, d := range data {
+		if d.Date.After(newest) {
+			newest = d.Date
+		}
+	}
+	completeBackfillSymbol(ctx, jobID, symbol, len(data), newest)
+}
+
+// last returns whichever of a, b is later, used by processBackfillSymbol
+// to report a last_bar_date even when there was nothing new to fetch.
+func last(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// lastBackfilledBar returns the most recent date already stored for symbol
+// in stock_historical_data, if any.
+func lastBackfilledBar(ctx context.Context, symbol string) (time.Time, bool, error) {
+	var ts sql.NullInt64
+	err := BacktestDB.QueryRowContext(ctx, `
+		SELECT MAX(date) FROM stock_historical_data WHERE symbol = ?
+	`, symbol).Scan(&ts)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("backfill: failed to query last bar for %s: %w", symbol, err)
+	}
+	if !ts.Valid {
+		return time.Time{}, false, nil
+	}
+	return time.Unix(ts.Int64, 0), true, nil
+}
+
+// markBackfillJobStatus updates a job's status column.
+func markBackfillJobStatus(ctx context.Context, jobID int64, status string) {
+	if _, err := BacktestDB.ExecContext(ctx, `
+		UPDATE backfill_jobs SET status = ?, updated_at = ? WHERE id = ?
+	`, status, time.Now().Unix(), jobID); err != nil {
+		slog.Error("backfill: failed to set job status", "job_id", jobID, "status", status, "error", err)
+	}
+}
+
+// markBackfillSymbolStatus updates one symbol's status (and error, if any)
+// within a job.
+func markBackfillSymbolStatus(ctx context.Context, jobID int64, symbol, status, errMsg string) {
+	if _, err := BacktestDB.ExecContext(ctx, `
+		UPDATE backfill_job_symbols SET status = ?, error = ?, updated_at = ? WHERE job_id = ? AND symbol = ?
+	`, status, errMsg, time.Now().Unix(), jobID, symbol); err != nil {
+		slog.Error("backfill: failed to set symbol status", "job_id", jobID, "symbol", symbol, "status", status, "error", err)
+	}
+}
+
+// completeBackfillSymbol records symbol as done and increments the job's
+// processed counter in one transaction (a SQLite BEGIN IMMEDIATE one, see
+// dsnForDriver's _txlock=immediate) so the up-to-fillConcurrency workers
+// updating backfill_jobs concurrently fail fast on contention instead of
+// deadlocking mid-transaction.
+func completeBackfillSymbol(ctx context.Context, jobID int64, symbol string, rows int, lastBar time.Time) {
+	if err := withBackfillTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE backfill_job_symbols SET status = 'done', rows = ?, last_bar_date = ?, error = NULL, updated_at = ? WHERE job_id = ? AND symbol = ?
+		`, rows, lastBar.Unix(), time.Now().Unix(), jobID, symbol); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `
+			UPDATE backfill_jobs SET processed = processed + 1, updated_at = ? WHERE id = ?
+		`, time.Now().Unix(), jobID)
+		return err
+	}); err != nil {
+		slog.Error("backfill: failed to record symbol completion", "job_id", jobID, "symbol", symbol, "error", err)
+	}
+}
+
+// failBackfillSymbol records symbol as failed and increments the job's
+// failed counter, under the same BEGIN IMMEDIATE transaction as
+// completeBackfillSymbol.
+func failBackfillSymbol(ctx context.Context, jobID int64, symbol string, symbolErr error) {
+	if err := withBackfillTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE backfill_job_symbols SET status = 'failed', error = ?, updated_at = ? WHERE job_id = ? AND symbol = ?
+		`, symbolErr.Error(), time.Now().Unix(), jobID, symbol); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `
+			UPDATE backfill_jobs SET failed = failed + 1, updated_at = ? WHERE id = ?
+		`, time.Now().Unix(), jobID)
+		return err
+	}); err != nil {
+		slog.Error("backfill: failed to record symbol failure", "job_id", jobID, "symbol", symbol, "error", err)
+	}
+}
+
+// withBackfillTx runs fn inside a transaction against BacktestDB,
+// committing on success and rolling back on any error fn returns.
+func withBackfillTx(ctx context.Context, fn func(*sql.Tx) error) error {
+	tx, err := BacktestDB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("backfill: failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// finalizeBackfillJob sets a job's terminal status once every symbol has
+// been attempted or the run was canceled: "canceled" if ctx was canceled
+// before every symbol finished, "failed" if every symbol failed, "done"
+// otherwise (per-symbol failures, if any, are still visible via
+// GET /backfill/{id}'s failed count and symbol list).
+func finalizeBackfillJob(ctx context.Context, jobID int64, canceled bool) {
+	var total, processed, failed int
+	err := BacktestDB.QueryRowContext(ctx, `
+		SELECT total, processed, failed FROM backfill_jobs WHERE id = ?
+	`, jobID).Scan(&total, &processed, &failed)
+	if err != nil {
+		slog.Error("backfill: failed to read counters for finalize", "job_id", jobID, "error", err)
+		return
+	}
+
+	status := "done"
+	switch {
+	case canceled && processed+failed < total:
+		status = "canceled"
+	case total > 0 && failed == total:
+		status = "failed"
+	}
+	markBackfillJobStatus(ctx, jobID, status)
+}
+
+// backfillJobView is GET /backfill/{id}'s response shape: the job's own
+// progress plus one entry per symbol so a caller can see exactly what
+// failed and why.
+type backfillJobView struct {
+	ID        int64                `json:"id"`
+	Status    string               `json:"status"`
+	Provider  string               `json:"provider"`
+	Interval  string               `json:"interval"`
+	Total     int                  `json:"total"`
+	Processed int                  `json:"processed"`
+	Failed    int                  `json:"failed"`
+	Error     string               `json:"error,omitempty"`
+	Symbols   []backfillSymbolView `json:"symbols"`
+}
+
+// backfillSymbolView is one symbol's row within backfillJobView.
+type backfillSymbolView struct {
+	Symbol      string `json:"symbol"`
+	Status      string `json:"status"`
+	Rows        int    `json:"rows"`
+	LastBarDate string `json:"last_bar_date,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// backfillStatusHandler returns a BackfillJob's progress, including every
+// symbol's own status, for GET /backfill/{id}.
+func backfillStatusHandler(w http.ResponseWriter, r *http.Request) {
+	jobID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid job id", http.StatusBadRequest)
+		return
+	}
+
+	view := backfillJobView{ID: jobID}
+	var jobErr sql.NullString
+	err = BacktestDB.QueryRowContext(r.Context(), `
+		SELECT status, provider, interval, total, processed, failed, error
+		FROM backfill_jobs WHERE id = ?
+	`, jobID).Scan(&view.Status, &view.Provider, &view.Interval, &view.Total, &view.Processed, &view.Failed, &jobErr)
+	if err == sql.ErrNoRows {
+		http.Error(w, "backfill job not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, fmt.Sprintf("failed to query job: %v", err), http.StatusInternalServerError)
+		return
+	}
+	view.Error = jobErr.String
+
+	rows, err := BacktestDB.QueryContext(r.Context(), `
+		SELECT symbol, status, rows, last_bar_date, error
+		FROM backfill_job_symbols WHERE job_id = ? ORDER BY symbol
+	`, jobID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to query symbols: %v", err), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s backfillSymbolView
+		var lastBar sql.NullInt64
+		var symErr sql.NullString
+		if err := rows.Scan(&s.Symbol, &s.Status, &s.Rows, &lastBar, &symErr); err != nil {
+			http.Error(w, fmt.Sprintf("failed to scan symbol row: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if lastBar.Valid {
+			s.LastBarDate = time.Unix(lastBar.Int64, 0).Format("2006-01-02")
+		}
+		s.Error = symErr.String
+		view.Symbols = append(view.Symbols, s)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(view)
+}
+
+// backfillCancelHandler cancels a running BackfillJob's context for
+// DELETE /backfill/{id}. The job's symbols already in flight still finish
+// (or fail) individually; runBackfillJob marks the job "canceled" once
+// they've all returned. Canceling a job that isn't currently running (it
+// already finished, or the process restarted since it started) reports
+// 404 rather than silently doing nothing.
+func backfillCancelHandler(w http.ResponseWriter, r *http.Request) {
+	jobID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid job id", http.StatusBadRequest)
+		return
+	}
+
+	backfillMu.Lock()
+	cancel, ok := backfillCancels[jobID]
+	backfillMu.Unlock()
+	if !ok {
+		http.Error(w, "backfill job is not running", http.StatusNotFound)
+		return
+	}
+	cancel()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(types.HandlerResponse{Success: true, Message: "cancellation requested"})
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// exportRowGroupSize bounds how many rows exportHandler buffers into a
+// single Parquet row group before flushing, so a symbol with a long history
+// doesn't grow one row group unboundedly.
+const exportRowGroupSize = 50000
+
+// exportRow is the Parquet row shape for a bar of stock_historical_data,
+// mirroring the CSV columns below.
+type exportRow struct {
+	Date     int64   `parquet:"name=date, type=INT64"`
+	Open     float64 `parquet:"name=open, type=DOUBLE"`
+	High     float64 `parquet:"name=high, type=DOUBLE"`
+	Low      float64 `parquet:"name=low, type=DOUBLE"`
+	Close    float64 `parquet:"name=close, type=DOUBLE"`
+	AdjClose float64 `parquet:"name=adj_close, type=DOUBLE"`
+	Volume   int64   `parquet:"name=volume, type=INT64"`
+}
+
+// exportHandler streams a ZIP archive containing one CSV or Parquet file
+// per requested symbol, sourced from stock_historical_data. "symbols" is a
+// required comma-separated list; "format" is "csv" (default) or "parquet";
+// "from"/"to" follow parseHistoricalWindow's convention. The ZIP is written
+// straight to the response as each symbol's rows are read, rather than
+// buffering the whole archive in memory first.
+func exportHandler(w http.ResponseWriter, r *http.Request) {
+	symbolsParam := r.URL.Query().Get("symbols")
+	if symbolsParam == "" {
+		http.Error(w, "symbols is required", http.StatusBadRequest)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "parquet" {
+		http.Error(w, "format must be 'csv' or 'parquet'", http.StatusBadRequest)
+		return
+	}
+
+	endDate, startDate, err := parseHistoricalWindow(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="historical_data.zip"`)
+
+	zw := zip.NewWriter(w)
+	defer zw.Close()
+
+	for _, symbol := range strings.Split(symbolsParam, ",") {
+		symbol = strings.TrimSpace(symbol)
+		if symbol == "" {
+			continue
+		}
+
+		rows, err := BacktestDB.QueryContext(r.Context(), `
+			SELECT date, open, high, low, close, adj_close, volume
+			FROM stock_historical_data
+			WHERE symbol = ? AND date BETWEEN ? AND ?
+			ORDER BY date ASC
+		`, symbol, startDate.Unix(), endDate.Unix())
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to query %s: %v", symbol, err), http.StatusInternalServerError)
+			return
+		}
+
+		f, err := zw.Create(symbol + "." + format)
+		if err != nil {
+			rows.Close()
+			http.Error(w, fmt.Sprintf("failed to create archive entry for %s: %v", symbol, err), http.StatusInternalServerError)
+			return
+		}
+
+		if format == "csv" {
+			err = writeHistoricalCSV(f, rows)
+		} else {
+			err = writeHistoricalParquet(f, rows)
+		}
+		rows.Close()
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to export %s: %v", symbol, err), http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// historicalRows is the minimal interface writeHistoricalCSV/writeHistoricalParquet
+// need from *sql.Rows, so they can scan a stock_historical_data result set
+// without depending on the concrete driver type.
+type historicalRows interface {
+	Next() bool
+	Scan(dest ...interface{}) error
+	Err() error
+}
+
+// writeHistoricalCSV writes rows to w as CSV, using the standard
+// Date,Open,High,Low,Close,AdjClose,Volume header Yahoo's downloads use, so
+// the files round-trip with existing backtest tooling.
+func writeHistoricalCSV(w io.Writer, rows historicalRows) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"Date", "Open", "High", "Low", "Close", "AdjClose", "Volume"}); err != nil {
+		return err
+	}
+
+	for rows.Next() {
+		var timestamp, volume int64
+		var open, high, low, close, adjClose float64
+		if err := rows.Scan(&timestamp, &open, &high, &low, &close, &adjClose, &volume); err != nil {
+			return err
+		}
+
+		record := []string{
+			time.Unix(timestamp, 0).UTC().Format("2006-01-02"),
+			strconv.FormatFloat(open, 'f', -1, 64),
+			strconv.FormatFloat(high, 'f', -1, 64),
+			strconv.FormatFloat(low, 'f', -1, 64),
+			strconv.FormatFloat(close, 'f', -1, 64),
+			strconv.FormatFloat(adjClose, 'f', -1, 64),
+			strconv.FormatInt(volume, 10),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeHistoricalParquet writes rows to w as Parquet, flushing a new row
+// group every exportRowGroupSize rows.
+func writeHistoricalParquet(w io.Writer, rows historicalRows) error {
+	pw, err := writer.NewParquetWriterFromWriter(w, new(exportRow), 1)
+	if err != nil {
+		return fmt.Errorf("failed to create parquet writer: %w", err)
+	}
+
+	var n int
+	for rows.Next() {
+		var timestamp, volume int64
+		var open, high, low, close, adjClose float64
+		if err := rows.Scan(&timestamp, &open, &high, &low, &close, &adjClose, &volume); err != nil {
+			return err
+		}
+
+		if err := pw.Write(exportRow{
+			Date:     timestamp,
+			Open:     open,
+			High:     high,
+			Low:      low,
+			Close:    close,
+			AdjClose: adjClose,
+			Volume:   volume,
+		}); err != nil {
+			return fmt.Errorf("failed to write row: %w", err)
+		}
+
+		n++
+		if n%exportRowGroupSize == 0 {
+			if err := pw.Flush(true); err != nil {
+				return fmt.Errorf("failed to flush row group: %w", err)
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	return pw.WriteStop()
+}
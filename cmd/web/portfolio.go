@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/portfolio"
+)
+
+// positionProviderFromRequest resolves which portfolio.PositionProvider to
+// use from the "source" query param: "sqlite" (default, the positions
+// table in BacktestDB), "csv" (requires "file"), or "http" (requires
+// "url", for a broker/budgeting API adapter).
+func positionProviderFromRequest(r *http.Request) (portfolio.PositionProvider, error) {
+	switch source := r.URL.Query().Get("source"); source {
+	case "", "sqlite":
+		return portfolio.NewSQLitePositionProvider(BacktestDB)
+	case "csv":
+		file := r.URL.Query().Get("file")
+		if file == "" {
+			return nil, fmt.Errorf("source=csv requires a file param")
+		}
+		return portfolio.NewCSVPositionProvider(file), nil
+	case "http":
+		url := r.URL.Query().Get("url")
+		if url == "" {
+			return nil, fmt.Errorf("source=http requires a url param")
+		}
+		return portfolio.NewHTTPPositionProvider(url), nil
+	default:
+		return nil, fmt.Errorf("unknown source %q", source)
+	}
+}
+
+// valuatorFromRequest builds a portfolio.Valuator against BacktestDB, using
+// the "threshold" query param (a fraction, e.g. 0.1 for 10%) to override
+// C.TrailingStopPct when present.
+func valuatorFromRequest(r *http.Request) (*portfolio.Valuator, error) {
+	threshold := C.TrailingStopPct
+	if raw := r.URL.Query().Get("threshold"); raw != "" {
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid threshold %q: %w", raw, err)
+		}
+		threshold = parsed
+	}
+	return portfolio.NewValuator(BacktestDB, threshold), nil
+}
+
+// portfolioHandler returns every position's current valuation as JSON.
+func portfolioHandler(w http.ResponseWriter, r *http.Request) {
+	provider, err := positionProviderFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	positions, err := provider.Positions(r.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to load positions: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	valuator, err := valuatorFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	valued, err := valuator.Value(r.Context(), positions)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to value positions: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(valued)
+}
+
+// portfolioAlertsHandler returns only the positions whose trailing-stop
+// threshold has triggered.
+func portfolioAlertsHandler(w http.ResponseWriter, r *http.Request) {
+	provider, err := positionProviderFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	positions, err := provider.Positions(r.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to load positions: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	valuator, err := valuatorFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	alerts, err := valuator.Alerts(r.Context(), positions)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to compute alerts: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(alerts)
+}
@@ -1,23 +1,33 @@
 package main
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
-	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"database/sql"
 
+	"github.com/darianmavgo/backtest-sell-limit/pkg/backtest"
+	"github.com/darianmavgo/backtest-sell-limit/pkg/dbadmin"
+	"github.com/darianmavgo/backtest-sell-limit/pkg/fetcher"
+	"github.com/darianmavgo/backtest-sell-limit/pkg/marketdata"
+	"github.com/darianmavgo/backtest-sell-limit/pkg/providers"
+	"github.com/darianmavgo/backtest-sell-limit/pkg/ratelimit"
+	"github.com/darianmavgo/backtest-sell-limit/pkg/sp500"
+	"github.com/darianmavgo/backtest-sell-limit/pkg/store"
+	"github.com/darianmavgo/backtest-sell-limit/pkg/stream"
 	"github.com/darianmavgo/backtest-sell-limit/pkg/types"
+	"github.com/darianmavgo/backtest-sell-limit/pkg/types/metrics"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/gomarkdown/markdown"
-	nethtml "golang.org/x/net/html"
+	"github.com/gorilla/websocket"
 )
 
 // homeHandler renders the README.md file as HTML
@@ -45,8 +55,16 @@ func readmeHandler(w http.ResponseWriter, r *http.Request) {
 
 // portfolioBacktestHandler runs the portfolio backtest and streams the output
 func portfolioBacktestHandler(w http.ResponseWriter, r *http.Request) {
+	providerName := r.URL.Query().Get("provider")
+	interval := r.URL.Query().Get("interval")
+	endDate, startDate, err := parseHistoricalWindow(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Get list of active tickers
-	tickers, err := getActiveSP500Tickers(BacktestDB)
+	tickers, err := getActiveSP500Tickers(r.Context(), BacktestDB)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Failed to get tickers: %v", err), http.StatusInternalServerError)
 		return
@@ -55,15 +73,15 @@ func portfolioBacktestHandler(w http.ResponseWriter, r *http.Request) {
 	// Process each ticker
 	for _, symbol := range tickers {
 		// Get historical data
-		data, err := fetchHistoricalData(symbol)
+		data, err := fetchHistoricalData(r.Context(), symbol, providerName, startDate, endDate, interval)
 		if err != nil {
-			log.Printf("Failed to fetch historical data for %s: %v", symbol, err)
+			slog.Error("failed to fetch historical data", "request_id", middleware.GetReqID(r.Context()), "symbol", symbol, "error", err, "error_class", errClass(err))
 			continue
 		}
 
 		// Save to database
-		if err := saveHistoricalData(BacktestDB, symbol, data); err != nil {
-			log.Printf("Failed to save historical data for %s: %v", symbol, err)
+		if err := saveHistoricalData(r.Context(), BacktestDB, symbol, data); err != nil {
+			slog.Error("failed to save historical data", "request_id", middleware.GetReqID(r.Context()), "symbol", symbol, "error", err)
 			continue
 		}
 	}
@@ -74,6 +92,62 @@ func portfolioBacktestHandler(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// runBacktestHandler runs the named backtest.Strategy (see pkg/backtest;
+// "strategy" query param, DefaultStrategyName if omitted) against
+// symbol's stored stock_historical_data and returns the resulting
+// backtest.BacktestResult as JSON.
+func runBacktestHandler(w http.ResponseWriter, r *http.Request) {
+	symbol := chi.URLParam(r, "symbol")
+	if symbol == "" {
+		http.Error(w, "Symbol is required", http.StatusBadRequest)
+		return
+	}
+
+	strategy, err := backtest.Get(r.URL.Query().Get("strategy"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rows, err := BacktestDB.QueryContext(r.Context(), `
+		SELECT date, open, high, low, close, adj_close, volume
+		FROM stock_historical_data
+		WHERE symbol = ?
+		ORDER BY date ASC
+	`, symbol)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	var bars []marketdata.Bar
+	for rows.Next() {
+		var bar marketdata.Bar
+		var timestamp int64
+		if err := rows.Scan(&timestamp, &bar.Open, &bar.High, &bar.Low, &bar.Close, &bar.AdjClose, &bar.Volume); err != nil {
+			http.Error(w, fmt.Sprintf("Error scanning row: %v", err), http.StatusInternalServerError)
+			return
+		}
+		bar.Symbol = symbol
+		bar.Date = time.Unix(timestamp, 0)
+		bars = append(bars, bar)
+	}
+	if err := rows.Err(); err != nil {
+		http.Error(w, fmt.Sprintf("Row iteration error: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	result, err := backtest.Run(r.Context(), strategy, symbol, bars)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
 // sp500Handler handles S&P 500 data fetching and updating
 func sp500Handler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -85,7 +159,19 @@ func sp500Handler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	stocks, err := fetchSP500List()
+	providerName := r.URL.Query().Get("provider")
+	interval := r.URL.Query().Get("interval")
+	endDate, startDate, err := parseHistoricalWindow(r)
+	if err != nil {
+		sendJSONResponse(w, types.HandlerResponse{
+			Success: false,
+			Message: err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+
+	stocks, err := fetchSP500List(r.URL.Query().Get("force") == "true")
 	if err != nil {
 		sendJSONResponse(w, types.HandlerResponse{
 			Success: false,
@@ -97,14 +183,14 @@ func sp500Handler(w http.ResponseWriter, r *http.Request) {
 
 	// Update each stock's data
 	for _, stock := range stocks {
-		data, err := fetchHistoricalData(stock.Symbol)
+		data, err := fetchHistoricalData(r.Context(), stock.Symbol, providerName, startDate, endDate, interval)
 		if err != nil {
-			log.Printf("Failed to fetch historical data for %s: %v", stock.Symbol, err)
+			slog.Error("failed to fetch historical data", "request_id", middleware.GetReqID(r.Context()), "symbol", stock.Symbol, "error", err, "error_class", errClass(err))
 			continue
 		}
 
-		if err := saveHistoricalData(BacktestDB, stock.Symbol, data); err != nil {
-			log.Printf("Failed to save historical data for %s: %v", stock.Symbol, err)
+		if err := saveHistoricalData(r.Context(), BacktestDB, stock.Symbol, data); err != nil {
+			slog.Error("failed to save historical data", "request_id", middleware.GetReqID(r.Context()), "symbol", stock.Symbol, "error", err)
 			continue
 		}
 	}
@@ -116,7 +202,11 @@ func sp500Handler(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// historicalDataHandler handles requests for historical stock data
+// historicalDataHandler handles requests for historical stock data. By
+// default it serves whatever is already stored in stock_historical_data;
+// passing "provider" (and optionally "from"/"to"/"interval") instead fetches
+// live from that market data provider (see pkg/providers) without touching
+// the database.
 func historicalDataHandler(w http.ResponseWriter, r *http.Request) {
 	symbol := chi.URLParam(r, "symbol")
 	if symbol == "" {
@@ -124,10 +214,28 @@ func historicalDataHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if providerName := r.URL.Query().Get("provider"); providerName != "" {
+		endDate, startDate, err := parseHistoricalWindow(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		data, err := fetchHistoricalData(r.Context(), symbol, providerName, startDate, endDate, r.URL.Query().Get("interval"))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to fetch historical data: %v", err), http.StatusBadGateway)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(data)
+		return
+	}
+
 	// Query the database for historical data
-	rows, err := BacktestDB.Query(`
+	rows, err := BacktestDB.QueryContext(r.Context(), `
 		SELECT date, open, high, low, close, adj_close, volume
-		FROM stock_historical_data 
+		FROM stock_historical_data
 		WHERE symbol = ?
 		ORDER BY date DESC
 	`, symbol)
@@ -163,7 +271,44 @@ func historicalDataHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(data)
 }
 
-// updateSP500Handler fetches the current S&P 500 list and updates the database
+// ensureSP500Tables creates sp500_constituents (the current membership
+// list, keyed by ticker, stamped with the as_of date of its last refresh)
+// and sp500_changes (an append-only log of every addition/removal
+// updateSP500Handler has ever recorded) if they don't already exist.
+func ensureSP500Tables(db store.Store) error {
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS sp500_constituents (
+			ticker TEXT PRIMARY KEY,
+			security_name TEXT,
+			sector TEXT,
+			sub_industry TEXT,
+			date_added TEXT,
+			cik TEXT,
+			founded TEXT,
+			as_of TEXT
+		)
+	`); err != nil {
+		return fmt.Errorf("failed to create sp500_constituents: %w", err)
+	}
+
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS sp500_changes (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			ticker TEXT NOT NULL,
+			change_type TEXT NOT NULL,
+			as_of TEXT NOT NULL,
+			recorded_at INTEGER NOT NULL
+		)
+	`); err != nil {
+		return fmt.Errorf("failed to create sp500_changes: %w", err)
+	}
+
+	return nil
+}
+
+// updateSP500Handler fetches the current S&P 500 list and replaces
+// sp500_constituents with it, recording any tickers added or removed
+// since the last refresh into sp500_changes.
 func updateSP500Handler(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 	if r.Method != http.MethodGet {
 		sendJSONResponse(w, types.HandlerResponse{
@@ -173,23 +318,17 @@ func updateSP500Handler(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 		return
 	}
 
-	// Create the table if it doesn't exist
-	_, err := BacktestDB.Exec(`
-		CREATE TABLE IF NOT EXISTS sp500_list_2025_jun (
-			ticker TEXT PRIMARY KEY,
-			security_name TEXT
-		)
-	`)
-	if err != nil {
+	if err := ensureSP500Tables(BacktestDB); err != nil {
 		sendJSONResponse(w, types.HandlerResponse{
 			Success: false,
-			Message: fmt.Sprintf("Failed to create table: %v", err),
+			Message: err.Error(),
 		})
 		return
 	}
 
-	// Fetch S&P 500 constituents from local file
-	stocks, err := fetchSP500List()
+	// Fetch the current S&P 500 constituents (live Wikipedia, falling back
+	// to a local or bundled snapshot; see sp500Cache).
+	stocks, err := fetchSP500List(r.URL.Query().Get("force") == "true")
 	if err != nil {
 		sendJSONResponse(w, types.HandlerResponse{
 			Success: false,
@@ -198,7 +337,37 @@ func updateSP500Handler(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 		return
 	}
 
-	// Begin transaction
+	existing, err := BacktestDB.Query("SELECT ticker FROM sp500_constituents")
+	if err != nil {
+		sendJSONResponse(w, types.HandlerResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to query existing tickers: %v", err),
+		})
+		return
+	}
+	previousTickers := make(map[string]struct{})
+	for existing.Next() {
+		var ticker string
+		if err := existing.Scan(&ticker); err != nil {
+			existing.Close()
+			sendJSONResponse(w, types.HandlerResponse{
+				Success: false,
+				Message: fmt.Sprintf("Failed to scan existing ticker: %v", err),
+			})
+			return
+		}
+		previousTickers[ticker] = struct{}{}
+	}
+	existing.Close()
+
+	currentTickers := make(map[string]struct{}, len(stocks))
+	for _, stock := range stocks {
+		currentTickers[stock.Symbol] = struct{}{}
+	}
+
+	asOf := time.Now().Format("2006-01-02")
+	now := time.Now().Unix()
+
 	tx, err := BacktestDB.Begin()
 	if err != nil {
 		sendJSONResponse(w, types.HandlerResponse{
@@ -209,9 +378,7 @@ func updateSP500Handler(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 	}
 	defer tx.Rollback()
 
-	// Clear existing data
-	_, err = tx.Exec("DELETE FROM sp500_list_2025_jun")
-	if err != nil {
+	if _, err := tx.Exec("DELETE FROM sp500_constituents"); err != nil {
 		sendJSONResponse(w, types.HandlerResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to clear existing data: %v", err),
@@ -219,10 +386,9 @@ func updateSP500Handler(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 		return
 	}
 
-	// Insert new stocks
-	stmt, err := tx.Prepare(`
-		INSERT INTO sp500_list_2025_jun (ticker, security_name)
-		VALUES (?, ?)
+	insertStmt, err := tx.Prepare(`
+		INSERT INTO sp500_constituents (ticker, security_name, sector, sub_industry, date_added, cik, founded, as_of)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
 		sendJSONResponse(w, types.HandlerResponse{
@@ -231,11 +397,10 @@ func updateSP500Handler(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 		})
 		return
 	}
-	defer stmt.Close()
+	defer insertStmt.Close()
 
 	for _, stock := range stocks {
-		_, err = stmt.Exec(stock.Symbol, stock.SecurityName)
-		if err != nil {
+		if _, err := insertStmt.Exec(stock.Symbol, stock.SecurityName, stock.Sector, stock.SubIndustry, stock.DateAdded, stock.CIK, stock.Founded, asOf); err != nil {
 			sendJSONResponse(w, types.HandlerResponse{
 				Success: false,
 				Message: fmt.Sprintf("Failed to insert stock %s: %v", stock.Symbol, err),
@@ -244,6 +409,42 @@ func updateSP500Handler(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 		}
 	}
 
+	changeStmt, err := tx.Prepare(`
+		INSERT INTO sp500_changes (ticker, change_type, as_of, recorded_at)
+		VALUES (?, ?, ?, ?)
+	`)
+	if err != nil {
+		sendJSONResponse(w, types.HandlerResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to prepare change log statement: %v", err),
+		})
+		return
+	}
+	defer changeStmt.Close()
+
+	for ticker := range currentTickers {
+		if _, ok := previousTickers[ticker]; !ok {
+			if _, err := changeStmt.Exec(ticker, "added", asOf, now); err != nil {
+				sendJSONResponse(w, types.HandlerResponse{
+					Success: false,
+					Message: fmt.Sprintf("Failed to record addition of %s: %v", ticker, err),
+				})
+				return
+			}
+		}
+	}
+	for ticker := range previousTickers {
+		if _, ok := currentTickers[ticker]; !ok {
+			if _, err := changeStmt.Exec(ticker, "removed", asOf, now); err != nil {
+				sendJSONResponse(w, types.HandlerResponse{
+					Success: false,
+					Message: fmt.Sprintf("Failed to record removal of %s: %v", ticker, err),
+				})
+				return
+			}
+		}
+	}
+
 	// Commit transaction
 	if err = tx.Commit(); err != nil {
 		sendJSONResponse(w, types.HandlerResponse{
@@ -299,49 +500,478 @@ func stockHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(stock)
 }
 
-// fillHistoricalDataHandler fills historical data for all stocks
+// fillConcurrency, fillRPS, and fillBurst bound fillHistoricalDataHandler's
+// pkg/fetcher pool: at most fillConcurrency symbols in flight at once,
+// sharing a token bucket that allows fillRPS requests/sec with bursts up to
+// fillBurst. fillConcurrency defaults to fetcher.RecommendedWorkers
+// (derived from the process's open-file ceiling) rather than a fixed
+// number, so it never exhausts FDs alongside the DB pool and this server's
+// own connections. These mirror the root binary's fillHistoricalDataHandler
+// (see main.go), which fetches through the same pkg/fetcher pool.
+var fillConcurrency = fetcher.RecommendedWorkers()
+
+const (
+	fillRPS   = 4.0
+	fillBurst = 4
+)
+
+// fillSymbolTimeout bounds a single symbol's fetch+save within
+// fillHistoricalDataHandler, so one slow or hanging upstream call can't
+// occupy a worker for the rest of the run; only the remaining budget of
+// the overall request context still applies beyond that.
+const fillSymbolTimeout = 60 * time.Second
+
+// fillEvent is one line of fillHistoricalDataHandler's NDJSON response: a
+// per-symbol result as it completes, or (Symbol == "") the final summary.
+type fillEvent struct {
+	Symbol    string `json:"symbol,omitempty"`
+	Status    string `json:"status"`
+	Rows      int    `json:"rows,omitempty"`
+	ElapsedMs int64  `json:"elapsed_ms,omitempty"`
+	Error     string `json:"error,omitempty"`
+	Processed int    `json:"processed,omitempty"`
+	Failed    int    `json:"failed,omitempty"`
+	Total     int    `json:"total,omitempty"`
+}
+
+// fillHistoricalDataHandler fills historical data for all stocks, streaming
+// one application/x-ndjson line per symbol as it completes (and a final
+// summary line), instead of buffering the whole run and returning a single
+// JSON blob — a fill over ~500 tickers can take minutes, long enough to trip
+// a load balancer's idle timeout with no feedback in the meantime. The
+// source and window are controlled by the "provider" (see pkg/providers),
+// "from"/"to" (YYYY-MM-DD, default last 2 years), and "interval" (empty for
+// daily bars) query params. Fetches run through a pkg/fetcher.Fetcher (see
+// providerAdapter), which bounds concurrency to fillConcurrency, shares a
+// fillRPS/fillBurst token bucket across every symbol, and chunks the list
+// into fetcher.MaxSymbolsPerRequest-sized batches rather than fanning the
+// whole S&P 500 out against the upstream provider in one unbounded pool.
+// The whole fill stops as soon as the request is canceled (e.g. the client
+// disconnects) instead of running all remaining batches regardless.
 func fillHistoricalDataHandler(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	providerName := r.URL.Query().Get("provider")
+	interval := r.URL.Query().Get("interval")
+
+	endDate, startDate, err := parseHistoricalWindow(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Get list of S&P 500 stocks
-	stocks, err := fetchSP500List()
+	stocks, err := fetchSP500List(r.URL.Query().Get("force") == "true")
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Failed to get S&P 500 list: %v", err), http.StatusInternalServerError)
 		return
 	}
+	symbols := make([]string, len(stocks))
+	for i, stock := range stocks {
+		symbols[i] = stock.Symbol
+	}
 
-	log.Printf("Starting historical data download for %d S&P 500 stocks", len(stocks))
+	if err := fetcher.CheckSymbolLimit(symbols); err != nil {
+		slog.Info("fillHistoricalDataHandler: batching fill, symbol count exceeds per-request limit", "request_id", middleware.GetReqID(r.Context()), "error", err)
+	}
 
-	// Process each ticker
-	completed := 0
-	for _, stock := range stocks {
-		log.Printf("Processing %s (%d/%d)", stock.Symbol, completed+1, len(stocks))
-		
-		// Get historical data
-		data, err := fetchHistoricalData(stock.Symbol)
-		if err != nil {
-			log.Printf("Failed to fetch historical data for %s: %v", stock.Symbol, err)
-			continue
+	slog.Info("starting historical data download", "request_id", middleware.GetReqID(r.Context()), "stock_count", len(stocks))
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	f := fetcher.New(fillConcurrency, fillRPS, fillBurst)
+	f.JobTimeout = fillSymbolTimeout
+	adapter := providerAdapter{ctx: ctx, providerName: providerName, interval: interval}
+
+	events := make(chan fillEvent)
+	go func() {
+		defer close(events)
+
+		// Batched the same way fetcher.Fetcher.FetchAll chunks a symbol
+		// list, but streaming each batch's results out as they complete
+		// instead of buffering a whole batch into a slice, so callers still
+		// see progress on a run of hundreds of tickers.
+		for batchStart := 0; batchStart < len(symbols); batchStart += fetcher.MaxSymbolsPerRequest {
+			if ctx.Err() != nil {
+				return
+			}
+			batchEnd := batchStart + fetcher.MaxSymbolsPerRequest
+			if batchEnd > len(symbols) {
+				batchEnd = len(symbols)
+			}
+			batch := symbols[batchStart:batchEnd]
+
+			started := make(map[string]time.Time, len(batch))
+			now := time.Now()
+			for _, symbol := range batch {
+				started[symbol] = now
+			}
+
+			for result := range f.FillAll(ctx, adapter, batch, startDate, endDate) {
+				if result.Err != nil {
+					sendFillEvent(ctx, events, fillEvent{Symbol: result.Symbol, Status: "error", Error: result.Err.Error()})
+					continue
+				}
+
+				data := barsToStockData(result.Bars)
+				if err := saveHistoricalData(ctx, BacktestDB, result.Symbol, data); err != nil {
+					sendFillEvent(ctx, events, fillEvent{Symbol: result.Symbol, Status: "error", Error: err.Error()})
+					continue
+				}
+
+				sendFillEvent(ctx, events, fillEvent{
+					Symbol:    result.Symbol,
+					Status:    "ok",
+					Rows:      len(data),
+					ElapsedMs: time.Since(started[result.Symbol]).Milliseconds(),
+				})
+			}
 		}
+	}()
 
-		// Save to database
-		if err := saveHistoricalData(BacktestDB, stock.Symbol, data); err != nil {
-			log.Printf("Failed to save historical data for %s: %v", stock.Symbol, err)
-			continue
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	enc := json.NewEncoder(w)
+
+	var processed, failed int
+	for event := range events {
+		if event.Status == "ok" {
+			processed++
+		} else {
+			failed++
+		}
+		if err := enc.Encode(event); err != nil {
+			slog.Warn("fillHistoricalDataHandler: client disconnected mid-stream", "request_id", middleware.GetReqID(r.Context()), "error", err)
+			return
+		}
+		flusher.Flush()
+	}
+
+	slog.Info("historical data download completed", "request_id", middleware.GetReqID(r.Context()), "processed", processed, "total", len(stocks))
+
+	enc.Encode(fillEvent{Status: "completed", Processed: processed, Failed: failed, Total: len(stocks)})
+	flusher.Flush()
+}
+
+// providerAdapter adapts cmd/web's own fetchHistoricalData (and the
+// providers.MarketDataProvider chain it resolves by name) to
+// marketdata.Provider, the interface pkg/fetcher.Fetcher's worker pool is
+// built against. This lets fillHistoricalDataHandler run through Fetcher's
+// batching, bounded concurrency, and shared rate limiting instead of its
+// own ad-hoc fan-out, without cmd/web maintaining a second provider chain
+// of its own (see pkg/marketdata for the one the root binary uses).
+type providerAdapter struct {
+	ctx          context.Context
+	providerName string
+	interval     string
+}
+
+func (a providerAdapter) Name() string { return a.providerName }
+
+// FetchOHLCV ignores its interval argument in favor of a.interval: every
+// marketdata.Provider call made through Fetcher.FillAll/FillRanges passes
+// "", so the interval fillHistoricalDataHandler was asked for is fixed on
+// the adapter instead.
+//
+// It fetches through a.ctx (the handler's request context), not
+// context.Background(), so a client disconnect or the handler's own
+// fillSymbolTimeout actually aborts the underlying HTTP request via
+// fetchHistoricalData instead of leaving it to run to completion in the
+// background regardless of what Fetcher itself does with its own timeout.
+func (a providerAdapter) FetchOHLCV(symbol string, start, end time.Time, _ string) ([]marketdata.Bar, error) {
+	jobCtx, cancel := context.WithTimeout(a.ctx, fillSymbolTimeout)
+	defer cancel()
+	data, err := fetchHistoricalData(jobCtx, symbol, a.providerName, start, end, a.interval)
+	if err != nil {
+		return nil, err
+	}
+	return stockDataToBars(symbol, data), nil
+}
+
+// stockDataToBars converts fetchHistoricalData's []types.StockData into the
+// []marketdata.Bar shape pkg/fetcher.Result carries, for providerAdapter.
+func stockDataToBars(symbol string, data []types.StockData) []marketdata.Bar {
+	bars := make([]marketdata.Bar, len(data))
+	for i, d := range data {
+		bars[i] = marketdata.Bar{
+			Symbol:   symbol,
+			Date:     d.Date,
+			Open:     d.Open,
+			High:     d.High,
+			Low:      d.Low,
+			Close:    d.Close,
+			AdjClose: d.AdjClose,
+			Volume:   d.Volume,
+			Source:   d.Source,
+		}
+	}
+	return bars
+}
+
+// barsToStockData converts a fetcher.Result's []marketdata.Bar back into
+// []types.StockData for saveHistoricalData, the inverse of
+// stockDataToBars.
+func barsToStockData(bars []marketdata.Bar) []types.StockData {
+	data := make([]types.StockData, len(bars))
+	for i, b := range bars {
+		data[i] = types.StockData{
+			Date:     b.Date,
+			Open:     b.Open,
+			High:     b.High,
+			Low:      b.Low,
+			Close:    b.Close,
+			AdjClose: b.AdjClose,
+			Volume:   b.Volume,
+			Source:   b.Source,
+		}
+	}
+	return data
+}
+
+// sendFillEvent delivers event on events, but gives up as soon as ctx is
+// done so a canceled request (client disconnect) can't block a worker
+// forever on a channel nobody is draining anymore.
+func sendFillEvent(ctx context.Context, events chan<- fillEvent, event fillEvent) {
+	select {
+	case events <- event:
+	case <-ctx.Done():
+	}
+}
+
+// liveStream holds the one running stream.Client, if any. The app only
+// ever runs a single live backfill at a time, so a package-level singleton
+// (guarded by liveStreamMu) is simpler than threading a handle through the
+// router.
+var (
+	liveStreamMu     sync.Mutex
+	liveStream       *stream.Client
+	liveStreamCancel context.CancelFunc
+
+	// liveHub fans the running liveStream's trades out to /ws/quotes
+	// subscribers, deduping their symbol subscriptions against the one
+	// upstream connection. It's non-nil exactly when liveStream is.
+	liveHub *stream.Hub
+)
+
+// streamStartHandler starts a live WebSocket backfill alongside the
+// existing historical fill. "source" (alpaca|polygon|finnhub, default
+// alpaca) selects the upstream; its API key/secret come from the
+// "<SOURCE>_API_KEY"/"<SOURCE>_API_SECRET" env vars. "table" names the
+// symbols table to subscribe (default: the active S&P 500 tickers).
+// Incoming trades are aggregated into 1-minute bars and upserted the same
+// way a historical fill saves data.
+func streamStartHandler(w http.ResponseWriter, r *http.Request) {
+	liveStreamMu.Lock()
+	defer liveStreamMu.Unlock()
+
+	if liveStream != nil {
+		http.Error(w, "a stream is already running; call /stream/stop first", http.StatusConflict)
+		return
+	}
+
+	source := r.URL.Query().Get("source")
+	if source == "" {
+		source = "alpaca"
+	}
+	envPrefix := strings.ToUpper(source)
+	key := os.Getenv(envPrefix + "_API_KEY")
+	secret := os.Getenv(envPrefix + "_API_SECRET")
+
+	client, err := stream.NewClient(source, key, secret)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var symbols []string
+	if table := r.URL.Query().Get("table"); table != "" {
+		symbols, err = getSymbolsFromTable(r.Context(), BacktestDB, table)
+	} else {
+		symbols, err = getActiveSP500Tickers(r.Context(), BacktestDB)
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to load symbols: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	client.OnBar(func(symbol string, bar types.HistoricalData) {
+		if err := saveHistoricalData(context.Background(), BacktestDB, symbol, []types.StockData{historicalBarToStockData(bar)}); err != nil {
+			slog.Error("stream: failed to save bar", "symbol", symbol, "error", err)
 		}
-		
-		completed++
-		log.Printf("Completed %s (%d/%d)", stock.Symbol, completed, len(stocks))
+	})
+	hub := stream.NewHub(client)
+
+	if err := client.Subscribe(symbols); err != nil {
+		http.Error(w, fmt.Sprintf("failed to subscribe: %v", err), http.StatusInternalServerError)
+		return
 	}
 
-	log.Printf("Historical data download completed. Processed %d out of %d stocks", completed, len(stocks))
+	ctx, cancel := context.WithCancel(context.Background())
+	liveStream = client
+	liveStreamCancel = cancel
+	liveHub = hub
+	go client.Run(ctx)
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"status": "completed",
-		"processed": completed,
-		"total": len(stocks),
+	json.NewEncoder(w).Encode(types.HandlerResponse{
+		Success: true,
+		Message: "stream started",
+		Data:    map[string]interface{}{"source": source, "symbols": len(symbols)},
 	})
 }
 
+// streamStopHandler stops the running stream started by streamStartHandler,
+// flushing each symbol's in-progress minute bar so it isn't lost.
+func streamStopHandler(w http.ResponseWriter, r *http.Request) {
+	liveStreamMu.Lock()
+	defer liveStreamMu.Unlock()
+
+	if liveStream == nil {
+		http.Error(w, "no stream is running", http.StatusNotFound)
+		return
+	}
+
+	liveStreamCancel()
+	for symbol, bar := range liveStream.Flush() {
+		if err := saveHistoricalData(context.Background(), BacktestDB, symbol, []types.StockData{historicalBarToStockData(bar)}); err != nil {
+			slog.Error("stream: failed to save final bar", "symbol", symbol, "error", err)
+		}
+	}
+	liveStream = nil
+	liveStreamCancel = nil
+	liveHub = nil
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(types.HandlerResponse{Success: true, Message: "stream stopped"})
+}
+
+// wsUpgrader upgrades /ws/quotes requests. Origin checking is left to
+// whatever reverse proxy fronts this app, matching the rest of the API's
+// lack of CORS/CSRF handling.
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin:     func(r *http.Request) bool { return true },
+}
+
+// wsQuotesTickBuffer bounds how many trades wsQuotesHandler will queue for
+// a single connection across all of its subscribed symbols before it
+// starts dropping the oldest, so a slow WebSocket write can't back up the
+// shared stream.Hub subscriptions feeding it.
+const wsQuotesTickBuffer = 64
+
+// wsQuotesHandler upgrades the request to a WebSocket and streams live
+// ticks for one or more symbols (comma-separated "symbols" query param)
+// from the currently running stream (see streamStartHandler), one
+// JSON-encoded stream.Trade per message. Each symbol's buffered latest
+// tick (see stream.Hub.Latest) is delivered immediately on connect so a
+// newly-opened dashboard tab doesn't have to wait for the next trade.
+// Multiple tabs or handlers subscribing to the same symbol share the one
+// upstream subscription via liveHub instead of each opening their own.
+func wsQuotesHandler(w http.ResponseWriter, r *http.Request) {
+	liveStreamMu.Lock()
+	hub := liveHub
+	liveStreamMu.Unlock()
+
+	if hub == nil {
+		http.Error(w, "no stream is running; call /stream/start first", http.StatusConflict)
+		return
+	}
+
+	var symbols []string
+	for _, s := range strings.Split(r.URL.Query().Get("symbols"), ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			symbols = append(symbols, s)
+		}
+	}
+	if len(symbols) == 0 {
+		http.Error(w, "symbols query param is required", http.StatusBadRequest)
+		return
+	}
+
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		slog.Error("wsQuotesHandler: upgrade failed", "request_id", middleware.GetReqID(r.Context()), "error", err)
+		return
+	}
+	defer conn.Close()
+
+	ticks := make(chan stream.Trade, wsQuotesTickBuffer)
+	ctx := r.Context()
+	for _, symbol := range symbols {
+		ch, cancel := hub.Subscribe(symbol)
+		defer cancel()
+		go relayTicks(ctx, ch, ticks)
+	}
+
+	// The browser side never sends anything meaningful, but reading keeps
+	// gorilla/websocket's ping/pong and close-frame handling running so
+	// this handler notices a client disconnect.
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case t, ok := <-ticks:
+			if !ok {
+				return
+			}
+			if err := conn.WriteJSON(t); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// relayTicks copies ch onto out until ctx is done or ch closes (closing
+// shouldn't happen — a stream.Hub subscription's channel lives as long as
+// the subscription does — but the select keeps this goroutine from
+// leaking if it ever does).
+func relayTicks(ctx context.Context, ch <-chan stream.Trade, out chan<- stream.Trade) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case t, ok := <-ch:
+			if !ok {
+				return
+			}
+			select {
+			case out <- t:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// historicalBarToStockData adapts a stream-aggregated bar to the shape
+// saveHistoricalData expects; StockData's extra fields (company name,
+// market cap, ...) are left zero since a trade stream doesn't carry them.
+func historicalBarToStockData(bar types.HistoricalData) types.StockData {
+	return types.StockData{
+		Symbol:   bar.Symbol,
+		Date:     bar.Date,
+		Open:     bar.Open,
+		High:     bar.High,
+		Low:      bar.Low,
+		Close:    bar.Close,
+		AdjClose: bar.AdjClose,
+		Volume:   bar.Volume,
+	}
+}
+
 // listSP500Handler returns the current list of S&P 500 stocks
 func listSP500Handler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -353,7 +983,7 @@ func listSP500Handler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	stocks, err := fetchSP500List()
+	stocks, err := fetchSP500List(r.URL.Query().Get("force") == "true")
 	if err != nil {
 		sendJSONResponse(w, types.HandlerResponse{
 			Success: false,
@@ -370,111 +1000,174 @@ func listSP500Handler(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// tablesHandler returns list of all database tables
-func tablesHandler(w http.ResponseWriter, r *http.Request) {
-	// Query to get all table names
-	rows, err := BacktestDB.Query(`
-		SELECT name FROM sqlite_master 
-		WHERE type='table' AND name NOT LIKE 'sqlite_%'
-		ORDER BY name
-	`)
+// indexRegistry dispatches indexesHandler's {name} path param to the
+// right index provider. "sp500" wraps the same sp500Cache fetchSP500List
+// uses, so /indexes/sp500 and the back-compat /sp500 never disagree.
+var indexRegistry = newIndexRegistry()
+
+func newIndexRegistry() *sp500.Registry {
+	reg := sp500.NewRegistry()
+	reg.Register(sp500.NewCachedIndex("sp500", sp500Cache))
+	reg.Register(sp500.NewCachedIndex("nasdaq100", sp500.NewCache(sp500.NewNasdaq100Source(""), sp500.DefaultCacheTTL)))
+	reg.Register(sp500.NewCachedIndex("dowjones", sp500.NewCache(sp500.NewDowJonesSource(""), sp500.DefaultCacheTTL)))
+	reg.Register(sp500.NewCachedIndex("russell1000", sp500.NewCache(sp500.NewRussell1000Source(""), sp500.DefaultCacheTTL)))
+	return reg
+}
+
+// indexesHandler returns the current constituents of the index named by
+// the "name" path param (see indexRegistry), generalizing listSP500Handler
+// to every registered index instead of one hardcoded handler per index.
+func indexesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		sendJSONResponse(w, types.HandlerResponse{
+			Success: false,
+			Message: "Method not allowed",
+		})
+		return
+	}
+
+	name := chi.URLParam(r, "name")
+	provider, ok := indexRegistry.Get(name)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		sendJSONResponse(w, types.HandlerResponse{
+			Success: false,
+			Message: fmt.Sprintf("unknown index %q (want one of %v)", name, indexRegistry.Names()),
+		})
+		return
+	}
+
+	fetch := provider.Symbols
+	if r.URL.Query().Get("force") == "true" {
+		fetch = provider.Refresh
+	}
+
+	stocks, err := fetch(r.Context())
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to get tables: %v", err), http.StatusInternalServerError)
+		sendJSONResponse(w, types.HandlerResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to fetch %s constituents: %v", name, err),
+			Data:    nil,
+		})
 		return
 	}
-	defer rows.Close()
 
-	var tables []string
-	for rows.Next() {
-		var tableName string
-		if err := rows.Scan(&tableName); err != nil {
-			http.Error(w, fmt.Sprintf("Failed to scan table name: %v", err), http.StatusInternalServerError)
-			return
-		}
-		tables = append(tables, tableName)
+	sendJSONResponse(w, types.HandlerResponse{
+		Success: true,
+		Message: fmt.Sprintf("Successfully retrieved %d %s constituents", len(stocks), name),
+		Data:    stocks,
+	})
+}
+
+// tablesHandler returns the list of browsable database tables.
+func tablesHandler(w http.ResponseWriter, r *http.Request) {
+	tables, err := dbadmin.Tables(r.Context(), BacktestDB.DB(), C.StorageDriver)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get tables: %v", err), http.StatusInternalServerError)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(tables)
 }
 
-// tableDataHandler returns paginated data from a specific table
-func tableDataHandler(w http.ResponseWriter, r *http.Request) {
+// tableSchemaHandler returns column definitions for a table, pulled from
+// PRAGMA table_info via pkg/dbadmin.
+func tableSchemaHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
 	tableName := chi.URLParam(r, "table")
-	page := r.URL.Query().Get("page")
-	pageSize := r.URL.Query().Get("pageSize")
 
-	if page == "" {
-		page = "1"
+	allowed, err := dbadmin.IsAllowed(ctx, BacktestDB.DB(), C.StorageDriver, tableName)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to validate table: %v", err), http.StatusInternalServerError)
+		return
 	}
-	if pageSize == "" {
-		pageSize = "100"
+	if !allowed {
+		http.Error(w, "Table not found", http.StatusNotFound)
+		return
 	}
 
-	pageNum, _ := strconv.Atoi(page)
-	pageSizeNum, _ := strconv.Atoi(pageSize)
-	offset := (pageNum - 1) * pageSizeNum
+	columns, err := dbadmin.Schema(ctx, BacktestDB.DB(), C.StorageDriver, tableName)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get schema: %v", err), http.StatusInternalServerError)
+		return
+	}
 
-	// Validate table name exists
-	var exists bool
-	err := BacktestDB.QueryRow(`
-		SELECT 1 FROM sqlite_master 
-		WHERE type='table' AND name=? AND name NOT LIKE 'sqlite_%'
-	`, tableName).Scan(&exists)
-	if err != nil || !exists {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(columns)
+}
+
+// tableDataHandler returns a keyset-paginated page of rows from a table.
+// Pagination is controlled by "pageSize" (clamped to dbadmin.MaxPageSize)
+// and an "after"/"before" rowid cursor (see dbadmin.Page) rather than an
+// unbounded OFFSET.
+func tableDataHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tableName := chi.URLParam(r, "table")
+
+	allowed, err := dbadmin.IsAllowed(ctx, BacktestDB.DB(), C.StorageDriver, tableName)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to validate table: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if !allowed {
 		http.Error(w, "Table not found", http.StatusNotFound)
 		return
 	}
 
-	// Execute the query
-	rows, err := BacktestDB.Query(fmt.Sprintf("SELECT * FROM %s LIMIT %d OFFSET %d", tableName, pageSizeNum, offset))
+	params, err := dbadmin.ParsePageParams(
+		r.URL.Query().Get("pageSize"),
+		r.URL.Query().Get("after"),
+		r.URL.Query().Get("before"),
+	)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to query table: %v", err), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	defer rows.Close()
 
-	// Get column names
-	columns, err := rows.Columns()
+	page, err := dbadmin.FetchPage(ctx, BacktestDB.DB(), C.StorageDriver, tableName, params)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to get columns: %v", err), http.StatusInternalServerError)
+		http.Error(w, fmt.Sprintf("Failed to query table: %v", err), http.StatusInternalServerError)
 		return
 	}
 
-	// Prepare result
-	var result []map[string]interface{}
-	for rows.Next() {
-		// Create a slice of interface{} to hold the values
-		values := make([]interface{}, len(columns))
-		valuePtrs := make([]interface{}, len(columns))
-		for i := range columns {
-			valuePtrs[i] = &values[i]
-		}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
 
-		// Scan the result into the values
-		if err := rows.Scan(valuePtrs...); err != nil {
-			http.Error(w, fmt.Sprintf("Failed to scan row: %v", err), http.StatusInternalServerError)
-			return
-		}
+// defaultRequestTimeout is used when C.RequestTimeout isn't set in config.json.
+const defaultRequestTimeout = 5 * time.Minute
+
+// newAPIRateLimiter builds the Limiter guarding the fetch/download,
+// backtest, and S&P 500 routes below, capping each client to
+// C.RateLimitRPM requests per minute (these are the endpoints expensive
+// enough, or public enough, that one caller looping on them could starve
+// everyone else). It's built fresh by setupRoutes, called after
+// InitConfig, rather than as a package var, so it picks up C.RateLimitRPM
+// from config.json instead of always seeing the zero value.
+//
+// C.TrustedProxyCIDRs, if set, lets the limiter key requests by
+// X-Forwarded-For when (and only when) they arrive via one of those
+// proxies; left empty, every client is keyed by RemoteAddr, since
+// honoring XFF from an untrusted direct caller lets them dodge their own
+// limit by setting the header to a fresh value on every request.
+func newAPIRateLimiter() *ratelimit.Limiter {
+	rpm := C.RateLimitRPM
+	if rpm <= 0 {
+		rpm = ratelimit.DefaultRPM
+	}
+	l := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), rpm, time.Minute)
 
-		// Create a map for this row
-		row := make(map[string]interface{})
-		for i, col := range columns {
-			var v interface{}
-			val := values[i]
-			b, ok := val.([]byte)
-			if ok {
-				v = string(b)
-			} else {
-				v = val
-			}
-			row[col] = v
+	if len(C.TrustedProxyCIDRs) > 0 {
+		proxies, err := ratelimit.ParseTrustedProxies(C.TrustedProxyCIDRs)
+		if err != nil {
+			slog.Error("newAPIRateLimiter: ignoring invalid TrustedProxyCIDRs", "error", err)
+		} else {
+			l.TrustedProxies = proxies
 		}
-		result = append(result, row)
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(result)
+	return l
 }
 
 // setupRoutes configures all the application routes
@@ -482,34 +1175,71 @@ func setupRoutes() *chi.Mux {
 	// Create router
 	r := chi.NewRouter()
 
+	requestTimeout := C.RequestTimeout
+	if requestTimeout <= 0 {
+		requestTimeout = defaultRequestTimeout
+	}
+
+	apiLimiter := newAPIRateLimiter()
+
 	// Middleware
+	r.Use(middleware.RequestID)
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
+	r.Use(middleware.Timeout(requestTimeout))
 
 	// Home route - render README.md
 	r.Get("/", homeHandler)
 
+	// Browser-facing live quote stream (see streamStartHandler/stream.Hub).
+	r.Get("/ws/quotes", wsQuotesHandler)
+
 	// API routes
 	r.Route("/api", func(r chi.Router) {
 		// Stock data routes
 		r.Get("/stock/{symbol}", stockHandler)
 		r.Get("/stock/historical/{symbol}", historicalDataHandler)
-		r.Get("/stock/historical/fill", fillHistoricalDataHandler)
+		r.With(apiLimiter.Middleware).Get("/stock/historical/fill", fillHistoricalDataHandler)
+
+		// Live streaming routes
+		r.Post("/stream/start", streamStartHandler)
+		r.Post("/stream/stop", streamStopHandler)
 
 		// Portfolio routes
-		r.Get("/portfolio/backtest", portfolioBacktestHandler)
+		r.With(apiLimiter.Middleware).Get("/portfolio/backtest", portfolioBacktestHandler)
+
+		// Strategy backtest routes (see pkg/backtest)
+		r.With(apiLimiter.Middleware).Get("/backtest/{symbol}", runBacktestHandler)
+		r.Get("/portfolio/positions", portfolioHandler)
+		r.Get("/portfolio/alerts", portfolioAlertsHandler)
 
 		// S&P 500 routes
-		r.Get("/sp500", listSP500Handler)
+		r.With(apiLimiter.Middleware).Get("/sp500", listSP500Handler)
+
+		// Index routes: /sp500 above is a back-compat alias for
+		// /indexes/sp500, kept because existing callers depend on it.
+		r.With(apiLimiter.Middleware).Get("/indexes/{name}", indexesHandler)
 
 		// Database browsing routes
 		r.Get("/tables", tablesHandler)
 		r.Get("/tables/{table}", tableDataHandler)
+		r.Get("/tables/{table}/schema", tableSchemaHandler)
+
+		// Bulk export routes
+		r.Get("/export", exportHandler)
+
+		// Batch historical backfill jobs (see cmd/web/backfill.go)
+		r.With(apiLimiter.Middleware).Post("/backfill", backfillStartHandler)
+		r.Get("/backfill/{id}", backfillStatusHandler)
+		r.Delete("/backfill/{id}", backfillCancelHandler)
 	})
 
 	// Documentation routes
 	r.Get("/readme", readmeHandler)
 
+	// Prometheus metrics for fetch error rates and DB latency.
+	r.Get("/metrics", metrics.Handler().ServeHTTP)
+
 	return r
 }
 
@@ -519,187 +1249,96 @@ func sendJSONResponse(w http.ResponseWriter, response types.HandlerResponse) {
 	json.NewEncoder(w).Encode(response)
 }
 
-// fetchSP500List fetches the current S&P 500 constituents from local HTML file
-func fetchSP500List() ([]types.SP500Stock, error) {
-	// Read the local HTML file
-	content, err := os.ReadFile("sp500.html")
-	if err != nil {
-		return nil, fmt.Errorf("failed to read sp500.html: %v", err)
+// sp500Cache backs fetchSP500List. It tries a live Wikipedia fetch first,
+// falls back to the local sp500.html snapshot if that fails, and finally
+// to the CSV snapshot bundled into the binary so fetchSP500List still
+// returns something on a fresh checkout with neither network access nor a
+// cached sp500.html.
+var sp500Cache = sp500.NewCache(sp500.NewChainSource(
+	sp500.NewHTTPSource(""),
+	sp500.NewFileSource("sp500.html"),
+	sp500.NewCSVSource(),
+), sp500.DefaultCacheTTL)
+
+// fetchSP500List returns the current S&P 500 constituents, memoized for
+// sp500Cache's TTL so repeated hits to e.g. /api/sp500 don't re-parse or
+// re-fetch on every request. The underlying source is swappable (see
+// sp500.Source) between a local HTML snapshot, a live Wikipedia fetch, or
+// a database-backed list. force bypasses that memoization and re-fetches
+// (see listSP500Handler's force=true query param).
+func fetchSP500List(force bool) ([]types.SP500Stock, error) {
+	if force {
+		return sp500Cache.Force(context.Background())
 	}
+	return sp500Cache.Get(context.Background())
+}
 
-	// Parse the HTML document
-	doc, err := nethtml.Parse(bytes.NewReader(content))
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse HTML: %v", err)
-	}
-
-	var stocks []types.SP500Stock
-	var f func(*nethtml.Node)
-	f = func(n *nethtml.Node) {
-		if n.Type == nethtml.ElementNode && n.Data == "table" {
-			// Check if this is the S&P 500 table
-			for _, a := range n.Attr {
-				if a.Key == "id" && a.Val == "constituents" {
-					// Found the right table, now parse rows
-					var currentStock types.SP500Stock
-					var inRow bool
-					var colIndex int
-
-					var parseRow func(*nethtml.Node)
-					parseRow = func(n *nethtml.Node) {
-						if n.Type == nethtml.ElementNode {
-							switch n.Data {
-							case "tr":
-								if n.Parent != nil && n.Parent.Data == "tbody" {
-									inRow = true
-									colIndex = 0
-									currentStock = types.SP500Stock{}
-								}
-							case "td":
-								if !inRow {
-									return
-								}
-								switch colIndex {
-								case 0: // Symbol column
-									// Find the first anchor tag
-									for c := n.FirstChild; c != nil; c = c.NextSibling {
-										if c.Type == nethtml.ElementNode && c.Data == "a" {
-											if c.FirstChild != nil {
-												currentStock.Symbol = strings.TrimSpace(c.FirstChild.Data)
-											}
-											break
-										}
-									}
-								case 1: // Security Name column
-									// Find the first anchor tag
-									for c := n.FirstChild; c != nil; c = c.NextSibling {
-										if c.Type == nethtml.ElementNode && c.Data == "a" {
-											if c.FirstChild != nil {
-												currentStock.SecurityName = strings.TrimSpace(c.FirstChild.Data)
-											}
-											break
-										}
-									}
-									// After getting both columns, add to stocks if valid
-									if currentStock.Symbol != "" && currentStock.SecurityName != "" {
-										stocks = append(stocks, currentStock)
-									}
-								}
-								colIndex++
-							}
-						}
-						for c := n.FirstChild; c != nil; c = c.NextSibling {
-							parseRow(c)
-						}
-					}
-
-					// Parse all rows in the table
-					for c := n.FirstChild; c != nil; c = c.NextSibling {
-						parseRow(c)
-					}
-					return
-				}
-			}
-		}
-		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			f(c)
+// parseHistoricalWindow reads the "from"/"to" query params (YYYY-MM-DD) off
+// r, defaulting to the last 2 years ending now when either is absent.
+func parseHistoricalWindow(r *http.Request) (end, start time.Time, err error) {
+	end = time.Now()
+	if to := r.URL.Query().Get("to"); to != "" {
+		end, err = time.Parse("2006-01-02", to)
+		if err != nil {
+			return end, start, fmt.Errorf("invalid 'to' format. Use YYYY-MM-DD")
 		}
 	}
-	f(doc)
 
-	if len(stocks) == 0 {
-		return nil, fmt.Errorf("no stocks found in HTML file")
+	start = end.AddDate(-2, 0, 0)
+	if from := r.URL.Query().Get("from"); from != "" {
+		start, err = time.Parse("2006-01-02", from)
+		if err != nil {
+			return end, start, fmt.Errorf("invalid 'from' format. Use YYYY-MM-DD")
+		}
 	}
 
-	return stocks, nil
+	return end, start, nil
 }
 
-// fetchHistoricalData fetches historical data for a given symbol
-func fetchHistoricalData(symbol string) ([]types.StockData, error) {
-	// Set date range (last 2 years)
-	endDate := time.Now()
-	startDate := endDate.AddDate(-2, 0, 0)
-	
-	// Fetch data using the existing function
-	data, err := fetchHistoricalTickerData(symbol, startDate, endDate)
+// fetchHistoricalData fetches historical data for a given symbol from the
+// named market data provider (empty defaults to providers.DefaultProviderName).
+// An empty interval fetches daily bars; anything else is passed through to
+// the provider's FetchIntraday.
+func fetchHistoricalData(ctx context.Context, symbol, providerName string, start, end time.Time, interval string) ([]types.StockData, error) {
+	provider, err := providers.Get(providerName)
 	if err != nil {
 		return nil, err
 	}
-	
-	// Convert to StockData format
-	var stockData []types.StockData
-	for _, d := range data {
-		stockData = append(stockData, types.StockData{
-			Symbol:   d.Symbol,
-			Date:     d.Date,
-			Open:     d.Open,
-			High:     d.High,
-			Low:      d.Low,
-			Close:    d.Close,
-			AdjClose: d.AdjClose,
-			Volume:   d.Volume,
-		})
-	}
-	
-	return stockData, nil
-}
+	source := provider.Name()
 
-// saveHistoricalData saves historical stock data to the database
-func saveHistoricalData(db *sql.DB, symbol string, data []types.StockData) error {
-	// Begin transaction
-	tx, err := BacktestDB.Begin()
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %v", err)
+	started := time.Now()
+	var data []types.StockData
+	if interval == "" || interval == "1d" {
+		data, err = provider.FetchDaily(ctx, symbol, start, end)
+	} else {
+		data, err = provider.FetchIntraday(ctx, symbol, start, end, interval)
 	}
-	defer tx.Rollback()
 
-	// Prepare statement
-	stmt, err := tx.Prepare(`
-		INSERT INTO stock_historical_data (
-			symbol, date, open, high, low, close, adj_close, volume
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
-		ON CONFLICT(symbol, date) DO UPDATE SET
-			open = excluded.open,
-			high = excluded.high,
-			low = excluded.low,
-			close = excluded.close,
-			adj_close = excluded.adj_close,
-			volume = excluded.volume
-	`)
+	metrics.StockFetchDuration.Observe(time.Since(started).Seconds(), source)
+	result := "ok"
 	if err != nil {
-		return fmt.Errorf("failed to prepare statement: %v", err)
-	}
-	defer stmt.Close()
-
-	// Insert data
-	for _, d := range data {
-		_, err = stmt.Exec(
-			symbol,
-			d.Date.Unix(),
-			d.Open,
-			d.High,
-			d.Low,
-			d.Close,
-			d.AdjClose,
-			d.Volume,
-		)
-		if err != nil {
-			return fmt.Errorf("failed to insert historical data: %v", err)
-		}
+		result = "error"
 	}
+	metrics.StockFetchTotal.Inc(source, symbol, result)
 
-	// Commit transaction
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %v", err)
-	}
+	return data, err
+}
 
+// saveHistoricalData upserts historical stock data into db via its batched
+// BulkInsertHistorical path, instead of inserting one row at a time. It
+// aborts as soon as ctx is done.
+func saveHistoricalData(ctx context.Context, db store.Store, symbol string, data []types.StockData) error {
+	if err := db.BulkInsertHistorical(ctx, symbol, data); err != nil {
+		return err
+	}
+	metrics.HistoricalRowsIngestedTotal.Add(float64(len(data)))
 	return nil
 }
 
-// getActiveSP500Tickers returns a list of active S&P 500 tickers from the database
-func getActiveSP500Tickers(db *sql.DB) ([]string, error) {
-	rows, err := BacktestDB.Query(`
-		SELECT DISTINCT symbol 
+// getActiveSP500Tickers returns a list of active S&P 500 tickers from db.
+func getActiveSP500Tickers(ctx context.Context, db store.Store) ([]string, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT DISTINCT symbol
 		FROM stock_historical_data
 		ORDER BY symbol
 	`)
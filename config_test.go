@@ -0,0 +1,223 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/credential"
+)
+
+func TestLoadConfigFormats(t *testing.T) {
+	want := Config{
+		ENV:       "DEV",
+		Port:      "8080",
+		ProjectID: "flight-test",
+	}
+
+	cases := []struct {
+		name    string
+		ext     string
+		content string
+	}{
+		{
+			name:    "json",
+			ext:     ".json",
+			content: `{"ENV":"DEV","Port":"8080","ProjectID":"flight-test"}`,
+		},
+		{
+			name:    "yaml",
+			ext:     ".yaml",
+			content: "env: DEV\nport: \"8080\"\nprojectid: flight-test\n",
+		},
+		{
+			name:    "toml",
+			ext:     ".toml",
+			content: "ENV = \"DEV\"\nPort = \"8080\"\nProjectID = \"flight-test\"\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config"+tc.ext)
+			if err := os.WriteFile(path, []byte(tc.content), 0o644); err != nil {
+				t.Fatalf("failed to write fixture: %v", err)
+			}
+
+			got, err := LoadConfig(path)
+			if err != nil {
+				t.Fatalf("LoadConfig(%s) returned error: %v", path, err)
+			}
+
+			if !reflect.DeepEqual(*got, want) {
+				t.Errorf("LoadConfig(%s) = %+v, want %+v", path, *got, want)
+			}
+		})
+	}
+}
+
+func TestLoadConfigUnrecognizedFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.ini")
+	if err := os.WriteFile(path, []byte("ENV=DEV"), 0o644); err != nil {
+		t.Fatalf("failed to write fixture: %v", err)
+	}
+
+	if _, err := LoadConfig(path); err == nil {
+		t.Error("expected LoadConfig to reject an unrecognized extension, got nil error")
+	}
+}
+
+func TestApplyEnvOverlay(t *testing.T) {
+	t.Setenv("FLIGHT_PORT", "9090")
+	t.Setenv("FLIGHT_ALLOWSTAGING", "true")
+
+	cfg := &Config{Port: "8080", ENV: "DEV"}
+	applyEnvOverlay(cfg)
+
+	if cfg.Port != "9090" {
+		t.Errorf("expected FLIGHT_PORT to override Port, got %q", cfg.Port)
+	}
+	if !cfg.AllowStaging {
+		t.Error("expected FLIGHT_ALLOWSTAGING=true to set AllowStaging")
+	}
+	if cfg.ENV != "DEV" {
+		t.Errorf("expected unset FLIGHT_ENV to leave ENV untouched, got %q", cfg.ENV)
+	}
+}
+
+func TestConfigStoreGetSubscribe(t *testing.T) {
+	first := &Config{Port: "8080"}
+	store.set(first)
+	if got := Get(); got != first {
+		t.Fatalf("Get() = %+v, want the first config set", *got)
+	}
+
+	var oldSeen, newSeen *Config
+	Subscribe(func(old, new *Config) {
+		oldSeen, newSeen = old, new
+	})
+
+	second := &Config{Port: "9090"}
+	store.set(second)
+
+	if Get() != second {
+		t.Errorf("Get() after second set = %+v, want %+v", *Get(), *second)
+	}
+	if oldSeen != first || newSeen != second {
+		t.Errorf("Subscribe callback saw old=%v new=%v, want old=%v new=%v", oldSeen, newSeen, first, second)
+	}
+}
+
+func TestConfigGetCredential(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sa.json")
+	if err := os.WriteFile(path, []byte(`{"type":"service_account"}`), 0o600); err != nil {
+		t.Fatalf("failed to write fixture: %v", err)
+	}
+
+	cfg := &Config{
+		Credentials: []credential.CredentialRef{
+			{Name: "bigquery", Provider: "file", Key: path},
+		},
+	}
+
+	if err := resolveCredentials(cfg); err != nil {
+		t.Fatalf("resolveCredentials returned error: %v", err)
+	}
+
+	got, err := cfg.GetCredential("bigquery")
+	if err != nil {
+		t.Fatalf("GetCredential(bigquery) returned error: %v", err)
+	}
+	if string(got.Data) != `{"type":"service_account"}` {
+		t.Errorf("GetCredential(bigquery).Data = %q, want service account JSON", got.Data)
+	}
+
+	if _, err := cfg.GetCredential("missing"); err == nil {
+		t.Error("expected GetCredential to error for an unconfigured name")
+	}
+}
+
+func TestConfigResolveCredentialsFailsFast(t *testing.T) {
+	cfg := &Config{
+		Credentials: []credential.CredentialRef{
+			{Name: "bigquery", Provider: "file", Key: filepath.Join(t.TempDir(), "does-not-exist.json")},
+		},
+	}
+
+	if err := resolveCredentials(cfg); err == nil {
+		t.Error("expected resolveCredentials to fail for a missing credential file")
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	dir := t.TempDir()
+
+	cases := []struct {
+		name    string
+		cfg     Config
+		wantErr string // a path expected somewhere in the error list, or "" for none
+	}{
+		{
+			name: "valid",
+			cfg: Config{
+				Port:        "8080",
+				ENV:         "DEV",
+				TopLevelDir: dir,
+			},
+			wantErr: "",
+		},
+		{
+			name:    "bad port",
+			cfg:     Config{Port: "not-a-port", ENV: "DEV"},
+			wantErr: "$.Port",
+		},
+		{
+			name:    "port out of range",
+			cfg:     Config{Port: "99999", ENV: "DEV"},
+			wantErr: "$.Port",
+		},
+		{
+			name:    "bad env",
+			cfg:     Config{Port: "8080", ENV: "Staging"},
+			wantErr: "$.ENV",
+		},
+		{
+			name:    "missing dir",
+			cfg:     Config{Port: "8080", ENV: "DEV", TopLevelDir: filepath.Join(dir, "does-not-exist")},
+			wantErr: "$.TopLevelDir",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := tc.cfg.Validate()
+
+			if tc.wantErr == "" {
+				if len(errs) != 0 {
+					t.Errorf("Validate() = %v, want no errors", errs)
+				}
+				return
+			}
+
+			found := false
+			for _, e := range errs {
+				if e.Path == tc.wantErr {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("Validate() = %v, want an error at %s", errs, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigSearchPathsHonorsFlightConfigHint(t *testing.T) {
+	t.Setenv("FLIGHT_CONFIG", "/etc/flight/override.json")
+
+	paths := ConfigSearchPaths()
+	if len(paths) == 0 || paths[0] != "/etc/flight/override.json" {
+		t.Errorf("expected FLIGHT_CONFIG hint to lead ConfigSearchPaths, got %v", paths[:min(3, len(paths))])
+	}
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/types"
+)
+
+// errClass labels err by its sentinel class (see pkg/types/provider_errors.go)
+// so a log line's error_class field groups by cause (rate limit vs. outage
+// vs. bad response) instead of needing to grep the formatted message.
+func errClass(err error) string {
+	switch {
+	case errors.Is(err, types.ErrRateLimited):
+		return "rate_limited"
+	case errors.Is(err, types.ErrNoData):
+		return "no_data"
+	case errors.Is(err, types.ErrProviderDown):
+		return "provider_down"
+	case errors.Is(err, types.ErrParse):
+		return "parse"
+	default:
+		return "unknown"
+	}
+}
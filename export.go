@@ -0,0 +1,225 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// exportRowGroupSize bounds how many rows exportHistoricalHandler buffers
+// into a single Parquet row group before flushing, so a multi-symbol,
+// multi-year dump doesn't grow one row group unboundedly.
+const exportRowGroupSize = 50000
+
+// exportDateLayout is the ISO-8601 date format exportHistoricalHandler
+// accepts for start_date/end_date and writes for CSV rows.
+const exportDateLayout = "2006-01-02"
+
+// historicalExportRow is the Parquet row shape for one stock_historical_data
+// bar, mirroring the CSV columns written by writeHistoricalCSVRows.
+type historicalExportRow struct {
+	Symbol   string  `parquet:"name=symbol, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Date     int64   `parquet:"name=date, type=INT64"`
+	Open     float64 `parquet:"name=open, type=DOUBLE"`
+	High     float64 `parquet:"name=high, type=DOUBLE"`
+	Low      float64 `parquet:"name=low, type=DOUBLE"`
+	Close    float64 `parquet:"name=close, type=DOUBLE"`
+	AdjClose float64 `parquet:"name=adj_close, type=DOUBLE"`
+	Volume   int64   `parquet:"name=volume, type=INT64"`
+	Source   string  `parquet:"name=source, type=BYTE_ARRAY, convertedtype=UTF8"`
+}
+
+// exportHistoricalHandler streams every requested symbol's
+// stock_historical_data rows as a single CSV or Parquet file, so downstream
+// tooling (Python, R) can pull the data without touching SQLite directly.
+// "symbols" is a required comma-separated list; "format" is "csv" (default)
+// or "parquet"; "start_date"/"end_date" are optional ISO-8601 dates
+// (exportDateLayout) bounding the query, defaulting to an unbounded range.
+// Rows are written as they're scanned rather than collected into memory
+// first, so the response streams with chunked transfer encoding even for a
+// multi-GB dump.
+func exportHistoricalHandler(w http.ResponseWriter, r *http.Request) {
+	symbolsParam := r.URL.Query().Get("symbols")
+	if symbolsParam == "" {
+		http.Error(w, "symbols is required", http.StatusBadRequest)
+		return
+	}
+	var symbols []string
+	for _, s := range strings.Split(symbolsParam, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			symbols = append(symbols, s)
+		}
+	}
+	if len(symbols) == 0 {
+		http.Error(w, "symbols is required", http.StatusBadRequest)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "parquet" {
+		http.Error(w, "format must be 'csv' or 'parquet'", http.StatusBadRequest)
+		return
+	}
+
+	startDate, err := parseExportDate(r.URL.Query().Get("start_date"), time.Time{})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid start_date: %v", err), http.StatusBadRequest)
+		return
+	}
+	endDate, err := parseExportDate(r.URL.Query().Get("end_date"), time.Now())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid end_date: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	placeholders := make([]string, len(symbols))
+	args := make([]interface{}, 0, len(symbols)+2)
+	for i, symbol := range symbols {
+		placeholders[i] = "?"
+		args = append(args, symbol)
+	}
+	args = append(args, startDate.Unix(), endDate.Unix())
+
+	rows, err := db.Query(fmt.Sprintf(`
+		SELECT symbol, date, open, high, low, close, adj_close, volume, source
+		FROM stock_historical_data
+		WHERE symbol IN (%s) AND date BETWEEN ? AND ?
+		ORDER BY symbol ASC, date ASC
+	`, strings.Join(placeholders, ",")), args...)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Database error: %v", err), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="historical_data.csv"`)
+		err = writeHistoricalCSVRows(w, rows)
+	} else {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", `attachment; filename="historical_data.parquet"`)
+		err = writeHistoricalParquetRows(w, rows)
+	}
+	if err != nil {
+		log.Printf("exportHistoricalHandler: failed to stream %s export: %v", format, err)
+	}
+}
+
+// parseExportDate parses an ISO-8601 date query param, returning fallback
+// if raw is empty.
+func parseExportDate(raw string, fallback time.Time) (time.Time, error) {
+	if raw == "" {
+		return fallback, nil
+	}
+	return time.Parse(exportDateLayout, raw)
+}
+
+// writeHistoricalCSVRows writes rows to w as RFC 4180 CSV with an
+// ISO-8601 date column, flushing after every record so a large dump
+// streams rather than buffering.
+func writeHistoricalCSVRows(w http.ResponseWriter, rows *sql.Rows) error {
+	flusher, _ := w.(http.Flusher)
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"symbol", "date", "open", "high", "low", "close", "adj_close", "volume", "source"}); err != nil {
+		return err
+	}
+
+	for rows.Next() {
+		var symbol, source string
+		var timestamp, volume int64
+		var open, high, low, close, adjClose float64
+		if err := rows.Scan(&symbol, &timestamp, &open, &high, &low, &close, &adjClose, &volume, &source); err != nil {
+			return err
+		}
+
+		record := []string{
+			symbol,
+			time.Unix(timestamp, 0).UTC().Format(exportDateLayout),
+			strconv.FormatFloat(open, 'f', -1, 64),
+			strconv.FormatFloat(high, 'f', -1, 64),
+			strconv.FormatFloat(low, 'f', -1, 64),
+			strconv.FormatFloat(close, 'f', -1, 64),
+			strconv.FormatFloat(adjClose, 'f', -1, 64),
+			strconv.FormatInt(volume, 10),
+			source,
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// writeHistoricalParquetRows writes rows to w as Parquet, flushing a new
+// row group every exportRowGroupSize rows so memory use stays bounded
+// regardless of how many rows the query returns.
+func writeHistoricalParquetRows(w http.ResponseWriter, rows *sql.Rows) error {
+	pw, err := writer.NewParquetWriterFromWriter(w, new(historicalExportRow), 1)
+	if err != nil {
+		return fmt.Errorf("failed to create parquet writer: %w", err)
+	}
+
+	flusher, _ := w.(http.Flusher)
+
+	var n int
+	for rows.Next() {
+		var symbol, source string
+		var timestamp, volume int64
+		var open, high, low, close, adjClose float64
+		if err := rows.Scan(&symbol, &timestamp, &open, &high, &low, &close, &adjClose, &volume, &source); err != nil {
+			return err
+		}
+
+		if err := pw.Write(historicalExportRow{
+			Symbol:   symbol,
+			Date:     timestamp,
+			Open:     open,
+			High:     high,
+			Low:      low,
+			Close:    close,
+			AdjClose: adjClose,
+			Volume:   volume,
+			Source:   source,
+		}); err != nil {
+			return fmt.Errorf("failed to write row: %w", err)
+		}
+
+		n++
+		if n%exportRowGroupSize == 0 {
+			if err := pw.Flush(true); err != nil {
+				return fmt.Errorf("failed to flush row group: %w", err)
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	return pw.WriteStop()
+}
@@ -0,0 +1,510 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/graphql"
+)
+
+// graphql.go wires this app's tables and Job manager into a small GraphQL
+// surface at POST /query (and a GraphiQL UI at GET /graphiql), typed over
+// Email, Stock, HistoricalBar, BacktestRun, and Job. It's built on
+// pkg/graphql rather than gqlgen (see that package's doc comment for why)
+// and is additive: /api/tables, /api/tables/{table}, and the rest of this
+// app's REST endpoints are untouched, staying available as thin wrappers
+// during the migration this type of endpoint is meant to replace.
+
+// intArg reads an integer "first"-style argument, defaulting and clamping
+// it to a sane page size so a client can't force an unbounded scan.
+func intArg(args map[string]any, name string, def int) int {
+	v, ok := args[name]
+	if !ok {
+		return def
+	}
+	n, ok := v.(int64)
+	if !ok || n <= 0 {
+		return def
+	}
+	if n > 200 {
+		return 200
+	}
+	return int(n)
+}
+
+func stringArg(args map[string]any, name string) string {
+	s, _ := args[name].(string)
+	return s
+}
+
+func stringListArg(args map[string]any, name string) []string {
+	raw, _ := args[name].([]any)
+	out := make([]string, 0, len(raw))
+	for _, v := range raw {
+		if s, ok := v.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// historicalBar is the batch-fetched shape historicalBarsLoader groups by
+// symbol for Stock.historicalBars.
+type historicalBar struct {
+	Date     time.Time
+	Open     float64
+	High     float64
+	Low      float64
+	Close    float64
+	AdjClose float64
+	Volume   int64
+	Source   string
+}
+
+func historicalBarObject(symbol string, b historicalBar) graphql.Object {
+	return graphql.Object{
+		"symbol":   scalarField(symbol),
+		"date":     scalarField(b.Date.Format(time.RFC3339)),
+		"open":     scalarField(b.Open),
+		"high":     scalarField(b.High),
+		"low":      scalarField(b.Low),
+		"close":    scalarField(b.Close),
+		"adjClose": scalarField(b.AdjClose),
+		"volume":   scalarField(b.Volume),
+		"source":   scalarField(b.Source),
+		"cursor":   scalarField(graphql.EncodeCursor(symbol, b.Date.Format(time.RFC3339))),
+	}
+}
+
+// scalarField wraps a plain value (already computed, no further lookup
+// needed) as a graphql.Resolver, for fields that don't need ctx/args.
+func scalarField(value any) graphql.FieldDef {
+	return graphql.Field(func(ctx context.Context, args map[string]any, sub []*graphql.Selection) (any, error) {
+		return value, nil
+	})
+}
+
+// batchHistoricalBars loads every row in stock_historical_data for the
+// given symbols in a single query, grouped by symbol. It's the batchFn
+// behind each page of stocks' shared graphql.Loader, so N stocks on one
+// page cost one query instead of N (see pkg/graphql's Loader doc comment).
+func batchHistoricalBars(ctx context.Context, db *sql.DB, symbols []string) (map[string][]historicalBar, error) {
+	if len(symbols) == 0 {
+		return map[string][]historicalBar{}, nil
+	}
+
+	placeholders := make([]string, len(symbols))
+	queryArgs := make([]any, len(symbols))
+	for i, s := range symbols {
+		placeholders[i] = "?"
+		queryArgs[i] = s
+	}
+
+	rows, err := db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT symbol, date, open, high, low, close, adj_close, volume, source
+		FROM stock_historical_data
+		WHERE symbol IN (%s)
+		ORDER BY symbol, date
+	`, strings.Join(placeholders, ",")), queryArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to batch-fetch historical bars: %v", err)
+	}
+	defer rows.Close()
+
+	bySymbol := make(map[string][]historicalBar)
+	for rows.Next() {
+		var symbol, source string
+		var dateUnix int64
+		var b historicalBar
+		if err := rows.Scan(&symbol, &dateUnix, &b.Open, &b.High, &b.Low, &b.Close, &b.AdjClose, &b.Volume, &source); err != nil {
+			return nil, fmt.Errorf("failed to scan historical bar: %v", err)
+		}
+		b.Date = time.Unix(dateUnix, 0).UTC()
+		b.Source = source
+		bySymbol[symbol] = append(bySymbol[symbol], b)
+	}
+
+	return bySymbol, nil
+}
+
+// historicalBarsField returns the resolver for a single Stock's
+// historicalBars(first, after) connection, paginated in-memory (by date,
+// cursor (symbol,date)) over whatever loader already batch-fetched for
+// symbol.
+func historicalBarsField(symbol string, loader *graphql.Loader[string, []historicalBar]) graphql.FieldDef {
+	return graphql.Field(func(ctx context.Context, args map[string]any, sub []*graphql.Selection) (any, error) {
+		bars, err := loader.Load(ctx, symbol)
+		if err != nil {
+			return nil, err
+		}
+
+		first := intArg(args, "first", 30)
+		var afterDate string
+		if after := stringArg(args, "after"); after != "" {
+			parts, err := graphql.DecodeCursor(after)
+			if err != nil {
+				return nil, err
+			}
+			if len(parts) == 2 {
+				afterDate = parts[1]
+			}
+		}
+
+		var page []graphql.Object
+		for _, b := range bars {
+			if afterDate != "" && b.Date.Format(time.RFC3339) <= afterDate {
+				continue
+			}
+			page = append(page, historicalBarObject(symbol, b))
+			if len(page) >= first {
+				break
+			}
+		}
+		return page, nil
+	})
+}
+
+func stockObject(db *sql.DB, symbol, companyName string, price, changePercent float64, lastUpdated int64, loader *graphql.Loader[string, []historicalBar]) graphql.Object {
+	return graphql.Object{
+		"symbol":         scalarField(symbol),
+		"companyName":    scalarField(companyName),
+		"price":          scalarField(price),
+		"changePercent":  scalarField(changePercent),
+		"lastUpdated":    scalarField(time.Unix(lastUpdated, 0).UTC().Format(time.RFC3339)),
+		"cursor":         scalarField(graphql.EncodeCursor(symbol)),
+		"historicalBars": historicalBarsField(symbol, loader),
+	}
+}
+
+// stocksResolver answers the root "stocks" connection: stocks(first, after,
+// symbols) { ... }, symbol-ordered, optionally filtered to symbols.
+func stocksResolver(a *App) graphql.Resolver {
+	return func(ctx context.Context, args map[string]any, sub []*graphql.Selection) (any, error) {
+		first := intArg(args, "first", 20)
+		var afterSymbol string
+		if after := stringArg(args, "after"); after != "" {
+			parts, err := graphql.DecodeCursor(after)
+			if err != nil {
+				return nil, err
+			}
+			afterSymbol = parts[0]
+		}
+		symbolFilter := stringListArg(args, "symbols")
+
+		query := `SELECT symbol, company_name, price, change_percent, last_updated FROM stock_data WHERE symbol > ?`
+		queryArgs := []any{afterSymbol}
+		if len(symbolFilter) > 0 {
+			placeholders := make([]string, len(symbolFilter))
+			for i, s := range symbolFilter {
+				placeholders[i] = "?"
+				queryArgs = append(queryArgs, s)
+			}
+			query += fmt.Sprintf(" AND symbol IN (%s)", strings.Join(placeholders, ","))
+		}
+		query += " ORDER BY symbol LIMIT ?"
+		queryArgs = append(queryArgs, first)
+
+		rows, err := a.db.QueryContext(ctx, query, queryArgs...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list stocks: %v", err)
+		}
+		defer rows.Close()
+
+		type row struct {
+			symbol, companyName  string
+			price, changePercent float64
+			lastUpdated          int64
+		}
+		var page []row
+		var symbols []string
+		for rows.Next() {
+			var r row
+			if err := rows.Scan(&r.symbol, &r.companyName, &r.price, &r.changePercent, &r.lastUpdated); err != nil {
+				return nil, fmt.Errorf("failed to scan stock: %v", err)
+			}
+			page = append(page, r)
+			symbols = append(symbols, r.symbol)
+		}
+
+		loader := graphql.NewLoader(symbols, func(ctx context.Context, keys []string) (map[string][]historicalBar, error) {
+			return batchHistoricalBars(ctx, a.db, keys)
+		})
+
+		stocks := make([]graphql.Object, len(page))
+		for i, r := range page {
+			stocks[i] = stockObject(a.db, r.symbol, r.companyName, r.price, r.changePercent, r.lastUpdated, loader)
+		}
+		return stocks, nil
+	}
+}
+
+func emailObject(id, threadID, subject, from, to string, date int64, labelIDs string) graphql.Object {
+	return graphql.Object{
+		"id":       scalarField(id),
+		"threadId": scalarField(threadID),
+		"subject":  scalarField(subject),
+		"from":     scalarField(from),
+		"to":       scalarField(to),
+		"date":     scalarField(time.Unix(date, 0).UTC().Format(time.RFC3339)),
+		"labelIds": scalarField(strings.Split(labelIDs, ",")),
+		"cursor":   scalarField(graphql.EncodeCursor(fmt.Sprintf("%020d", date), id)),
+	}
+}
+
+// emailsResolver answers emails(first, after, fromDate, toDate, label):
+// a ScopeEmails-restricted connection over the emails table, newest first.
+// fromDate/toDate are RFC3339 timestamps; label filters to a single Gmail
+// label ID found in the comma-joined label_ids column.
+func emailsResolver(a *App) graphql.Resolver {
+	return func(ctx context.Context, args map[string]any, sub []*graphql.Selection) (any, error) {
+		first := intArg(args, "first", 20)
+
+		var afterDate int64 = 1<<63 - 1
+		var afterID string
+		if after := stringArg(args, "after"); after != "" {
+			parts, err := graphql.DecodeCursor(after)
+			if err != nil {
+				return nil, err
+			}
+			if len(parts) == 2 {
+				if n, err := strconv.ParseInt(parts[0], 10, 64); err == nil {
+					afterDate = n
+				}
+				afterID = parts[1]
+			}
+		}
+
+		query := `SELECT id, thread_id, subject, from_address, to_address, date, label_ids FROM emails WHERE (date < ? OR (date = ? AND id > ?))`
+		queryArgs := []any{afterDate, afterDate, afterID}
+
+		if fromDate := stringArg(args, "fromDate"); fromDate != "" {
+			t, err := time.Parse(time.RFC3339, fromDate)
+			if err != nil {
+				return nil, fmt.Errorf("invalid fromDate: %v", err)
+			}
+			query += " AND date >= ?"
+			queryArgs = append(queryArgs, t.Unix())
+		}
+		if toDate := stringArg(args, "toDate"); toDate != "" {
+			t, err := time.Parse(time.RFC3339, toDate)
+			if err != nil {
+				return nil, fmt.Errorf("invalid toDate: %v", err)
+			}
+			query += " AND date <= ?"
+			queryArgs = append(queryArgs, t.Unix())
+		}
+		if label := stringArg(args, "label"); label != "" {
+			query += " AND (',' || label_ids || ',') LIKE ?"
+			queryArgs = append(queryArgs, "%,"+label+",%")
+		}
+
+		query += " ORDER BY date DESC, id ASC LIMIT ?"
+		queryArgs = append(queryArgs, first)
+
+		rows, err := a.db.QueryContext(ctx, query, queryArgs...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list emails: %v", err)
+		}
+		defer rows.Close()
+
+		var page []graphql.Object
+		for rows.Next() {
+			var id, threadID, subject, from, to, labelIDs string
+			var date int64
+			if err := rows.Scan(&id, &threadID, &subject, &from, &to, &date, &labelIDs); err != nil {
+				return nil, fmt.Errorf("failed to scan email: %v", err)
+			}
+			page = append(page, emailObject(id, threadID, subject, from, to, date, labelIDs))
+		}
+		return page, nil
+	}
+}
+
+func jobObject(s JobSnapshot) graphql.Object {
+	return graphql.Object{
+		"id":        scalarField(s.ID),
+		"status":    scalarField(s.Status),
+		"done":      scalarField(s.Done),
+		"total":     scalarField(s.Total),
+		"startedAt": scalarField(s.StartedAt.UTC().Format(time.RFC3339)),
+		"error":     scalarField(s.Error),
+		"cursor":    scalarField(graphql.EncodeCursor(s.StartedAt.UTC().Format(time.RFC3339), s.ID)),
+	}
+}
+
+// sortedJobSnapshots returns jobs.list() sorted oldest-first; list() itself
+// iterates a map, so its order isn't reproducible across calls, and a
+// cursor-paginated connection needs one.
+func sortedJobSnapshots() []JobSnapshot {
+	snapshots := jobs.list()
+	sort.Slice(snapshots, func(i, j int) bool {
+		if snapshots[i].StartedAt.Equal(snapshots[j].StartedAt) {
+			return snapshots[i].ID < snapshots[j].ID
+		}
+		return snapshots[i].StartedAt.Before(snapshots[j].StartedAt)
+	})
+	return snapshots
+}
+
+// jobsConnection is shared by the @private "jobs" field and the
+// ScopeBacktests-restricted "backtestRuns" field: this codebase doesn't
+// distinguish a "backtest run" from any other Job, so backtestRuns is
+// simply jobs viewed through a narrower access requirement.
+func jobsConnection(args map[string]any) ([]graphql.Object, error) {
+	first := intArg(args, "first", 20)
+
+	var afterStarted string
+	var afterID string
+	if after := stringArg(args, "after"); after != "" {
+		parts, err := graphql.DecodeCursor(after)
+		if err != nil {
+			return nil, err
+		}
+		if len(parts) == 2 {
+			afterStarted, afterID = parts[0], parts[1]
+		}
+	}
+
+	var page []graphql.Object
+	for _, snap := range sortedJobSnapshots() {
+		if afterStarted != "" {
+			started := snap.StartedAt.UTC().Format(time.RFC3339)
+			if started < afterStarted || (started == afterStarted && snap.ID <= afterID) {
+				continue
+			}
+		}
+		page = append(page, jobObject(snap))
+		if len(page) >= first {
+			break
+		}
+	}
+	return page, nil
+}
+
+func jobsResolver(ctx context.Context, args map[string]any, sub []*graphql.Selection) (any, error) {
+	return jobsConnection(args)
+}
+
+func backtestRunsResolver(ctx context.Context, args map[string]any, sub []*graphql.Selection) (any, error) {
+	return jobsConnection(args)
+}
+
+// schema builds this app's root GraphQL query object. It deliberately
+// doesn't expose raw tables the way /api/tables/{table} does — the whole
+// point of this endpoint is a typed, paginated surface over the handful of
+// shapes that actually matter, not another window onto arbitrary SELECT *.
+func (a *App) schema() graphql.Object {
+	return graphql.Object{
+		"stocks":       graphql.Field(stocksResolver(a)),
+		"emails":       graphql.RestrictedField(&graphql.Access{Scope: graphql.ScopeEmails, Kind: graphql.KindRO}, emailsResolver(a)),
+		"jobs":         graphql.RestrictedField(graphql.Private, jobsResolver),
+		"backtestRuns": graphql.RestrictedField(&graphql.Access{Scope: graphql.ScopeBacktests, Kind: graphql.KindRO}, backtestRunsResolver),
+	}
+}
+
+// queryRequest is the POST /query request body: a GraphQL document and,
+// for parity with a standard GraphQL endpoint, a variables object this
+// first cut doesn't yet interpret (pkg/graphql has no $variable support;
+// every argument must be a literal).
+type queryRequest struct {
+	Query string `json:"query"`
+}
+
+type queryResponse struct {
+	Data   any      `json:"data,omitempty"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// viewerForRequest builds the graphql.Viewer for r: authenticated (with
+// every scope) if its session cookie carries a still-valid OAuth token,
+// unauthenticated otherwise. This schema has no finer-grained, per-scope
+// session state to check yet, so an authenticated session is trusted for
+// all of it.
+func viewerForRequest(r *http.Request) graphql.Viewer {
+	sessionID, err := sessionIDFromRequest(r)
+	if err != nil {
+		return graphql.Viewer{}
+	}
+	if _, err := sessionToken(db, sessionID); err != nil {
+		return graphql.Viewer{}
+	}
+	return graphql.Viewer{
+		Authenticated: true,
+		Scopes: map[graphql.AccessScope]bool{
+			graphql.ScopeStocks:    true,
+			graphql.ScopeEmails:    true,
+			graphql.ScopeBacktests: true,
+		},
+	}
+}
+
+// queryHandler serves POST /query: it parses a GraphQL document out of the
+// request body, executes it against this app's schema, and writes a
+// {data} or {errors} response, the conventional shape for a GraphQL HTTP
+// endpoint.
+func (a *App) queryHandler(w http.ResponseWriter, r *http.Request) {
+	var req queryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeQueryError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+
+	doc, err := graphql.Parse(req.Query)
+	if err != nil {
+		writeQueryError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := graphql.WithViewer(r.Context(), viewerForRequest(r))
+	data, err := graphql.Execute(ctx, a.schema(), doc)
+	if err != nil {
+		writeQueryError(w, http.StatusOK, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(queryResponse{Data: data})
+}
+
+func writeQueryError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(queryResponse{Errors: []string{msg}})
+}
+
+// graphiqlHandler serves a minimal GraphiQL UI at GET /graphiql, pointed at
+// POST /query. It loads GraphiQL from a CDN bundle rather than vendoring a
+// JS build pipeline into what's otherwise a pure Go repo.
+func (a *App) graphiqlHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, graphiqlHTML)
+}
+
+const graphiqlHTML = `<!DOCTYPE html>
+<html>
+<head>
+  <title>GraphiQL</title>
+  <link rel="stylesheet" href="https://unpkg.com/graphiql/graphiql.min.css" />
+</head>
+<body style="margin: 0;">
+  <div id="graphiql" style="height: 100vh;"></div>
+  <script crossorigin src="https://unpkg.com/react/umd/react.production.min.js"></script>
+  <script crossorigin src="https://unpkg.com/react-dom/umd/react-dom.production.min.js"></script>
+  <script crossorigin src="https://unpkg.com/graphiql/graphiql.min.js"></script>
+  <script>
+    const fetcher = GraphiQL.createFetcher({ url: '/query' });
+    ReactDOM.render(
+      React.createElement(GraphiQL, { fetcher: fetcher }),
+      document.getElementById('graphiql'),
+    );
+  </script>
+</body>
+</html>
+`
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/graphql"
+)
+
+func TestIntArgClampsToMax(t *testing.T) {
+	if got := intArg(map[string]any{"first": int64(1000)}, "first", 20); got != 200 {
+		t.Errorf("intArg(1000) = %d, want clamped to 200", got)
+	}
+	if got := intArg(map[string]any{}, "first", 20); got != 20 {
+		t.Errorf("intArg(missing) = %d, want default 20", got)
+	}
+}
+
+func TestStocksResolverReturnsInsertedStock(t *testing.T) {
+	if _, err := db.Exec(`INSERT OR REPLACE INTO stock_data (symbol, company_name, price, change_percent, last_updated) VALUES (?, ?, ?, ?, ?)`,
+		"ZZZZ", "Test Co", 12.34, 1.5, time.Now().Unix()); err != nil {
+		t.Fatalf("seed stock_data: %v", err)
+	}
+
+	a := &App{db: db}
+	resolve := stocksResolver(a)
+	result, err := resolve(context.Background(), map[string]any{"symbols": []any{"ZZZZ"}}, nil)
+	if err != nil {
+		t.Fatalf("stocksResolver: %v", err)
+	}
+
+	stocks, ok := result.([]graphql.Object)
+	if !ok || len(stocks) != 1 {
+		t.Fatalf("stocksResolver result = %+v, want one Stock", result)
+	}
+}
+
+func TestEmailsResolverRequiresScope(t *testing.T) {
+	root := (&App{db: db}).schema()
+	doc, err := graphql.Parse(`{ emails { id } }`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	if _, err := graphql.Execute(context.Background(), root, doc); err == nil {
+		t.Error("Execute(unauthenticated) = nil error, want access denied for emails")
+	}
+
+	authed := graphql.WithViewer(context.Background(), graphql.Viewer{
+		Authenticated: true,
+		Scopes:        map[graphql.AccessScope]bool{graphql.ScopeEmails: true},
+	})
+	if _, err := graphql.Execute(authed, root, doc); err != nil {
+		t.Errorf("Execute(authorized) = %v, want nil", err)
+	}
+}
+
+func TestJobsConnectionPaginatesByStartTime(t *testing.T) {
+	jobs.start(1, func(ctx context.Context, job *Job) {})
+	jobs.start(1, func(ctx context.Context, job *Job) {})
+
+	page, err := jobsConnection(map[string]any{"first": int64(1)})
+	if err != nil {
+		t.Fatalf("jobsConnection: %v", err)
+	}
+	if len(page) != 1 {
+		t.Fatalf("jobsConnection(first: 1) returned %d jobs, want 1", len(page))
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDefaultConstants(t *testing.T) {
+	c := defaultConstants()
+	if c.DBFile != dbFile || c.TokenFile != tokenFile || c.ServerPort != serverPort {
+		t.Errorf("defaultConstants() = %+v, want fields matching the credentialsFile/tokenFile/dbFile/serverPort consts", c)
+	}
+}
+
+func TestAppMiddlewareInjectsApp(t *testing.T) {
+	app := &App{constants: defaultConstants()}
+
+	var gotFromCtx *App
+	handler := appMiddleware(app)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotFromCtx = appFromContext(r.Context())
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if gotFromCtx != app {
+		t.Errorf("appFromContext returned %p, want the App %p appMiddleware wrapped", gotFromCtx, app)
+	}
+}
@@ -0,0 +1,502 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// JobEvent is one entry in a job's progress stream, rendered to SSE clients
+// as a "data: <json>\n\n" frame.
+type JobEvent struct {
+	Type      string    `json:"type"` // "started", "completed", "failed", or "summary"
+	Seq       int       `json:"seq"`  // 1-based, monotonically increasing per job; doubles as the SSE "id" for Last-Event-ID resume
+	Symbol    string    `json:"symbol,omitempty"`
+	Message   string    `json:"message,omitempty"`
+	Done      int       `json:"done"`
+	Total     int       `json:"total"`
+	Timestamp time.Time `json:"timestamp"`
+
+	// Rows and BytesSaved are set by fillHistoricalDataHandler's
+	// incremental/repair fetch modes: Rows is how many rows this symbol's
+	// fetch(es) inserted, BytesSaved is an estimate (see
+	// estimatedBytesPerBar) of the transfer avoided by not re-fetching
+	// date ranges already covered in stock_historical_data.
+	Rows       int   `json:"rows,omitempty"`
+	BytesSaved int64 `json:"bytes_saved,omitempty"`
+}
+
+// JobSnapshot is the JSON-safe summary of a Job returned by GET /api/jobs.
+type JobSnapshot struct {
+	ID        string    `json:"id"`
+	Status    string    `json:"status"` // "running", "completed", "failed", or "canceled"
+	Done      int       `json:"done"`
+	Total     int       `json:"total"`
+	StartedAt time.Time `json:"started_at"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Job tracks one background run (a historical data fill, a portfolio
+// backtest): its status, the events it has emitted so far, and the
+// subscribers currently streaming them over SSE.
+type Job struct {
+	ID        string
+	StartedAt time.Time
+
+	mu     sync.Mutex
+	status string
+	errMsg string
+	done   int
+	total  int
+	seq    int
+	events []JobEvent
+	subs   map[chan JobEvent]struct{}
+	cancel context.CancelFunc
+}
+
+// emit records e against the job, stamping it with the running done/total
+// counts, and fans it out to every current subscriber. A subscriber that
+// isn't keeping up has the event dropped rather than blocking the job.
+func (j *Job) emit(e JobEvent) {
+	e.Timestamp = time.Now()
+
+	j.mu.Lock()
+	if e.Type == "completed" || e.Type == "failed" {
+		j.done++
+	}
+	e.Done, e.Total = j.done, j.total
+	j.seq++
+	e.Seq = j.seq
+	j.events = append(j.events, e)
+	subs := make([]chan JobEvent, 0, len(j.subs))
+	for ch := range j.subs {
+		subs = append(subs, ch)
+	}
+	j.mu.Unlock()
+
+	for _, ch := range subs {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// Subscribe registers a new listener for this job's events and returns the
+// events already emitted so far plus a channel for everything after. The
+// returned unsubscribe func must be called (typically via defer) once the
+// caller stops reading.
+func (j *Job) Subscribe() (ch <-chan JobEvent, history []JobEvent, unsubscribe func()) {
+	c := make(chan JobEvent, 16)
+
+	j.mu.Lock()
+	history = append([]JobEvent{}, j.events...)
+	j.subs[c] = struct{}{}
+	j.mu.Unlock()
+
+	unsubscribe = func() {
+		j.mu.Lock()
+		delete(j.subs, c)
+		j.mu.Unlock()
+		close(c)
+	}
+
+	return c, history, unsubscribe
+}
+
+// Cancel requests that the job's goroutine stop at its next checkpoint and
+// marks it canceled. It does not wait for the goroutine to actually exit.
+func (j *Job) Cancel() {
+	j.mu.Lock()
+	if j.status == "running" {
+		j.status = "canceled"
+	}
+	j.mu.Unlock()
+	j.cancel()
+}
+
+// Fail marks the job failed with err and requests that its goroutine stop
+// at its next checkpoint, the same way Cancel does, but recording a
+// terminal error instead of a cancellation so streamers get an "error"
+// frame instead of "done".
+func (j *Job) Fail(err error) {
+	j.mu.Lock()
+	if j.status == "running" {
+		j.status = "failed"
+		j.errMsg = err.Error()
+	}
+	j.mu.Unlock()
+	j.cancel()
+}
+
+// Snapshot returns the job's current status as a JobSnapshot.
+func (j *Job) Snapshot() JobSnapshot {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return JobSnapshot{ID: j.ID, Status: j.status, Done: j.done, Total: j.total, StartedAt: j.StartedAt, Error: j.errMsg}
+}
+
+// JobManager tracks every job started since boot. Jobs aren't persisted, so
+// they're lost on restart.
+type JobManager struct {
+	mu   sync.Mutex
+	jobs map[string]*Job
+}
+
+// jobs is the process-wide JobManager backing the /api/jobs endpoints.
+var jobs = &JobManager{jobs: make(map[string]*Job)}
+
+// createJobsTable creates the jobs table, a durable record of each Job's
+// status/started_at/finished_at/error alongside the in-memory JobManager,
+// if it doesn't already exist. Jobs aren't reloaded from it on restart (the
+// in-memory JobManager still starts empty); it exists so status/error can
+// be inspected after a job's events have scrolled out of memory or the
+// process has restarted mid-job.
+func createJobsTable(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS jobs (
+			id TEXT PRIMARY KEY,
+			status TEXT NOT NULL,
+			started_at INTEGER NOT NULL,
+			finished_at INTEGER,
+			error TEXT
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create jobs table: %v", err)
+	}
+	return nil
+}
+
+// persistJobStart records job's start in the jobs table. It logs rather
+// than returns an error since a failure to persist shouldn't stop the job
+// itself from running.
+func persistJobStart(job *Job) {
+	if db == nil {
+		return
+	}
+	if _, err := db.Exec(`
+		INSERT OR REPLACE INTO jobs (id, status, started_at)
+		VALUES (?, 'running', ?)
+	`, job.ID, job.StartedAt.Unix()); err != nil {
+		log.Printf("jobs: failed to persist start of job %s: %v", job.ID, err)
+	}
+}
+
+// persistJobFinish records a job's terminal status, finish time, and error
+// (if any) in the jobs table.
+func persistJobFinish(id, status, errMsg string) {
+	if db == nil {
+		return
+	}
+	var errVal interface{}
+	if errMsg != "" {
+		errVal = errMsg
+	}
+	if _, err := db.Exec(`
+		UPDATE jobs SET status = ?, finished_at = ?, error = ? WHERE id = ?
+	`, status, time.Now().Unix(), errVal, id); err != nil {
+		log.Printf("jobs: failed to persist finish of job %s: %v", id, err)
+	}
+}
+
+// start registers a new Job with the given total unit count and runs fn in
+// its own goroutine, passing it a context that Cancel will cancel. Once fn
+// returns, the job is marked completed (unless Cancel already marked it
+// canceled) and a final "summary" event is emitted.
+func (m *JobManager) start(total int, fn func(ctx context.Context, job *Job)) *Job {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	job := &Job{
+		ID:        uuid.NewString(),
+		StartedAt: time.Now(),
+		status:    "running",
+		total:     total,
+		subs:      make(map[chan JobEvent]struct{}),
+		cancel:    cancel,
+	}
+
+	m.mu.Lock()
+	m.jobs[job.ID] = job
+	m.mu.Unlock()
+
+	persistJobStart(job)
+
+	go func() {
+		fn(ctx, job)
+
+		job.mu.Lock()
+		if job.status == "running" {
+			job.status = "completed"
+		}
+		status, done, total, errMsg := job.status, job.done, job.total, job.errMsg
+		job.mu.Unlock()
+
+		persistJobFinish(job.ID, status, errMsg)
+
+		job.emit(JobEvent{
+			Type:    "summary",
+			Message: fmt.Sprintf("job %s: %d/%d done", status, done, total),
+		})
+	}()
+
+	return job
+}
+
+// get returns the job registered under id, if any.
+func (m *JobManager) get(id string) (*Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[id]
+	return job, ok
+}
+
+// list returns a snapshot of every job the manager knows about.
+func (m *JobManager) list() []JobSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshots := make([]JobSnapshot, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		snapshots = append(snapshots, job.Snapshot())
+	}
+	return snapshots
+}
+
+// jobsListHandler returns a summary of every job started since boot.
+func jobsListHandler(w http.ResponseWriter, r *http.Request) {
+	sendJSONResponse(w, HandlerResponse{Success: true, Data: jobs.list()})
+}
+
+// jobStatusHandler returns a single job's current JobSnapshot, for a caller
+// polling progress instead of streaming GET /api/jobs/{id}/events.
+func jobStatusHandler(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	job, ok := jobs.get(id)
+	if !ok {
+		http.Error(w, fmt.Sprintf("no job %q", id), http.StatusNotFound)
+		return
+	}
+
+	sendJSONResponse(w, HandlerResponse{Success: true, Data: job.Snapshot()})
+}
+
+// jobEventsHandler streams a job's progress as Server-Sent Events: events
+// already emitted are replayed first, then new ones as they happen, until
+// the client disconnects or the job's closing "summary" event is sent.
+func jobEventsHandler(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	job, ok := jobs.get(id)
+	if !ok {
+		http.Error(w, fmt.Sprintf("no job %q", id), http.StatusNotFound)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	ch, history, unsubscribe := job.Subscribe()
+	defer unsubscribe()
+
+	writeEvent := func(e JobEvent) bool {
+		payload, err := json.Marshal(e)
+		if err != nil {
+			return true
+		}
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", payload); err != nil {
+			return false
+		}
+		flusher.Flush()
+		return true
+	}
+
+	for _, e := range history {
+		if !writeEvent(e) {
+			return
+		}
+		if e.Type == "summary" {
+			return
+		}
+	}
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e, ok := <-ch:
+			if !ok {
+				return
+			}
+			if !writeEvent(e) {
+				return
+			}
+			if e.Type == "summary" {
+				return
+			}
+		}
+	}
+}
+
+// jobCancelHandler requests that the named job stop at its next checkpoint.
+func jobCancelHandler(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	job, ok := jobs.get(id)
+	if !ok {
+		http.Error(w, fmt.Sprintf("no job %q", id), http.StatusNotFound)
+		return
+	}
+
+	job.Cancel()
+	sendJSONResponse(w, HandlerResponse{Success: true, Message: fmt.Sprintf("job %s canceled", id)})
+}
+
+// jobStreamHeartbeatInterval is how often jobStreamHandler sends an SSE
+// comment to keep a proxy from closing an idle connection.
+const jobStreamHeartbeatInterval = 15 * time.Second
+
+// jobStreamProgress is the payload of a "progress" frame: one ticker's
+// state plus the job's running done/total counts and elapsed time.
+type jobStreamProgress struct {
+	Ticker    string  `json:"ticker,omitempty"`
+	Completed int     `json:"completed"`
+	Total     int     `json:"total"`
+	Elapsed   float64 `json:"elapsed_seconds"`
+	Message   string  `json:"message,omitempty"`
+}
+
+// jobStreamTerminal is the payload of a "done" or "error" frame.
+type jobStreamTerminal struct {
+	Status  string  `json:"status"`
+	Elapsed float64 `json:"elapsed_seconds"`
+	Error   string  `json:"error,omitempty"`
+}
+
+// jobStreamFrame maps a JobEvent onto the event name / JSON payload pair
+// jobStreamHandler promises ("progress", "log", "done", "error"), as
+// opposed to jobEventsHandler's raw internal JobEvent.Type values.
+func jobStreamFrame(job *Job, e JobEvent, startedAt time.Time) (event string, payload interface{}) {
+	elapsed := e.Timestamp.Sub(startedAt).Seconds()
+
+	switch e.Type {
+	case "summary":
+		snap := job.Snapshot()
+		if snap.Status == "failed" {
+			return "error", jobStreamTerminal{Status: snap.Status, Elapsed: elapsed, Error: snap.Error}
+		}
+		return "done", jobStreamTerminal{Status: snap.Status, Elapsed: elapsed}
+	case "log":
+		return "log", map[string]string{"message": e.Message}
+	default:
+		return "progress", jobStreamProgress{
+			Ticker:    e.Symbol,
+			Completed: e.Done,
+			Total:     e.Total,
+			Elapsed:   elapsed,
+			Message:   e.Message,
+		}
+	}
+}
+
+// jobStreamHandler streams a job's progress as typed Server-Sent Events
+// (event: progress/log/done/error), each frame's "id:" set to the
+// underlying JobEvent's sequence number so a client that reconnects with a
+// Last-Event-ID header resumes after the last frame it saw instead of
+// replaying the whole history. A heartbeat comment every
+// jobStreamHeartbeatInterval keeps the connection alive across proxies
+// that time out idle streams.
+func jobStreamHandler(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	job, ok := jobs.get(id)
+	if !ok {
+		http.Error(w, fmt.Sprintf("no job %q", id), http.StatusNotFound)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	var lastSeq int
+	if raw := r.Header.Get("Last-Event-ID"); raw != "" {
+		lastSeq, _ = strconv.Atoi(raw)
+	}
+
+	ch, history, unsubscribe := job.Subscribe()
+	defer unsubscribe()
+
+	writeEvent := func(e JobEvent) bool {
+		if e.Seq <= lastSeq {
+			return true
+		}
+		event, payload := jobStreamFrame(job, e, job.StartedAt)
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return true
+		}
+		if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.Seq, event, data); err != nil {
+			return false
+		}
+		flusher.Flush()
+		return true
+	}
+
+	for _, e := range history {
+		if !writeEvent(e) {
+			return
+		}
+		if e.Type == "summary" {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(jobStreamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case e, ok := <-ch:
+			if !ok {
+				return
+			}
+			if !writeEvent(e) {
+				return
+			}
+			if e.Type == "summary" {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestJobManagerStartEmitsSummary(t *testing.T) {
+	m := &JobManager{jobs: make(map[string]*Job)}
+
+	job := m.start(2, func(ctx context.Context, job *Job) {
+		job.emit(JobEvent{Type: "started", Symbol: "AAPL"})
+		job.emit(JobEvent{Type: "completed", Symbol: "AAPL"})
+		job.emit(JobEvent{Type: "started", Symbol: "MSFT"})
+		job.emit(JobEvent{Type: "completed", Symbol: "MSFT"})
+	})
+
+	ch, history, unsubscribe := job.Subscribe()
+	defer unsubscribe()
+
+	events := append([]JobEvent{}, history...)
+	for len(events) == 0 || events[len(events)-1].Type != "summary" {
+		select {
+		case e := <-ch:
+			events = append(events, e)
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for summary event")
+		}
+	}
+
+	last := events[len(events)-1]
+	if last.Type != "summary" || last.Done != 2 || last.Total != 2 {
+		t.Errorf("summary event = %+v, want Done=2 Total=2", last)
+	}
+
+	if snap := job.Snapshot(); snap.Status != "completed" {
+		t.Errorf("job.Snapshot().Status = %q, want completed", snap.Status)
+	}
+
+	if _, ok := m.get(job.ID); !ok {
+		t.Error("expected JobManager.get to find the job by ID")
+	}
+}
+
+func TestJobCancel(t *testing.T) {
+	m := &JobManager{jobs: make(map[string]*Job)}
+	started := make(chan struct{})
+	released := make(chan struct{})
+
+	job := m.start(1, func(ctx context.Context, job *Job) {
+		close(started)
+		<-ctx.Done()
+		close(released)
+	})
+
+	<-started
+	job.Cancel()
+
+	select {
+	case <-released:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for Cancel to unblock the job goroutine")
+	}
+
+	if snap := job.Snapshot(); snap.Status != "canceled" {
+		t.Errorf("job.Snapshot().Status = %q, want canceled", snap.Status)
+	}
+}
+
+func TestJobFail(t *testing.T) {
+	m := &JobManager{jobs: make(map[string]*Job)}
+	started := make(chan struct{})
+
+	job := m.start(1, func(ctx context.Context, job *Job) {
+		close(started)
+		job.Fail(context.DeadlineExceeded)
+	})
+
+	<-started
+	time.Sleep(10 * time.Millisecond)
+
+	snap := job.Snapshot()
+	if snap.Status != "failed" {
+		t.Errorf("job.Snapshot().Status = %q, want failed", snap.Status)
+	}
+	if snap.Error != context.DeadlineExceeded.Error() {
+		t.Errorf("job.Snapshot().Error = %q, want %q", snap.Error, context.DeadlineExceeded.Error())
+	}
+}
+
+func TestJobStreamFrameMapsEventTypes(t *testing.T) {
+	job := &Job{ID: "job-1", StartedAt: time.Now(), status: "completed", subs: make(map[chan JobEvent]struct{})}
+
+	event, payload := jobStreamFrame(job, JobEvent{Type: "started", Symbol: "AAPL", Done: 0, Total: 2}, job.StartedAt)
+	if event != "progress" {
+		t.Errorf("jobStreamFrame(started) event = %q, want progress", event)
+	}
+	if p, ok := payload.(jobStreamProgress); !ok || p.Ticker != "AAPL" {
+		t.Errorf("jobStreamFrame(started) payload = %+v, want Ticker=AAPL", payload)
+	}
+
+	event, payload = jobStreamFrame(job, JobEvent{Type: "summary"}, job.StartedAt)
+	if event != "done" {
+		t.Errorf("jobStreamFrame(summary, completed) event = %q, want done", event)
+	}
+	if term, ok := payload.(jobStreamTerminal); !ok || term.Status != "completed" {
+		t.Errorf("jobStreamFrame(summary, completed) payload = %+v, want Status=completed", payload)
+	}
+
+	job.status = "failed"
+	job.errMsg = "boom"
+	event, payload = jobStreamFrame(job, JobEvent{Type: "summary"}, job.StartedAt)
+	if event != "error" {
+		t.Errorf("jobStreamFrame(summary, failed) event = %q, want error", event)
+	}
+	if term, ok := payload.(jobStreamTerminal); !ok || term.Error != "boom" {
+		t.Errorf("jobStreamFrame(summary, failed) payload = %+v, want Error=boom", payload)
+	}
+}
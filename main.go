@@ -1,15 +1,14 @@
 package main
 
 import (
-	"bytes"
-	"compress/gzip"
 	"context"
 	"database/sql"
 	"encoding/base64"
 	"encoding/json"
+	"flag"
 	"fmt"
-	"io"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"strconv"
@@ -17,13 +16,12 @@ import (
 	"sync"
 	"time"
 
-	"crypto/tls"
-
+	"github.com/darianmavgo/backtest-sell-limit/pkg/fetcher"
+	"github.com/darianmavgo/backtest-sell-limit/pkg/marketdata"
+	"github.com/darianmavgo/backtest-sell-limit/pkg/retry"
+	"github.com/darianmavgo/backtest-sell-limit/pkg/sp500"
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
 	_ "github.com/mattn/go-sqlite3"
-	"golang.org/x/net/html"
-	"golang.org/x/oauth2"
 	"google.golang.org/api/gmail/v1"
 	"google.golang.org/api/option"
 )
@@ -64,12 +62,19 @@ type StockData struct {
 	Open             float64   `json:"open"`
 	Close            float64   `json:"close"`
 	AdjClose         float64   `json:"adj_close"`
+	Source           string    `json:"source,omitempty"`
 }
 
 // StockResult represents the result of fetching stock data
 type StockResult struct {
 	Data *StockData
 	Err  error
+
+	// Attempts and LastStatus let a caller log/observe transient fetch
+	// failures (e.g. a 429 retried before succeeding) instead of seeing
+	// only the final outcome.
+	Attempts   int `json:"attempts,omitempty"`
+	LastStatus int `json:"last_status,omitempty"`
 }
 
 // HistoricalData represents a single day of stock data
@@ -89,6 +94,11 @@ type HistoricalResult struct {
 	Ticker string
 	Data   []HistoricalData
 	Err    error
+
+	// Attempts and LastStatus mirror StockResult's fields of the same
+	// name; see there for why they exist.
+	Attempts   int `json:"attempts,omitempty"`
+	LastStatus int `json:"last_status,omitempty"`
 }
 
 // CredentialInfo stores the raw credential file information
@@ -164,119 +174,6 @@ func ensureTokenDir() error {
 }
 
 // initDB initializes the SQLite database with WAL mode and creates the schema
-func initDB() (*sql.DB, error) {
-	db, err := sql.Open("sqlite3", dbFile+"?_journal_mode=WAL")
-	if err != nil {
-		return nil, fmt.Errorf("failed to open database: %v", err)
-	}
-
-	// Set connection pool settings
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(25)
-	db.SetConnMaxLifetime(5 * time.Minute)
-
-	// Create the emails table with the new schema
-	_, err = db.Exec(`
-		CREATE TABLE IF NOT EXISTS emails (
-			id TEXT PRIMARY KEY,
-			thread_id TEXT,
-			subject TEXT,
-			from_address TEXT,
-			to_address TEXT,
-			date INTEGER,
-			plain_text TEXT,
-			html TEXT,
-			label_ids TEXT,
-			UNIQUE(id)
-		)
-	`)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create table: %v", err)
-	}
-
-	// Create indexes
-	_, err = db.Exec(`
-		CREATE INDEX IF NOT EXISTS idx_thread_id ON emails(thread_id);
-		CREATE INDEX IF NOT EXISTS idx_date ON emails(date);
-		CREATE INDEX IF NOT EXISTS idx_subject ON emails(subject);
-	`)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create indexes: %v", err)
-	}
-
-	// Create the stock_data table for S&P 500 tickers
-	_, err = db.Exec(`
-		CREATE TABLE IF NOT EXISTS stock_data (
-			symbol TEXT PRIMARY KEY,
-			company_name TEXT,
-			price REAL,
-			change_amount REAL,
-			change_percent REAL,
-			volume INTEGER,
-			market_cap INTEGER,
-			previous_close REAL,
-			open_price REAL,
-			high REAL,
-			low REAL,
-			fifty_two_week_high REAL,
-			fifty_two_week_low REAL,
-			last_updated INTEGER,
-			UNIQUE(symbol)
-		)
-	`)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create stock_data table: %v", err)
-	}
-
-	// Create the stock_historical_data table
-	_, err = db.Exec(`
-		CREATE TABLE IF NOT EXISTS stock_historical_data (
-			symbol TEXT,
-			date INTEGER,
-			open REAL,
-			high REAL,
-			low REAL,
-			close REAL,
-			adj_close REAL,
-			volume INTEGER,
-			PRIMARY KEY (symbol, date)
-		)
-	`)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create stock_historical_data table: %v", err)
-	}
-
-	// Create indexes for emails
-	_, err = db.Exec(`
-		CREATE INDEX IF NOT EXISTS idx_thread_id ON emails(thread_id);
-		CREATE INDEX IF NOT EXISTS idx_date ON emails(date);
-		CREATE INDEX IF NOT EXISTS idx_subject ON emails(subject);
-	`)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create indexes: %v", err)
-	}
-
-	// Create indexes for stock_data
-	_, err = db.Exec(`
-		CREATE INDEX IF NOT EXISTS idx_stock_symbol ON stock_data(symbol);
-		CREATE INDEX IF NOT EXISTS idx_stock_updated ON stock_data(last_updated);
-		CREATE INDEX IF NOT EXISTS idx_stock_change_percent ON stock_data(change_percent);
-	`)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create stock indexes: %v", err)
-	}
-
-	// Create indexes for stock_historical_data
-	_, err = db.Exec(`
-		CREATE INDEX IF NOT EXISTS idx_historical_symbol_date ON stock_historical_data(symbol, date);
-	`)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create historical stock indexes: %v", err)
-	}
-
-	return db, nil
-}
-
 // saveEmailToDB saves an email to the SQLite database
 func (db *DB) saveEmailToDB(msg *gmail.Message) error {
 	// Extract headers
@@ -328,6 +225,12 @@ func (db *DB) saveEmailToDB(msg *gmail.Message) error {
 		return fmt.Errorf("failed to process payload: %v", err)
 	}
 
+	// Credentials pasted into an email (API keys, service-account JSON,
+	// ...) shouldn't end up sitting in plain_text/html; scan both bodies
+	// and store the redacted versions instead, recording what was found
+	// in email_secrets (see secretscan.go).
+	redactedPlainText, redactedHTML, secretFindings := scanAndRedactEmail(context.Background(), plainText, html)
+
 	// Save to database
 	stmt, err := db.Prepare(`
 		INSERT INTO emails (id, thread_id, subject, from_address, to_address, date, plain_text, html, label_ids)
@@ -345,14 +248,27 @@ func (db *DB) saveEmailToDB(msg *gmail.Message) error {
 		from,
 		to,
 		msg.InternalDate,
-		plainText,
-		html,
+		redactedPlainText,
+		redactedHTML,
 		strings.Join(msg.LabelIds, ","),
 	)
 	if err != nil {
 		return fmt.Errorf("failed to insert email: %v", err)
 	}
 
+	if err := recordEmailSecrets(db.DB, msg.Id, secretFindings); err != nil {
+		slog.Error("failed to record secret findings", "message_id", msg.Id, "error", err)
+	}
+
+	// A bounce is itself an email (a DSN or a VERP-addressed return), so
+	// detection runs on every message saved rather than needing a
+	// separate ingestion path.
+	if bounce, ok := detectBounce(msg); ok {
+		if err := db.recordBounce(bounce); err != nil {
+			slog.Error("failed to record bounce", "message_id", msg.Id, "error", err)
+		}
+	}
+
 	return nil
 }
 
@@ -363,16 +279,15 @@ type HandlerResponse struct {
 	Data    interface{} `json:"data,omitempty"`
 }
 
-// OAuth configuration
-var (
-	oauthStateString = "random-state-string" // In production, generate this randomly per session
-	config           *oauth2.Config
-)
-
 // SP500Stock represents a stock in the S&P 500 index
 type SP500Stock struct {
 	Symbol       string `json:"symbol"`
 	SecurityName string `json:"security_name"`
+	Sector       string `json:"sector,omitempty"`
+	SubIndustry  string `json:"sub_industry,omitempty"`
+	DateAdded    string `json:"date_added,omitempty"`
+	CIK          string `json:"cik,omitempty"`
+	Founded      string `json:"founded,omitempty"`
 }
 
 // StreamingLogWriter is a writer that streams logs to an HTTP response
@@ -408,59 +323,170 @@ func (s *StreamingLogWriter) Write(p []byte) (n int, err error) {
 	return n, nil
 }
 
-// portfolioBacktestHandler runs the portfolio backtest and streams the output
-func portfolioBacktestHandler(w http.ResponseWriter, r *http.Request) {
+// portfolioBacktestHandler kicks off an asynchronous backtest job over every
+// active ticker and returns its job ID immediately; stream its progress via
+// GET /api/jobs/{id}/events. It's a method on *App (see init.go) rather
+// than a bare function so it reaches the database and job manager through
+// a rather than the package-level db/jobs globals the rest of this file
+// still uses; see appMiddleware for how it's wired into chi.
+func (a *App) portfolioBacktestHandler(w http.ResponseWriter, r *http.Request) {
 	// Get list of active tickers
-	tickers, err := getActiveSP500Tickers(db)
+	tickers, err := getActiveSP500Tickers(a.db)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Failed to get tickers: %v", err), http.StatusInternalServerError)
 		return
 	}
 
-	// Process each ticker
-	for _, symbol := range tickers {
-		// Get historical data
-		data, err := fetchHistoricalData(symbol)
-		if err != nil {
-			log.Printf("Failed to fetch historical data for %s: %v", symbol, err)
-			continue
+	job := a.jobs.start(len(tickers), func(ctx context.Context, job *Job) {
+		for _, symbol := range tickers {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+
+			job.emit(JobEvent{Type: "started", Symbol: symbol})
+
+			// Get historical data
+			data, err := fetchHistoricalData(symbol)
+			if err != nil {
+				slog.Error("failed to fetch historical data", "symbol", symbol, "error", err, "error_class", errClass(err))
+				job.emit(JobEvent{Type: "failed", Symbol: symbol, Message: err.Error()})
+				continue
+			}
+
+			// Save to database
+			if err := saveHistoricalData(ctx, a.db, symbol, data); err != nil {
+				slog.Error("failed to save historical data", "symbol", symbol, "error", err)
+				job.emit(JobEvent{Type: "failed", Symbol: symbol, Message: err.Error()})
+				continue
+			}
+
+			job.emit(JobEvent{Type: "completed", Symbol: symbol})
 		}
+	})
 
-		// Save to database
-		if err := saveHistoricalData(db, symbol, data); err != nil {
-			log.Printf("Failed to save historical data for %s: %v", symbol, err)
-			continue
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(HandlerResponse{
+		Success: true,
+		Message: "portfolio backtest job started",
+		Data:    map[string]string{"job_id": job.ID},
+	})
+}
+
+// tablesHandler lists every user table in the database, for the admin
+// table-browsing UI.
+func (a *App) tablesHandler(w http.ResponseWriter, r *http.Request) {
+	rows, err := a.db.Query(`
+		SELECT name FROM sqlite_master
+		WHERE type='table' AND name NOT LIKE 'sqlite_%'
+		ORDER BY name
+	`)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get tables: %v", err), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var tableName string
+		if err := rows.Scan(&tableName); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to scan table name: %v", err), http.StatusInternalServerError)
+			return
 		}
+		tables = append(tables, tableName)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
-		"status": "completed",
-	})
+	json.NewEncoder(w).Encode(tables)
 }
 
-// Global database connection
-var db *sql.DB
+// tableRowsHandler returns a page of rows from one table, for the admin
+// table-browsing UI.
+func (a *App) tableRowsHandler(w http.ResponseWriter, r *http.Request) {
+	tableName := chi.URLParam(r, "table")
+	page := r.URL.Query().Get("page")
+	pageSize := r.URL.Query().Get("pageSize")
+
+	if page == "" {
+		page = "1"
+	}
+	if pageSize == "" {
+		pageSize = "100"
+	}
+
+	pageNum, _ := strconv.Atoi(page)
+	pageSizeNum, _ := strconv.Atoi(pageSize)
+	offset := (pageNum - 1) * pageSizeNum
+
+	// Validate table name exists
+	var exists bool
+	err := a.db.QueryRow(`
+		SELECT 1 FROM sqlite_master
+		WHERE type='table' AND name=? AND name NOT LIKE 'sqlite_%'
+	`, tableName).Scan(&exists)
+	if err != nil || !exists {
+		http.Error(w, "Table not found", http.StatusNotFound)
+		return
+	}
+
+	// Execute the query
+	rows, err := a.db.Query(fmt.Sprintf("SELECT * FROM %s LIMIT %d OFFSET %d", tableName, pageSizeNum, offset))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to query table: %v", err), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
 
-func init() {
-	// Initialize database
-	var err error
-	db, err = sql.Open("sqlite3", dbFile+"?_journal_mode=WAL")
+	// Get column names
+	columns, err := rows.Columns()
 	if err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to get columns: %v", err), http.StatusInternalServerError)
+		return
 	}
 
-	// Set connection pool settings
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(25)
-	db.SetConnMaxLifetime(5 * time.Minute)
+	// Prepare result
+	var result []map[string]interface{}
+	for rows.Next() {
+		// Create a slice of interface{} to hold the values
+		values := make([]interface{}, len(columns))
+		valuePtrs := make([]interface{}, len(columns))
+		for i := range columns {
+			valuePtrs[i] = &values[i]
+		}
+
+		// Scan the result into the values
+		if err := rows.Scan(valuePtrs...); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to scan row: %v", err), http.StatusInternalServerError)
+			return
+		}
 
-	// Create tables
-	if err := createTables(db); err != nil {
-		log.Fatalf("Failed to create tables: %v", err)
+		// Create a map for this row
+		row := make(map[string]interface{})
+		for i, col := range columns {
+			var v interface{}
+			val := values[i]
+			b, ok := val.([]byte)
+			if ok {
+				v = string(b)
+			} else {
+				v = val
+			}
+			row[col] = v
+		}
+		result = append(result, row)
 	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
 }
 
+// Global database connection, populated by init.go's package init() (via
+// initDB) so every file in this package can keep using the package-level
+// db/jobs globals while main() itself is built around the App struct.
+var db *sql.DB
+
 func createTables(db *sql.DB) error {
 	// Create the emails table with the new schema
 	_, err := db.Exec(`
@@ -505,6 +531,14 @@ func createTables(db *sql.DB) error {
 		return fmt.Errorf("failed to create stock_data table: %v", err)
 	}
 
+	// source was added after this table shipped, for the same reason (and
+	// with the same "duplicate column" ignore) as stock_historical_data's
+	// ALTER below.
+	if _, err := db.Exec(`ALTER TABLE stock_data ADD COLUMN source TEXT`); err != nil &&
+		!strings.Contains(err.Error(), "duplicate column") {
+		return fmt.Errorf("failed to add source column to stock_data: %v", err)
+	}
+
 	// Create the stock_historical_data table
 	_, err = db.Exec(`
 		CREATE TABLE IF NOT EXISTS stock_historical_data (
@@ -516,6 +550,7 @@ func createTables(db *sql.DB) error {
 			close REAL,
 			adj_close REAL,
 			volume INTEGER,
+			source TEXT,
 			PRIMARY KEY (symbol, date)
 		)
 	`)
@@ -523,6 +558,30 @@ func createTables(db *sql.DB) error {
 		return fmt.Errorf("failed to create stock_historical_data table: %v", err)
 	}
 
+	// source was added after this table shipped, so databases created
+	// before this change won't have it; SQLite has no ADD COLUMN IF NOT
+	// EXISTS, so just ignore the "duplicate column" error once it's there.
+	if _, err := db.Exec(`ALTER TABLE stock_historical_data ADD COLUMN source TEXT`); err != nil &&
+		!strings.Contains(err.Error(), "duplicate column") {
+		return fmt.Errorf("failed to add source column to stock_historical_data: %v", err)
+	}
+
+	if err := createBounceTables(db); err != nil {
+		return err
+	}
+
+	if err := createJobsTable(db); err != nil {
+		return err
+	}
+
+	if err := createSessionsTable(db); err != nil {
+		return err
+	}
+
+	if err := createEmailSecretsTable(db); err != nil {
+		return err
+	}
+
 	// Create indexes
 	_, err = db.Exec(`
 		CREATE INDEX IF NOT EXISTS idx_thread_id ON emails(thread_id);
@@ -541,128 +600,47 @@ func createTables(db *sql.DB) error {
 }
 
 func main() {
-	// Create router
-	r := chi.NewRouter()
-
-	// Middleware
-	r.Use(middleware.Logger)
-	r.Use(middleware.Recoverer)
-
-	// Routes
-	r.Get("/api/stock/{symbol}", stockHandler)
-	r.Get("/api/stock/historical/{symbol}", historicalDataHandler)
-	r.Get("/api/stock/historical/fill", fillHistoricalDataHandler)
-	r.Get("/api/portfolio/backtest", portfolioBacktestHandler)
-
-	// Database browsing routes
-	r.Get("/api/tables", func(w http.ResponseWriter, r *http.Request) {
-		// Query to get all table names
-		rows, err := db.Query(`
-			SELECT name FROM sqlite_master 
-			WHERE type='table' AND name NOT LIKE 'sqlite_%'
-			ORDER BY name
-		`)
-		if err != nil {
-			http.Error(w, fmt.Sprintf("Failed to get tables: %v", err), http.StatusInternalServerError)
-			return
-		}
-		defer rows.Close()
-
-		var tables []string
-		for rows.Next() {
-			var tableName string
-			if err := rows.Scan(&tableName); err != nil {
-				http.Error(w, fmt.Sprintf("Failed to scan table name: %v", err), http.StatusInternalServerError)
-				return
-			}
-			tables = append(tables, tableName)
-		}
-
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(tables)
-	})
+	// Structured JSON logging so fetch/handler errors carry fields
+	// (request_id, symbol, provider, http_status, ...) a log aggregator
+	// can filter/group on instead of grepping formatted strings.
+	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stdout, nil)))
 
-	r.Get("/api/tables/{table}", func(w http.ResponseWriter, r *http.Request) {
-		tableName := chi.URLParam(r, "table")
-		page := r.URL.Query().Get("page")
-		pageSize := r.URL.Query().Get("pageSize")
+	flag.Parse()
 
-		if page == "" {
-			page = "1"
-		}
-		if pageSize == "" {
-			pageSize = "100"
-		}
+	if *checkConfigFlag {
+		os.Exit(RunCheckConfig())
+	}
 
-		pageNum, _ := strconv.Atoi(page)
-		pageSizeNum, _ := strconv.Atoi(pageSize)
-		offset := (pageNum - 1) * pageSizeNum
-
-		// Validate table name exists
-		var exists bool
-		err := db.QueryRow(`
-			SELECT 1 FROM sqlite_master 
-			WHERE type='table' AND name=? AND name NOT LIKE 'sqlite_%'
-		`, tableName).Scan(&exists)
-		if err != nil || !exists {
-			http.Error(w, "Table not found", http.StatusNotFound)
-			return
-		}
+	InitConfig()
 
-		// Execute the query
-		rows, err := db.Query(fmt.Sprintf("SELECT * FROM %s LIMIT %d OFFSET %d", tableName, pageSizeNum, offset))
-		if err != nil {
-			http.Error(w, fmt.Sprintf("Failed to query table: %v", err), http.StatusInternalServerError)
-			return
-		}
-		defer rows.Close()
+	if ttl := Get().SP500CacheTTL; ttl > 0 {
+		sp500Cache.TTL = ttl
+	}
 
-		// Get column names
-		columns, err := rows.Columns()
-		if err != nil {
-			http.Error(w, fmt.Sprintf("Failed to get columns: %v", err), http.StatusInternalServerError)
-			return
+	watchCtx, cancelWatch := context.WithCancel(context.Background())
+	defer cancelWatch()
+	go func() {
+		if err := Watch(watchCtx); err != nil && err != context.Canceled {
+			slog.Error("config hot-reload stopped", "error", err)
 		}
+	}()
 
-		// Prepare result
-		var result []map[string]interface{}
-		for rows.Next() {
-			// Create a slice of interface{} to hold the values
-			values := make([]interface{}, len(columns))
-			valuePtrs := make([]interface{}, len(columns))
-			for i := range columns {
-				valuePtrs[i] = &values[i]
-			}
-
-			// Scan the result into the values
-			if err := rows.Scan(valuePtrs...); err != nil {
-				http.Error(w, fmt.Sprintf("Failed to scan row: %v", err), http.StatusInternalServerError)
-				return
-			}
-
-			// Create a map for this row
-			row := make(map[string]interface{})
-			for i, col := range columns {
-				var v interface{}
-				val := values[i]
-				b, ok := val.([]byte)
-				if ok {
-					v = string(b)
-				} else {
-					v = val
-				}
-				row[col] = v
-			}
-			result = append(result, row)
-		}
+	if pop3Cfg, ok := pop3ConfigFromEnv(); ok {
+		go runPOP3Poller(watchCtx, pop3Cfg)
+	}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(result)
-	})
+	app := newApp(defaultConstants())
+	r := initHTTP(app)
 
-	// Start the server
-	fmt.Printf("Server is running on port %s\n", serverPort)
-	log.Fatal(http.ListenAndServe(":"+serverPort, r))
+	// Start the server. Port comes from the live Config so an operator can
+	// rebind by editing the config file; serverPort is only the fallback
+	// default when no config set one.
+	port := Get().Port
+	if port == "" {
+		port = app.constants.ServerPort
+	}
+	fmt.Printf("Server is running on port %s\n", port)
+	log.Fatal(http.ListenAndServe(":"+port, r))
 }
 
 func handleHome(w http.ResponseWriter, r *http.Request) {
@@ -714,7 +692,7 @@ func handleHome(w http.ResponseWriter, r *http.Request) {
 			</div>
 			<div>
 				<h2>Historical Stock Data</h2>
-				<a href="/historical-data?table=sp500_list_2025_jun&start_date=2023-01-01&end_date=2023-12-31" class="button stock">Fetch Historical Data (Example)</a>
+				<a href="/historical-data?table=sp500_constituents&start_date=2023-01-01&end_date=2023-12-31" class="button stock">Fetch Historical Data (Example)</a>
 				<p><small>Fetches historical stock data for a given table, start date, and end date.</small></p>
 			</div>
 		</body>
@@ -723,69 +701,92 @@ func handleHome(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprint(w, html)
 }
 
-func handleGoogleLogin(w http.ResponseWriter, r *http.Request) {
-	url := config.AuthCodeURL(oauthStateString)
+// handleGoogleLogin issues (or reuses) a session cookie, generates a
+// random per-login CSRF state bound to that session (see startOAuthState),
+// and returns the Google consent URL built from it. The state used to be
+// the hard-coded oauthStateString; handleGoogleCallback now verifies it
+// against what's stored for the caller's own session instead.
+func (a *App) handleGoogleLogin(w http.ResponseWriter, r *http.Request) {
+	if a.oauth == nil {
+		sendJSONResponse(w, HandlerResponse{Success: false, Message: "Google OAuth is not configured"})
+		return
+	}
+
+	sessionID, err := ensureSession(a.db, w, r)
+	if err != nil {
+		sendJSONResponse(w, HandlerResponse{Success: false, Message: fmt.Sprintf("Failed to start session: %v", err)})
+		return
+	}
+
+	state, err := startOAuthState(a.db, sessionID)
+	if err != nil {
+		sendJSONResponse(w, HandlerResponse{Success: false, Message: fmt.Sprintf("Failed to start oauth state: %v", err)})
+		return
+	}
+
 	sendJSONResponse(w, HandlerResponse{
 		Success: true,
 		Message: "Authorization URL generated",
-		Data:    url,
+		Data:    a.oauth.AuthCodeURL(state),
 	})
 }
 
-func handleGoogleCallback(w http.ResponseWriter, r *http.Request) {
-	state := r.URL.Query().Get("state")
-	if state != oauthStateString {
-		sendJSONResponse(w, HandlerResponse{
-			Success: false,
-			Message: "Invalid OAuth state",
-			Data:    nil,
-		})
+// handleGoogleCallback verifies the state Google echoed back against the
+// one handleGoogleLogin issued for the caller's session, exchanges the
+// code for a token, and persists that token under the session ID (see
+// persistSessionToken) so RequireAuth can build a *gmail.Service bound to
+// this browser on later requests.
+func (a *App) handleGoogleCallback(w http.ResponseWriter, r *http.Request) {
+	if a.oauth == nil {
+		sendJSONResponse(w, HandlerResponse{Success: false, Message: "Google OAuth is not configured"})
+		return
+	}
+
+	sessionID, err := sessionIDFromRequest(r)
+	if err != nil {
+		sendJSONResponse(w, HandlerResponse{Success: false, Message: "No session cookie; start at /login"})
+		return
+	}
+
+	if err := verifyOAuthState(a.db, sessionID, r.URL.Query().Get("state")); err != nil {
+		sendJSONResponse(w, HandlerResponse{Success: false, Message: fmt.Sprintf("Invalid OAuth state: %v", err)})
 		return
 	}
 
 	code := r.URL.Query().Get("code")
-	token, err := config.Exchange(context.Background(), code)
+	token, err := a.oauth.Exchange(r.Context(), code)
 	if err != nil {
-		sendJSONResponse(w, HandlerResponse{
-			Success: false,
-			Message: fmt.Sprintf("Failed to exchange token: %v", err),
-			Data:    nil,
-		})
+		sendJSONResponse(w, HandlerResponse{Success: false, Message: fmt.Sprintf("Failed to exchange token: %v", err)})
 		return
 	}
 
-	client := config.Client(context.Background(), token)
-	srv, err := gmail.NewService(context.Background(), option.WithHTTPClient(client))
+	if err := persistSessionToken(a.db, sessionID, token); err != nil {
+		sendJSONResponse(w, HandlerResponse{Success: false, Message: fmt.Sprintf("Failed to persist token: %v", err)})
+		return
+	}
+
+	client := a.oauth.Client(r.Context(), token)
+	srv, err := gmail.NewService(r.Context(), option.WithHTTPClient(client))
 	if err != nil {
-		sendJSONResponse(w, HandlerResponse{
-			Success: false,
-			Message: fmt.Sprintf("Failed to create Gmail client: %v", err),
-			Data:    nil,
-		})
+		sendJSONResponse(w, HandlerResponse{Success: false, Message: fmt.Sprintf("Failed to create Gmail client: %v", err)})
 		return
 	}
 
-	// Get user profile
 	user, err := srv.Users.GetProfile("me").Do()
 	if err != nil {
-		sendJSONResponse(w, HandlerResponse{
-			Success: false,
-			Message: fmt.Sprintf("Failed to get user profile: %v", err),
-			Data:    nil,
-		})
+		sendJSONResponse(w, HandlerResponse{Success: false, Message: fmt.Sprintf("Failed to get user profile: %v", err)})
 		return
 	}
 
 	sendJSONResponse(w, HandlerResponse{
 		Success: true,
 		Message: "Successfully authenticated",
-		Data: map[string]interface{}{
-			"email": user.EmailAddress,
-			"token": token,
-		},
+		Data:    map[string]interface{}{"email": user.EmailAddress},
 	})
 }
 
+// batchGetHandler is wired behind RequireAuth (see initHTTP), so srv comes
+// from the caller's own session rather than a single shared credential.
 func batchGetHandler(w http.ResponseWriter, r *http.Request) {
 	labelName := r.URL.Query().Get("label")
 	if labelName == "" {
@@ -797,23 +798,11 @@ func batchGetHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get Gmail service
-	ctx := context.Background()
-	client, err := getGmailClient(ctx)
-	if err != nil {
-		sendJSONResponse(w, HandlerResponse{
-			Success: false,
-			Message: fmt.Sprintf("Failed to get Gmail client: %v", err),
-			Data:    nil,
-		})
-		return
-	}
-
-	srv, err := gmail.NewService(ctx, option.WithHTTPClient(client))
-	if err != nil {
+	srv := gmailServiceFromContext(r.Context())
+	if srv == nil {
 		sendJSONResponse(w, HandlerResponse{
 			Success: false,
-			Message: fmt.Sprintf("Failed to create Gmail service: %v", err),
+			Message: "No Gmail client on request context",
 			Data:    nil,
 		})
 		return
@@ -894,6 +883,12 @@ func fixDateHandler(w http.ResponseWriter, r *http.Request, db *DB) {
 	})
 }
 
+// sp500Handler fetches the current S&P 500 list and kicks off an
+// asynchronous job refreshing every constituent's historical data,
+// returning the job ID immediately instead of blocking until every stock
+// is fetched; stream its progress via GET /api/jobs/{id}/stream. Note:
+// like updateSP500Handler, this handler isn't currently wired to any
+// route.
 func sp500Handler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		sendJSONResponse(w, HandlerResponse{
@@ -904,7 +899,7 @@ func sp500Handler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	stocks, err := fetchSP500List()
+	stocks, err := fetchSP500List(r.URL.Query().Get("force") == "true")
 	if err != nil {
 		sendJSONResponse(w, HandlerResponse{
 			Success: false,
@@ -914,37 +909,40 @@ func sp500Handler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Update each stock's data
-	for _, stock := range stocks {
-		data, err := fetchHistoricalData(stock.Symbol)
-		if err != nil {
-			log.Printf("Failed to fetch historical data for %s: %v", stock.Symbol, err)
-			continue
-		}
+	job := jobs.start(len(stocks), func(ctx context.Context, job *Job) {
+		for _, stock := range stocks {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
 
-		if err := saveHistoricalData(db, stock.Symbol, data); err != nil {
-			log.Printf("Failed to save historical data for %s: %v", stock.Symbol, err)
-			continue
+			job.emit(JobEvent{Type: "started", Symbol: stock.Symbol})
+
+			data, err := fetchHistoricalData(stock.Symbol)
+			if err != nil {
+				slog.Error("failed to fetch historical data", "symbol", stock.Symbol, "error", err, "error_class", errClass(err))
+				job.emit(JobEvent{Type: "failed", Symbol: stock.Symbol, Message: err.Error()})
+				continue
+			}
+
+			if err := saveHistoricalData(ctx, db, stock.Symbol, data); err != nil {
+				slog.Error("failed to save historical data", "symbol", stock.Symbol, "error", err)
+				job.emit(JobEvent{Type: "failed", Symbol: stock.Symbol, Message: err.Error()})
+				continue
+			}
+
+			job.emit(JobEvent{Type: "completed", Symbol: stock.Symbol})
 		}
-	}
+	})
 
 	sendJSONResponse(w, HandlerResponse{
 		Success: true,
-		Message: fmt.Sprintf("Successfully updated %d S&P 500 stocks", len(stocks)),
-		Data:    stocks,
+		Message: "S&P 500 refresh job started",
+		Data:    map[string]string{"job_id": job.ID},
 	})
 }
 
-// Update getGmailClient to use the stored token
-func getGmailClient(ctx context.Context) (*http.Client, error) {
-	token, err := tokenFromFile(tokenFile)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get token: %v", err)
-	}
-
-	return config.Client(ctx, token), nil
-}
-
 func getLabelID(srv *gmail.Service, labelName string) (string, error) {
 	labels, err := srv.Users.Labels.List("me").Do()
 	if err != nil {
@@ -990,7 +988,7 @@ func processEmail(message *gmail.Message) (*gmail.Message, error) {
 
 // getActiveSP500Tickers fetches active ticker symbols from the database
 func (db *DB) getActiveSP500Tickers() ([]string, error) {
-	query := "SELECT ticker FROM sp500_list_2025_jun WHERE is_active = 1 ORDER BY ticker"
+	query := "SELECT ticker FROM sp500_constituents ORDER BY ticker"
 	rows, err := db.Query(query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query tickers: %v", err)
@@ -1015,99 +1013,92 @@ func (db *DB) getActiveSP500Tickers() ([]string, error) {
 
 // fetchStockData fetches stock data for a given ticker using a free API
 func fetchStockData(ticker string) (*StockData, error) {
-	// Using Yahoo Finance API (free alternative)
-	url := fmt.Sprintf("https://query1.finance.yahoo.com/v8/finance/chart/%s", ticker)
-
-	// Create a custom transport with TLS config
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: true, // Only for development/testing
-		},
-	}
+	return fetchStockDataFrom(quoteChain(), ticker)
+}
 
-	// Create a new client with the custom transport
-	client := &http.Client{
-		Timeout:   10 * time.Second,
-		Transport: tr,
-	}
+// quoteChain builds the live-quote provider fallback chain from
+// Config.MarketDataProviders — the same provider list and per-provider API
+// keys historicalDataChain resolves for historical bars, since a symbol's
+// quote and its bars come from the same upstream account. An empty or
+// all-unknown provider list falls back to a bare Yahoo provider, which
+// needs no key.
+func quoteChain() marketdata.QuoteProvider {
+	cfg := Get()
 
-	// Create request with User-Agent header (Yahoo Finance requires this)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request for %s: %v", ticker, err)
+	names := cfg.MarketDataProviders
+	if len(names) == 0 {
+		names = []string{"yahoo"}
 	}
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
 
-	resp, err := client.Do(req)
+	chain, err := buildQuoteProviderChain(cfg, names)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch data for %s: %v", ticker, err)
+		return marketdata.NewYahooProvider()
 	}
-	defer resp.Body.Close()
+	return chain
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API request failed for %s: status %d", ticker, resp.StatusCode)
+// buildQuoteProviderChain resolves each of names into a
+// marketdata.QuoteProvider via marketdata.NewQuote, the quote-side mirror
+// of buildProviderChain.
+func buildQuoteProviderChain(cfg *Config, names []string) (marketdata.QuoteProvider, error) {
+	var providers []marketdata.QuoteProvider
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		p, err := marketdata.NewQuote(name, marketDataAPIKey(cfg, name))
+		if err != nil {
+			slog.Warn("buildQuoteProviderChain: skipping provider", "provider", name, "error", err)
+			continue
+		}
+		providers = append(providers, p)
 	}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response for %s: %v", ticker, err)
+	if len(providers) == 0 {
+		return nil, fmt.Errorf("no valid quote providers in %v", names)
 	}
-
-	// Parse Yahoo Finance response
-	var yahooResp struct {
-		Chart struct {
-			Result []struct {
-				Meta struct {
-					Currency             string  `json:"currency"`
-					Symbol               string  `json:"symbol"`
-					RegularMarketPrice   float64 `json:"regularMarketPrice"`
-					PreviousClose        float64 `json:"previousClose"`
-					RegularMarketOpen    float64 `json:"regularMarketOpen"`
-					RegularMarketDayHigh float64 `json:"regularMarketDayHigh"`
-					RegularMarketDayLow  float64 `json:"regularMarketDayLow"`
-					RegularMarketVolume  int64   `json:"regularMarketVolume"`
-					MarketCap            int64   `json:"marketCap"`
-					FiftyTwoWeekHigh     float64 `json:"fiftyTwoWeekHigh"`
-					FiftyTwoWeekLow      float64 `json:"fiftyTwoWeekLow"`
-					LongName             string  `json:"longName"`
-				} `json:"meta"`
-			} `json:"result"`
-		} `json:"chart"`
+	if len(providers) == 1 {
+		return providers[0], nil
 	}
+	return marketdata.ChainedQuoteProvider{Providers: providers}, nil
+}
 
-	if err := json.Unmarshal(body, &yahooResp); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON for %s: %v", ticker, err)
+// fetchStockDataFrom fetches ticker's current quote from provider
+// specifically, bypassing the default fallback chain (mirrors
+// fetchHistoricalDataFrom).
+func fetchStockDataFrom(provider marketdata.QuoteProvider, ticker string) (*StockData, error) {
+	q, err := provider.FetchQuote(ticker)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch quote for %s: %v", ticker, err)
 	}
 
-	if len(yahooResp.Chart.Result) == 0 {
-		return nil, fmt.Errorf("no data returned for %s", ticker)
+	change := q.Price - q.PreviousClose
+	var changePercent float64
+	if q.PreviousClose != 0 {
+		changePercent = (change / q.PreviousClose) * 100
 	}
 
-	meta := yahooResp.Chart.Result[0].Meta
-	currentPrice := meta.RegularMarketPrice
-	previousClose := meta.PreviousClose
-	change := currentPrice - previousClose
-	changePercent := (change / previousClose) * 100
-
 	return &StockData{
-		Symbol:           ticker,
-		CompanyName:      meta.LongName,
-		Price:            currentPrice,
+		Symbol:           q.Symbol,
+		CompanyName:      q.CompanyName,
+		Price:            q.Price,
 		ChangeAmount:     change,
 		ChangePercent:    changePercent,
-		Volume:           meta.RegularMarketVolume,
-		MarketCap:        meta.MarketCap,
-		PreviousClose:    previousClose,
-		OpenPrice:        meta.RegularMarketOpen,
-		High:             meta.RegularMarketDayHigh,
-		Low:              meta.RegularMarketDayLow,
-		FiftyTwoWeekHigh: meta.FiftyTwoWeekHigh,
-		FiftyTwoWeekLow:  meta.FiftyTwoWeekLow,
-		LastUpdated:      int64(meta.RegularMarketPrice),
-		Date:             time.Now(),
-		Open:             meta.RegularMarketOpen,
-		Close:            currentPrice,
-		AdjClose:         currentPrice,
+		Volume:           q.Volume,
+		MarketCap:        q.MarketCap,
+		PreviousClose:    q.PreviousClose,
+		OpenPrice:        q.Open,
+		High:             q.High,
+		Low:              q.Low,
+		FiftyTwoWeekHigh: q.FiftyTwoWeekHigh,
+		FiftyTwoWeekLow:  q.FiftyTwoWeekLow,
+		LastUpdated:      q.UpdatedAt.Unix(),
+		Date:             q.UpdatedAt,
+		Open:             q.Open,
+		Close:            q.Price,
+		AdjClose:         q.Price,
+		Source:           q.Source,
 	}, nil
 }
 
@@ -1117,8 +1108,8 @@ func (db *DB) saveStockData(stock *StockData) error {
 		INSERT OR REPLACE INTO stock_data (
 			symbol, company_name, price, change_amount, change_percent,
 			volume, market_cap, previous_close, open_price, high, low,
-			fifty_two_week_high, fifty_two_week_low, last_updated
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			fifty_two_week_high, fifty_two_week_low, last_updated, source
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
 		return fmt.Errorf("failed to prepare statement: %v", err)
@@ -1140,6 +1131,7 @@ func (db *DB) saveStockData(stock *StockData) error {
 		stock.FiftyTwoWeekHigh,
 		stock.FiftyTwoWeekLow,
 		stock.LastUpdated,
+		stock.Source,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to insert stock data: %v", err)
@@ -1161,13 +1153,13 @@ func fetchAllSP500Data() error {
 		// Get historical data
 		data, err := fetchHistoricalData(symbol)
 		if err != nil {
-			log.Printf("Failed to fetch historical data for %s: %v", symbol, err)
+			slog.Error("failed to fetch historical data", "symbol", symbol, "error", err, "error_class", errClass(err))
 			continue
 		}
 
 		// Save to database
-		if err := saveHistoricalData(db, symbol, data); err != nil {
-			log.Printf("Failed to save historical data for %s: %v", symbol, err)
+		if err := saveHistoricalData(context.Background(), db, symbol, data); err != nil {
+			slog.Error("failed to save historical data", "symbol", symbol, "error", err)
 			continue
 		}
 	}
@@ -1222,165 +1214,29 @@ func historicalDataHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(data)
 }
 
-// fetchHistoricalTickerData fetches historical data for a single ticker from Yahoo Finance
+// fetchHistoricalTickerData fetches historical data for a single ticker,
+// trying each provider in historicalDataChain (see pkg/marketdata) in
+// order until one returns data.
 func fetchHistoricalTickerData(ticker string, startDate, endDate time.Time) ([]HistoricalData, error) {
-	// Yahoo Finance API URL
-	url := fmt.Sprintf(
-		"https://query1.finance.yahoo.com/v8/finance/chart/%s?period1=%d&period2=%d&interval=1d&includeAdjustedClose=true",
-		ticker,
-		startDate.Unix(),
-		endDate.Unix(),
-	)
-
-	// Create a custom transport with TLS config
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: true, // Only for development/testing
-		},
-	}
-
-	// Create a new client with the custom transport
-	client := &http.Client{
-		Timeout:   20 * time.Second,
-		Transport: tr,
-	}
-
-	// Create a new request
-	req, err := http.NewRequest("GET", url, nil)
+	bars, err := historicalDataChain().FetchOHLCV(ticker, startDate, endDate, "")
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %v", err)
-	}
-
-	// Add required headers
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko)")
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
-	req.Header.Set("Accept-Encoding", "gzip, deflate, br")
-	req.Header.Set("Origin", "https://finance.yahoo.com")
-	req.Header.Set("Referer", fmt.Sprintf("https://finance.yahoo.com/quote/%s", ticker))
-
-	// Make the request with retries
-	var resp *http.Response
-	maxRetries := 3
-	for i := 0; i < maxRetries; i++ {
-		resp, err = client.Do(req)
-		if err != nil {
-			if i == maxRetries-1 {
-				return nil, fmt.Errorf("failed to fetch data after %d retries: %v", maxRetries, err)
-			}
-			time.Sleep(time.Duration(i+1) * time.Second)
-			continue
-		}
-
-		if resp.StatusCode == 429 { // Too Many Requests
-			if i == maxRetries-1 {
-				return nil, fmt.Errorf("rate limit exceeded after %d retries", maxRetries)
-			}
-			time.Sleep(time.Duration(i+1) * 2 * time.Second)
-			resp.Body.Close()
-			continue
-		}
-
-		if resp.StatusCode != http.StatusOK {
-			resp.Body.Close()
-			return nil, fmt.Errorf("API request failed with status %d", resp.StatusCode)
-		}
-		break
-	}
-	defer resp.Body.Close()
-
-	// Handle gzip compression
-	var reader io.Reader
-	switch resp.Header.Get("Content-Encoding") {
-	case "gzip":
-		gzReader, err := gzip.NewReader(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create gzip reader: %v", err)
-		}
-		defer gzReader.Close()
-		reader = gzReader
-	default:
-		reader = resp.Body
+		return nil, err
 	}
 
-	// Read response body
-	body, err := io.ReadAll(reader)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %v", err)
-	}
-
-	// Parse JSON response
-	var yahooResp struct {
-		Chart struct {
-			Result []struct {
-				Meta struct {
-					Symbol string  `json:"symbol"`
-					First  float64 `json:"firstTradeDate"`
-				} `json:"meta"`
-				Timestamp  []int64 `json:"timestamp"`
-				Indicators struct {
-					Quote []struct {
-						Open   []float64 `json:"open"`
-						High   []float64 `json:"high"`
-						Low    []float64 `json:"low"`
-						Close  []float64 `json:"close"`
-						Volume []int64   `json:"volume"`
-					} `json:"quote"`
-					Adjclose []struct {
-						Adjclose []float64 `json:"adjclose"`
-					} `json:"adjclose"`
-				} `json:"indicators"`
-			} `json:"result"`
-			Error *struct {
-				Code        string `json:"code"`
-				Description string `json:"description"`
-			} `json:"error"`
-		} `json:"chart"`
-	}
-
-	if err := json.Unmarshal(body, &yahooResp); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON: %v, body: %s", err, string(body))
-	}
-
-	// Check for API errors
-	if yahooResp.Chart.Error != nil {
-		return nil, fmt.Errorf("API error: %s - %s", yahooResp.Chart.Error.Code, yahooResp.Chart.Error.Description)
-	}
-
-	if len(yahooResp.Chart.Result) == 0 {
-		return nil, fmt.Errorf("no data returned")
-	}
-
-	result := yahooResp.Chart.Result[0]
-	if len(result.Indicators.Quote) == 0 {
-		return nil, fmt.Errorf("no quote data returned")
-	}
-
-	quote := result.Indicators.Quote[0]
-	adjclose := result.Indicators.Adjclose[0]
-
-	var historicalData []HistoricalData
-	for i, ts := range result.Timestamp {
-		if i >= len(quote.Open) || i >= len(quote.High) || i >= len(quote.Low) ||
-			i >= len(quote.Close) || i >= len(quote.Volume) || i >= len(adjclose.Adjclose) {
-			continue
+	historicalData := make([]HistoricalData, len(bars))
+	for i, b := range bars {
+		historicalData[i] = HistoricalData{
+			Symbol:   b.Symbol,
+			Date:     b.Date,
+			Open:     b.Open,
+			High:     b.High,
+			Low:      b.Low,
+			Close:    b.Close,
+			AdjClose: b.AdjClose,
+			Volume:   b.Volume,
 		}
-
-		historicalData = append(historicalData, HistoricalData{
-			Symbol:   ticker,
-			Date:     time.Unix(ts, 0),
-			Open:     quote.Open[i],
-			High:     quote.High[i],
-			Low:      quote.Low[i],
-			Close:    quote.Close[i],
-			AdjClose: adjclose.Adjclose[i],
-			Volume:   quote.Volume[i],
-		})
 	}
 
-	// Add delay to avoid rate limiting
-	time.Sleep(100 * time.Millisecond)
-
 	return historicalData, nil
 }
 
@@ -1433,7 +1289,44 @@ func (db *DB) saveHistoricalData(data []HistoricalData) error {
 	return nil
 }
 
-// updateSP500Handler fetches the current S&P 500 list and updates the database
+// ensureSP500Tables creates sp500_constituents (the current membership
+// list, keyed by ticker, stamped with the as_of date of its last refresh)
+// and sp500_changes (an append-only log of every addition/removal
+// updateSP500Handler has ever recorded) if they don't already exist.
+func ensureSP500Tables(db *DB) error {
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS sp500_constituents (
+			ticker TEXT PRIMARY KEY,
+			security_name TEXT,
+			sector TEXT,
+			sub_industry TEXT,
+			date_added TEXT,
+			cik TEXT,
+			founded TEXT,
+			as_of TEXT
+		)
+	`); err != nil {
+		return fmt.Errorf("failed to create sp500_constituents: %v", err)
+	}
+
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS sp500_changes (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			ticker TEXT NOT NULL,
+			change_type TEXT NOT NULL,
+			as_of TEXT NOT NULL,
+			recorded_at INTEGER NOT NULL
+		)
+	`); err != nil {
+		return fmt.Errorf("failed to create sp500_changes: %v", err)
+	}
+
+	return nil
+}
+
+// updateSP500Handler fetches the current S&P 500 list and replaces
+// sp500_constituents with it, recording any tickers added or removed
+// since the last refresh into sp500_changes.
 func updateSP500Handler(w http.ResponseWriter, r *http.Request, db *DB) {
 	if r.Method != http.MethodGet {
 		sendJSONResponse(w, HandlerResponse{
@@ -1443,23 +1336,17 @@ func updateSP500Handler(w http.ResponseWriter, r *http.Request, db *DB) {
 		return
 	}
 
-	// Create the table if it doesn't exist
-	_, err := db.Exec(`
-		CREATE TABLE IF NOT EXISTS sp500_list_2025_jun (
-			ticker TEXT PRIMARY KEY,
-			security_name TEXT
-		)
-	`)
-	if err != nil {
+	if err := ensureSP500Tables(db); err != nil {
 		sendJSONResponse(w, HandlerResponse{
 			Success: false,
-			Message: fmt.Sprintf("Failed to create table: %v", err),
+			Message: err.Error(),
 		})
 		return
 	}
 
-	// Fetch S&P 500 constituents from local file
-	stocks, err := fetchSP500List()
+	// Fetch the current S&P 500 constituents (live Wikipedia, falling back
+	// to a local or bundled snapshot; see sp500Cache).
+	stocks, err := fetchSP500List(r.URL.Query().Get("force") == "true")
 	if err != nil {
 		sendJSONResponse(w, HandlerResponse{
 			Success: false,
@@ -1468,6 +1355,37 @@ func updateSP500Handler(w http.ResponseWriter, r *http.Request, db *DB) {
 		return
 	}
 
+	existing, err := db.Query("SELECT ticker FROM sp500_constituents")
+	if err != nil {
+		sendJSONResponse(w, HandlerResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to query existing tickers: %v", err),
+		})
+		return
+	}
+	previousTickers := make(map[string]struct{})
+	for existing.Next() {
+		var ticker string
+		if err := existing.Scan(&ticker); err != nil {
+			existing.Close()
+			sendJSONResponse(w, HandlerResponse{
+				Success: false,
+				Message: fmt.Sprintf("Failed to scan existing ticker: %v", err),
+			})
+			return
+		}
+		previousTickers[ticker] = struct{}{}
+	}
+	existing.Close()
+
+	currentTickers := make(map[string]struct{}, len(stocks))
+	for _, stock := range stocks {
+		currentTickers[stock.Symbol] = struct{}{}
+	}
+
+	asOf := time.Now().Format("2006-01-02")
+	now := time.Now().Unix()
+
 	// Begin transaction
 	tx, err := db.Begin()
 	if err != nil {
@@ -1480,7 +1398,7 @@ func updateSP500Handler(w http.ResponseWriter, r *http.Request, db *DB) {
 	defer tx.Rollback()
 
 	// Clear existing data
-	_, err = tx.Exec("DELETE FROM sp500_list_2025_jun")
+	_, err = tx.Exec("DELETE FROM sp500_constituents")
 	if err != nil {
 		sendJSONResponse(w, HandlerResponse{
 			Success: false,
@@ -1491,8 +1409,8 @@ func updateSP500Handler(w http.ResponseWriter, r *http.Request, db *DB) {
 
 	// Insert new stocks
 	stmt, err := tx.Prepare(`
-		INSERT INTO sp500_list_2025_jun (ticker, security_name)
-		VALUES (?, ?)
+		INSERT INTO sp500_constituents (ticker, security_name, sector, sub_industry, date_added, cik, founded, as_of)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
 		sendJSONResponse(w, HandlerResponse{
@@ -1504,7 +1422,7 @@ func updateSP500Handler(w http.ResponseWriter, r *http.Request, db *DB) {
 	defer stmt.Close()
 
 	for _, stock := range stocks {
-		_, err = stmt.Exec(stock.Symbol, stock.SecurityName)
+		_, err = stmt.Exec(stock.Symbol, stock.SecurityName, stock.Sector, stock.SubIndustry, stock.DateAdded, stock.CIK, stock.Founded, asOf)
 		if err != nil {
 			sendJSONResponse(w, HandlerResponse{
 				Success: false,
@@ -1514,6 +1432,42 @@ func updateSP500Handler(w http.ResponseWriter, r *http.Request, db *DB) {
 		}
 	}
 
+	changeStmt, err := tx.Prepare(`
+		INSERT INTO sp500_changes (ticker, change_type, as_of, recorded_at)
+		VALUES (?, ?, ?, ?)
+	`)
+	if err != nil {
+		sendJSONResponse(w, HandlerResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to prepare change log statement: %v", err),
+		})
+		return
+	}
+	defer changeStmt.Close()
+
+	for ticker := range currentTickers {
+		if _, ok := previousTickers[ticker]; !ok {
+			if _, err := changeStmt.Exec(ticker, "added", asOf, now); err != nil {
+				sendJSONResponse(w, HandlerResponse{
+					Success: false,
+					Message: fmt.Sprintf("Failed to record addition of %s: %v", ticker, err),
+				})
+				return
+			}
+		}
+	}
+	for ticker := range previousTickers {
+		if _, ok := currentTickers[ticker]; !ok {
+			if _, err := changeStmt.Exec(ticker, "removed", asOf, now); err != nil {
+				sendJSONResponse(w, HandlerResponse{
+					Success: false,
+					Message: fmt.Sprintf("Failed to record removal of %s: %v", ticker, err),
+				})
+				return
+			}
+		}
+	}
+
 	// Commit transaction
 	if err = tx.Commit(); err != nil {
 		sendJSONResponse(w, HandlerResponse{
@@ -1569,7 +1523,62 @@ func stockHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(stock)
 }
 
-// fillHistoricalDataHandler fills historical data for all stocks
+// fillConcurrency, fillRPS, and fillBurst bound fillHistoricalDataHandler's
+// pkg/fetcher pool: at most fillConcurrency symbols in flight at once,
+// sharing a token bucket that allows fillRPS requests/sec with bursts up
+// to fillBurst. Config.FetchConcurrency/FetchRPS override the first two.
+// fillConcurrency defaults to fetcher.RecommendedWorkers (derived from the
+// process's open-file ceiling) rather than a fixed number, so it never
+// exhausts FDs alongside the DB pool and this server's own connections.
+var fillConcurrency = fetcher.RecommendedWorkers()
+
+const (
+	fillRPS   = 4.0
+	fillBurst = 4
+)
+
+// fillJobTimeout bounds a single symbol's fetch within a fill job (see
+// fetcher.Fetcher.JobTimeout), so one slow or hanging upstream call can't
+// occupy a worker for the rest of the run.
+const fillJobTimeout = 60 * time.Second
+
+// activeFetcher holds the *fetcher.Fetcher for the most recently started
+// fill job, if any, so fetcherMetricsHandler can report its live state.
+// It's replaced wholesale each time fillHistoricalDataHandler starts a job
+// rather than tracked per-job, since only one fill's throughput matters at
+// a time in practice.
+var activeFetcher struct {
+	mu sync.Mutex
+	f  *fetcher.Fetcher
+}
+
+// fetcherMetricsHandler reports the most recent fill job's rate limiter
+// state and in-flight fetch count, or zero values if no fill has run yet.
+func fetcherMetricsHandler(w http.ResponseWriter, r *http.Request) {
+	activeFetcher.mu.Lock()
+	f := activeFetcher.f
+	activeFetcher.mu.Unlock()
+
+	var stats fetcher.Stats
+	if f != nil {
+		stats = f.Stats()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(HandlerResponse{Success: true, Data: stats})
+}
+
+// fillHistoricalDataHandler kicks off an asynchronous fill job over every
+// active ticker and returns its job ID immediately; poll its status via GET
+// /api/jobs/{id} or stream progress via GET /api/jobs/{id}/events, and stop
+// it early with POST /api/jobs/{id}/cancel or DELETE /api/jobs/{id}. A
+// "provider" query param (see pkg/marketdata), e.g.
+// "?provider=yahoo,alphavantage", forces that fallback chain for the whole
+// job instead of the configured one. A "timeout" query param, e.g.
+// "?timeout=30s", bounds the whole job's context instead of letting it run
+// until every ticker is attempted. A "mode" query param selects how much of
+// each ticker's range actually gets fetched (see buildFillPlan); it
+// defaults to "incremental".
 func fillHistoricalDataHandler(w http.ResponseWriter, r *http.Request) {
 	// Get list of active tickers
 	tickers, err := getActiveSP500Tickers(db)
@@ -1578,56 +1587,260 @@ func fillHistoricalDataHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Process each ticker
-	for _, symbol := range tickers {
-		// Get historical data
-		data, err := fetchHistoricalData(symbol)
+	provider := historicalDataChain()
+	if forced := r.URL.Query().Get("provider"); forced != "" {
+		provider, err = buildProviderChain(Get(), strings.Split(forced, ","))
 		if err != nil {
-			log.Printf("Failed to fetch historical data for %s: %v", symbol, err)
-			continue
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
 		}
+	}
 
-		// Save to database
-		if err := saveHistoricalData(db, symbol, data); err != nil {
-			log.Printf("Failed to save historical data for %s: %v", symbol, err)
-			continue
+	end := time.Now()
+	start := end.AddDate(-2, 0, 0)
+
+	mode := r.URL.Query().Get("mode")
+	if mode == "" {
+		mode = "incremental"
+	}
+	if mode != "incremental" && mode != "repair" && mode != "full" {
+		http.Error(w, fmt.Sprintf("invalid mode %q (want incremental, repair, or full)", mode), http.StatusBadRequest)
+		return
+	}
+
+	plans := make(map[string]fillPlan, len(tickers))
+	for _, symbol := range tickers {
+		plans[symbol] = buildFillPlan(db, mode, symbol, start, end)
+	}
+
+	var fetchRanges []fetcher.SymbolRange
+	pending := make(map[string]int, len(tickers))
+	for symbol, plan := range plans {
+		pending[symbol] = len(plan.ranges)
+		for _, rg := range plan.ranges {
+			fetchRanges = append(fetchRanges, fetcher.SymbolRange{Symbol: symbol, Start: rg.start, End: rg.end})
 		}
 	}
 
+	cfg := Get()
+	concurrency, rps := fillConcurrency, fillRPS
+	if cfg.FetchConcurrency > 0 {
+		concurrency = cfg.FetchConcurrency
+	}
+	if cfg.FetchRPS > 0 {
+		rps = cfg.FetchRPS
+	}
+
+	var timeout time.Duration
+	if raw := r.URL.Query().Get("timeout"); raw != "" {
+		timeout, err = time.ParseDuration(raw)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid timeout %q: %v", raw, err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	job := jobs.start(len(tickers), func(ctx context.Context, job *Job) {
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		// A symbol whose plan has no ranges is already fully covered (or,
+		// in repair mode, has no gaps) and never goes through the
+		// fetcher, so it's reported done right away.
+		for symbol, plan := range plans {
+			if len(plan.ranges) == 0 {
+				job.emit(JobEvent{Type: "completed", Symbol: symbol, BytesSaved: plan.bytesSaved, Message: "already up to date"})
+			}
+		}
+
+		f := fetcher.New(concurrency, rps, fillBurst)
+		f.JobTimeout = fillJobTimeout
+
+		activeFetcher.mu.Lock()
+		activeFetcher.f = f
+		activeFetcher.mu.Unlock()
+
+		rows := make(map[string]int)
+		failed := make(map[string]string)
+
+		for result := range f.FillRanges(ctx, provider, fetchRanges) {
+			symbol := result.Symbol
+
+			if result.Err != nil {
+				slog.Error("failed to fetch historical data", "symbol", symbol, "error", result.Err, "error_class", errClass(result.Err))
+				failed[symbol] = result.Err.Error()
+			} else {
+				data := make([]StockData, len(result.Bars))
+				for i, b := range result.Bars {
+					data[i] = StockData{
+						Symbol:   b.Symbol,
+						Date:     b.Date,
+						Open:     b.Open,
+						High:     b.High,
+						Low:      b.Low,
+						Close:    b.Close,
+						AdjClose: b.AdjClose,
+						Volume:   b.Volume,
+						Source:   b.Source,
+					}
+				}
+
+				if err := saveHistoricalData(ctx, db, symbol, data); err != nil {
+					slog.Error("failed to save historical data", "symbol", symbol, "error", err)
+					failed[symbol] = err.Error()
+				} else {
+					rows[symbol] += len(data)
+				}
+			}
+
+			pending[symbol]--
+			if pending[symbol] > 0 {
+				continue
+			}
+			if msg, ok := failed[symbol]; ok {
+				job.emit(JobEvent{Type: "failed", Symbol: symbol, Message: msg})
+			} else {
+				job.emit(JobEvent{Type: "completed", Symbol: symbol, Rows: rows[symbol], BytesSaved: plans[symbol].bytesSaved})
+			}
+		}
+
+		if ctx.Err() == context.DeadlineExceeded {
+			job.Fail(ctx.Err())
+		}
+	})
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
-		"status": "completed",
+	json.NewEncoder(w).Encode(HandlerResponse{
+		Success: true,
+		Message: "fill job started",
+		Data:    map[string]string{"job_id": job.ID},
 	})
 }
 
-// fetchHistoricalData fetches historical data for a given symbol
+// fetchHistoricalData fetches the last 2 years of daily historical data for
+// symbol from historicalDataChain, the configured market-data provider
+// fallback chain (see pkg/marketdata).
 func fetchHistoricalData(symbol string) ([]StockData, error) {
-	// Implement the actual data fetching logic here
-	// For now, return empty data
-	return []StockData{}, nil
+	return fetchHistoricalDataFrom(historicalDataChain(), symbol)
+}
+
+// historicalDataChain builds the market-data provider fallback chain from
+// Config.MarketDataProviders, resolving each provider's API key via
+// marketDataAPIKey. An empty or all-unknown provider list falls back to a
+// bare Yahoo provider, which needs no key.
+func historicalDataChain() marketdata.Provider {
+	cfg := Get()
+
+	names := cfg.MarketDataProviders
+	if len(names) == 0 {
+		names = []string{"yahoo"}
+	}
+
+	chain, err := buildProviderChain(cfg, names)
+	if err != nil {
+		return marketdata.NewYahooProvider()
+	}
+	return chain
+}
+
+// buildProviderChain resolves each of names (trimming whitespace, skipping
+// any that fail to construct) into a marketdata.Provider via marketdata.New,
+// wiring up its API key via marketDataAPIKey, and wraps them in a
+// ChainedProvider. It errors only if every name fails to resolve.
+func buildProviderChain(cfg *Config, names []string) (marketdata.Provider, error) {
+	var providers []marketdata.Provider
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		p, err := marketdata.New(name, marketDataAPIKey(cfg, name))
+		if err != nil {
+			slog.Warn("buildProviderChain: skipping provider", "provider", name, "error", err)
+			continue
+		}
+		providers = append(providers, p)
+	}
+
+	if len(providers) == 0 {
+		return nil, fmt.Errorf("no valid providers in %v", names)
+	}
+	if len(providers) == 1 {
+		return providers[0], nil
+	}
+	return marketdata.ChainedProvider{Providers: providers}, nil
+}
+
+// marketDataAPIKey resolves provider's API key from cfg's credentials,
+// looking it up under "<provider>_api_key". It returns "" if cfg is nil or
+// no such credential is configured, since providers that don't need a key
+// (e.g. Yahoo) are expected to ignore an empty key.
+func marketDataAPIKey(cfg *Config, provider string) string {
+	if cfg == nil {
+		return ""
+	}
+	cred, err := cfg.GetCredential(provider + "_api_key")
+	if err != nil {
+		return ""
+	}
+	return string(cred.Data)
+}
+
+// fetchHistoricalDataFrom fetches symbol's last 2 years of daily bars from
+// provider specifically, bypassing the default fallback chain — used by
+// fillHistoricalDataHandler's "provider" query param to force a single
+// source.
+func fetchHistoricalDataFrom(provider marketdata.Provider, symbol string) ([]StockData, error) {
+	end := time.Now()
+	start := end.AddDate(-2, 0, 0)
+
+	bars, err := provider.FetchOHLCV(symbol, start, end, "")
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]StockData, len(bars))
+	for i, b := range bars {
+		data[i] = StockData{
+			Symbol:   b.Symbol,
+			Date:     b.Date,
+			Open:     b.Open,
+			High:     b.High,
+			Low:      b.Low,
+			Close:    b.Close,
+			AdjClose: b.AdjClose,
+			Volume:   b.Volume,
+			Source:   b.Source,
+		}
+	}
+	return data, nil
 }
 
 // saveHistoricalData saves historical stock data to the database
-func saveHistoricalData(db *sql.DB, symbol string, data []StockData) error {
+func saveHistoricalData(ctx context.Context, db *sql.DB, symbol string, data []StockData) error {
 	// Begin transaction
-	tx, err := db.Begin()
+	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %v", err)
 	}
 	defer tx.Rollback()
 
 	// Prepare statement
-	stmt, err := tx.Prepare(`
+	stmt, err := tx.PrepareContext(ctx, `
 		INSERT INTO stock_historical_data (
-			symbol, date, open, high, low, close, adj_close, volume
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			symbol, date, open, high, low, close, adj_close, volume, source
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(symbol, date) DO UPDATE SET
 			open = excluded.open,
 			high = excluded.high,
 			low = excluded.low,
 			close = excluded.close,
 			adj_close = excluded.adj_close,
-			volume = excluded.volume
+			volume = excluded.volume,
+			source = excluded.source
 	`)
 	if err != nil {
 		return fmt.Errorf("failed to prepare statement: %v", err)
@@ -1636,7 +1849,7 @@ func saveHistoricalData(db *sql.DB, symbol string, data []StockData) error {
 
 	// Insert data
 	for _, d := range data {
-		_, err = stmt.Exec(
+		_, err = stmt.ExecContext(ctx,
 			symbol,
 			d.Date.Unix(),
 			d.Open,
@@ -1645,6 +1858,7 @@ func saveHistoricalData(db *sql.DB, symbol string, data []StockData) error {
 			d.Close,
 			d.AdjClose,
 			d.Volume,
+			d.Source,
 		)
 		if err != nil {
 			return fmt.Errorf("failed to insert historical data: %v", err)
@@ -1659,6 +1873,159 @@ func saveHistoricalData(db *sql.DB, symbol string, data []StockData) error {
 	return nil
 }
 
+// dateRange is a [start, end] window to fetch, inclusive of both ends.
+type dateRange struct {
+	start, end time.Time
+}
+
+// fillPlan is what fillHistoricalDataHandler actually needs to fetch for one
+// symbol, plus the bytesSaved estimate for whatever it's skipping.
+type fillPlan struct {
+	ranges     []dateRange
+	bytesSaved int64
+}
+
+// estimatedBytesPerBar is a rough estimate of one daily OHLCV bar's wire
+// size, used only to report how much transfer an incremental or repair fill
+// avoided by not re-fetching an already-covered date range. It isn't
+// measured from any real response, so treat BytesSaved as an order of
+// magnitude rather than an exact count.
+const estimatedBytesPerBar = 120
+
+// buildFillPlan decides what date range(s), if any, fillHistoricalDataHandler
+// needs to fetch for symbol over [start, end], based on mode:
+//
+//   - "full" always (re)fetches the whole [start, end] window.
+//   - "incremental" (the default) fetches only the gap(s) before the
+//     earliest and after the latest date already stored for symbol,
+//     skipping the fetch entirely once [start, end] is fully covered.
+//   - "repair" ignores [start, end] and instead re-fetches any weekday gap
+//     wider than 3 days found inside the symbol's stored series.
+//
+// Errors reading the existing data are logged and treated as "nothing
+// stored", so the handler falls back to fetching the full range rather than
+// silently skipping a symbol it failed to inspect.
+func buildFillPlan(db *sql.DB, mode, symbol string, start, end time.Time) fillPlan {
+	switch mode {
+	case "full":
+		return fillPlan{ranges: []dateRange{{start, end}}}
+
+	case "repair":
+		gaps, err := historicalGaps(db, symbol)
+		if err != nil {
+			slog.Error("buildFillPlan: failed to scan gaps", "symbol", symbol, "error", err)
+			return fillPlan{ranges: []dateRange{{start, end}}}
+		}
+		return fillPlan{ranges: gaps}
+
+	default: // "incremental"
+		haveMin, haveMax, hasData, err := historicalDateRange(db, symbol)
+		if err != nil {
+			slog.Error("buildFillPlan: failed to read date range", "symbol", symbol, "error", err)
+			return fillPlan{ranges: []dateRange{{start, end}}}
+		}
+
+		ranges := incrementalRanges(start, end, haveMin, haveMax, hasData)
+		if !hasData {
+			return fillPlan{ranges: ranges}
+		}
+
+		full := tradingDaysBetween(start, end)
+		remaining := 0
+		for _, rg := range ranges {
+			remaining += tradingDaysBetween(rg.start, rg.end)
+		}
+		var bytesSaved int64
+		if full > remaining {
+			bytesSaved = int64(full-remaining) * estimatedBytesPerBar
+		}
+		return fillPlan{ranges: ranges, bytesSaved: bytesSaved}
+	}
+}
+
+// historicalDateRange returns the earliest and latest date stored for
+// symbol in stock_historical_data, and whether it has any rows at all.
+func historicalDateRange(db *sql.DB, symbol string) (min, max time.Time, hasData bool, err error) {
+	var minUnix, maxUnix sql.NullInt64
+	err = db.QueryRow(`
+		SELECT MIN(date), MAX(date) FROM stock_historical_data WHERE symbol = ?
+	`, symbol).Scan(&minUnix, &maxUnix)
+	if err != nil {
+		return time.Time{}, time.Time{}, false, err
+	}
+	if !minUnix.Valid || !maxUnix.Valid {
+		return time.Time{}, time.Time{}, false, nil
+	}
+	return time.Unix(minUnix.Int64, 0), time.Unix(maxUnix.Int64, 0), true, nil
+}
+
+// incrementalRanges splits [start, end] into the parts not already covered
+// by [haveMin, haveMax]: at most a pre-min range and a post-max range. The
+// post-max range is clamped to start the day after haveMax, per the "only
+// fetch since the last known bar" intent of incremental mode. It returns
+// the whole [start, end] window unchanged if hasData is false, and nil if
+// [start, end] is already fully covered.
+func incrementalRanges(start, end, haveMin, haveMax time.Time, hasData bool) []dateRange {
+	if !hasData {
+		return []dateRange{{start, end}}
+	}
+
+	var ranges []dateRange
+	if start.Before(haveMin) {
+		ranges = append(ranges, dateRange{start, haveMin.AddDate(0, 0, -1)})
+	}
+	if end.After(haveMax) {
+		ranges = append(ranges, dateRange{haveMax.AddDate(0, 0, 1), end})
+	}
+	return ranges
+}
+
+// historicalGaps scans symbol's stored dates in stock_historical_data for
+// consecutive rows more than 3 days apart -- wider than any single
+// weekend -- and returns the span between them as a dateRange to re-fetch.
+func historicalGaps(db *sql.DB, symbol string) ([]dateRange, error) {
+	rows, err := db.Query(`
+		SELECT date FROM stock_historical_data WHERE symbol = ? ORDER BY date
+	`, symbol)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var gaps []dateRange
+	var prev time.Time
+	first := true
+	for rows.Next() {
+		var unix int64
+		if err := rows.Scan(&unix); err != nil {
+			return nil, err
+		}
+		d := time.Unix(unix, 0)
+		if !first && d.Sub(prev) > 3*24*time.Hour {
+			gaps = append(gaps, dateRange{prev.AddDate(0, 0, 1), d.AddDate(0, 0, -1)})
+		}
+		prev = d
+		first = false
+	}
+	return gaps, rows.Err()
+}
+
+// tradingDaysBetween estimates the number of weekday (Mon-Fri) dates in
+// [start, end], used to turn a skipped date range into a bytes-saved
+// estimate; it doesn't account for market holidays.
+func tradingDaysBetween(start, end time.Time) int {
+	if end.Before(start) {
+		return 0
+	}
+	days := 0
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		if wd := d.Weekday(); wd != time.Saturday && wd != time.Sunday {
+			days++
+		}
+	}
+	return days
+}
+
 // getActiveSP500Tickers returns a list of active S&P 500 tickers from the database
 func getActiveSP500Tickers(db *sql.DB) ([]string, error) {
 	rows, err := db.Query(`
@@ -1693,18 +2060,6 @@ func sendJSONResponse(w http.ResponseWriter, response HandlerResponse) {
 	json.NewEncoder(w).Encode(response)
 }
 
-// tokenFromFile retrieves a token from a local file
-func tokenFromFile(file string) (*oauth2.Token, error) {
-	f, err := os.Open(file)
-	if err != nil {
-		return nil, err
-	}
-	defer f.Close()
-	tok := &oauth2.Token{}
-	err = json.NewDecoder(f).Decode(tok)
-	return tok, err
-}
-
 // extractMessageContent extracts content from a Gmail message
 func extractMessageContent(message *gmail.Message) error {
 	if message.Payload == nil {
@@ -1730,99 +2085,58 @@ func extractMessageContent(message *gmail.Message) error {
 	return nil
 }
 
-// fetchSP500List fetches the current S&P 500 constituents from local HTML file
-func fetchSP500List() ([]SP500Stock, error) {
-	// Read the local HTML file
-	content, err := os.ReadFile("sp500.html")
+// sp500RetryingSource wraps the live Wikipedia fetch with exponential
+// backoff and jitter, so a dropped connection or a transient 429/5xx
+// doesn't immediately fall through to the (stale) file/CSV snapshots
+// below. It's its own package var, rather than inlined into sp500Cache,
+// so a test can swap its Config.Clock for a retry.FakeClock (see
+// main_test.go's TestFetchSP500List) without waiting on real backoff
+// delays.
+var sp500RetryingSource = sp500.NewRetryingSource(sp500.NewHTTPSource(""), retry.Config{
+	InitialInterval:     500 * time.Millisecond,
+	Multiplier:          1.5,
+	RandomizationFactor: 0.5,
+	MaxInterval:         2 * time.Second,
+	MaxElapsedTime:      5 * time.Second,
+})
+
+// sp500Cache backs fetchSP500List. It tries a live Wikipedia fetch first,
+// falls back to the local sp500.html snapshot if that fails, and finally
+// to the CSV snapshot bundled into the binary so fetchSP500List still
+// returns something on a fresh checkout with neither network access nor a
+// cached sp500.html.
+var sp500Cache = sp500.NewCache(sp500.NewChainSource(
+	sp500RetryingSource,
+	sp500.NewFileSource("sp500.html"),
+	sp500.NewCSVSource(),
+), sp500.DefaultCacheTTL)
+
+// fetchSP500List returns the current S&P 500 constituents, memoized for
+// sp500Cache's TTL. force bypasses that memoization and re-fetches
+// (see listSP500Handler's force=true query param).
+func fetchSP500List(force bool) ([]SP500Stock, error) {
+	fetch := sp500Cache.Get
+	if force {
+		fetch = sp500Cache.Force
+	}
+	stocks, err := fetch(context.Background())
 	if err != nil {
-		return nil, fmt.Errorf("failed to read sp500.html: %v", err)
+		return nil, err
 	}
 
-	// Parse the HTML document
-	doc, err := html.Parse(bytes.NewReader(content))
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse HTML: %v", err)
-	}
-
-	var stocks []SP500Stock
-	var f func(*html.Node)
-	f = func(n *html.Node) {
-		if n.Type == html.ElementNode && n.Data == "table" {
-			// Check if this is the S&P 500 table
-			for _, a := range n.Attr {
-				if a.Key == "id" && a.Val == "constituents" {
-					// Found the right table, now parse rows
-					var currentStock SP500Stock
-					var inRow bool
-					var colIndex int
-
-					var parseRow func(*html.Node)
-					parseRow = func(n *html.Node) {
-						if n.Type == html.ElementNode {
-							switch n.Data {
-							case "tr":
-								if n.Parent != nil && n.Parent.Data == "tbody" {
-									inRow = true
-									colIndex = 0
-									currentStock = SP500Stock{}
-								}
-							case "td":
-								if !inRow {
-									return
-								}
-								switch colIndex {
-								case 0: // Symbol column
-									// Find the first anchor tag
-									for c := n.FirstChild; c != nil; c = c.NextSibling {
-										if c.Type == html.ElementNode && c.Data == "a" {
-											if c.FirstChild != nil {
-												currentStock.Symbol = strings.TrimSpace(c.FirstChild.Data)
-											}
-											break
-										}
-									}
-								case 1: // Security Name column
-									// Find the first anchor tag
-									for c := n.FirstChild; c != nil; c = c.NextSibling {
-										if c.Type == html.ElementNode && c.Data == "a" {
-											if c.FirstChild != nil {
-												currentStock.SecurityName = strings.TrimSpace(c.FirstChild.Data)
-											}
-											break
-										}
-									}
-									// After getting both columns, add to stocks if valid
-									if currentStock.Symbol != "" && currentStock.SecurityName != "" {
-										stocks = append(stocks, currentStock)
-									}
-								}
-								colIndex++
-							}
-						}
-						for c := n.FirstChild; c != nil; c = c.NextSibling {
-							parseRow(c)
-						}
-					}
-
-					// Parse all rows in the table
-					for c := n.FirstChild; c != nil; c = c.NextSibling {
-						parseRow(c)
-					}
-					return
-				}
-			}
+	result := make([]SP500Stock, len(stocks))
+	for i, s := range stocks {
+		result[i] = SP500Stock{
+			Symbol:       s.Symbol,
+			SecurityName: s.SecurityName,
+			Sector:       s.Sector,
+			SubIndustry:  s.SubIndustry,
+			DateAdded:    s.DateAdded,
+			CIK:          s.CIK,
+			Founded:      s.Founded,
 		}
-		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			f(c)
-		}
-	}
-	f(doc)
-
-	if len(stocks) == 0 {
-		return nil, fmt.Errorf("no stocks found in HTML file")
 	}
-
-	return stocks, nil
+	return result, nil
 }
 
 // listSP500Handler returns the current list of S&P 500 stocks
@@ -1836,7 +2150,7 @@ func listSP500Handler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	stocks, err := fetchSP500List()
+	stocks, err := fetchSP500List(r.URL.Query().Get("force") == "true")
 	if err != nil {
 		sendJSONResponse(w, HandlerResponse{
 			Success: false,
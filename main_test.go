@@ -2,11 +2,21 @@ package main
 
 import (
 	"testing"
+	"time"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/retry"
 )
 
 func TestFetchSP500List(t *testing.T) {
+	// Swap in a fake clock so a flaky or unreachable network makes
+	// sp500RetryingSource retry instantly instead of burning real wall-clock
+	// backoff delays during the test.
+	origClock := sp500RetryingSource.Config.Clock
+	sp500RetryingSource.Config.Clock = retry.NewFakeClock(time.Unix(0, 0))
+	defer func() { sp500RetryingSource.Config.Clock = origClock }()
+
 	// Fetch the S&P 500 list
-	stocks, err := fetchSP500List()
+	stocks, err := fetchSP500List(false)
 	if err != nil {
 		t.Fatalf("Failed to fetch S&P 500 list: %v", err)
 	}
@@ -0,0 +1,175 @@
+// Package backtest runs a pluggable Strategy bar by bar against a
+// symbol's historical OHLCV series and aggregates the resulting trades
+// into a BacktestResult, so a new strategy (moving-average cross, RSI,
+// ...) can be dropped in without touching the engine that walks bars and
+// tracks open/closed positions.
+package backtest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/marketdata"
+)
+
+// Side is which direction a position or entry order takes.
+type Side int
+
+const (
+	Long Side = iota
+	Short
+)
+
+// String renders s as "long" or "short", e.g. for a JSON response or log
+// line.
+func (s Side) String() string {
+	if s == Short {
+		return "short"
+	}
+	return "long"
+}
+
+// EntryOpts describes a Strategy's request to open a new position. Limit,
+// if set, enters at that price instead of the bar's close (e.g. a
+// sell-limit strategy's initial buy); Stop and Comment are carried
+// through for a caller's own bookkeeping and aren't interpreted by Run.
+type EntryOpts struct {
+	Side    Side
+	Limit   *float64
+	Stop    string
+	Qty     string
+	OrdID   string
+	Comment string
+}
+
+// Order is one instruction a Strategy.OnBar returns in response to the
+// bar just processed. Exactly one of Entry or ExitOrdID should be set:
+// Entry opens a new position, ExitOrdID closes the open position with
+// that OrdID at the bar's close.
+type Order struct {
+	Entry     *EntryOpts
+	ExitOrdID string
+}
+
+// State is what a Strategy sees of its own run so far: every position
+// still open, keyed by OrdID, so OnBar can decide which one (if any) to
+// close on the current bar.
+type State struct {
+	Open map[string]*Position
+}
+
+// Strategy decides, bar by bar, whether to open or close positions.
+// Implementations include SellLimitStrategy; a caller runs one via Run.
+type Strategy interface {
+	// Name identifies the strategy, e.g. for the "strategy" field in an
+	// API response or for dispatch in a registry keyed by name.
+	Name() string
+
+	// OnBar is called once per bar, in order, and returns the orders (if
+	// any) the strategy wants to place in response.
+	OnBar(ctx context.Context, symbol string, bar marketdata.Bar, state *State) ([]Order, error)
+}
+
+// Position is one trade, closed or still open: ExitPx and ExitTime are
+// zero until Run closes it.
+type Position struct {
+	EntryPx   float64
+	ExitPx    float64
+	EntryTime time.Time
+	ExitTime  time.Time
+	EntrySide Side
+	OrdID     string
+}
+
+// Profit returns the position's return as a price ratio (1.05 means
+// +5%): ExitPx/EntryPx for a Long position, and the inverse (EntryPx/ExitPx)
+// for a Short, since a short profits when the price falls rather than
+// rises. It's meaningless before the position is closed (ExitPx == 0).
+func (p Position) Profit() float64 {
+	if p.EntryPx == 0 || p.ExitPx == 0 {
+		return 1
+	}
+	if p.EntrySide == Short {
+		return p.EntryPx / p.ExitPx
+	}
+	return p.ExitPx / p.EntryPx
+}
+
+// BacktestResult aggregates every position Run closed during a strategy's
+// pass over a symbol's bars.
+type BacktestResult struct {
+	ClosedOrd         []Position `json:"closed_orders"`
+	TotalClosedTrades int        `json:"total_closed_trades"`
+	ProfitableTrades  int        `json:"profitable_trades"`
+	PercentProfitable float64    `json:"percent_profitable"`
+	NetProfit         float64    `json:"net_profit"`
+}
+
+// newBacktestResult aggregates closed into a BacktestResult. NetProfit is
+// the cumulative product of every position's Profit(), starting from 1.0,
+// so it reads as a final-equity multiplier (1.1 means the run ended up
+// +10% overall, compounding each trade into the next).
+func newBacktestResult(closed []Position) BacktestResult {
+	result := BacktestResult{ClosedOrd: closed, TotalClosedTrades: len(closed), NetProfit: 1.0}
+	for _, pos := range closed {
+		profit := pos.Profit()
+		result.NetProfit *= profit
+		if profit > 1 {
+			result.ProfitableTrades++
+		}
+	}
+	if result.TotalClosedTrades > 0 {
+		result.PercentProfitable = float64(result.ProfitableTrades) / float64(result.TotalClosedTrades) * 100
+	}
+	return result
+}
+
+// Run walks bars in order (oldest first), calling strategy.OnBar for each
+// and applying the orders it returns. A position still open once bars is
+// exhausted is left out of the result entirely — Run never force-closes
+// at the final bar, since an in-progress trade has no realized Profit().
+func Run(ctx context.Context, strategy Strategy, symbol string, bars []marketdata.Bar) (BacktestResult, error) {
+	state := &State{Open: make(map[string]*Position)}
+	var closed []Position
+	var nextOrdID int
+
+	for _, bar := range bars {
+		orders, err := strategy.OnBar(ctx, symbol, bar, state)
+		if err != nil {
+			return BacktestResult{}, fmt.Errorf("backtest: %s.OnBar failed: %w", strategy.Name(), err)
+		}
+
+		for _, ord := range orders {
+			if ord.Entry != nil {
+				ordID := ord.Entry.OrdID
+				if ordID == "" {
+					ordID = fmt.Sprintf("%s-%d", symbol, nextOrdID)
+					nextOrdID++
+				}
+				px := bar.Close
+				if ord.Entry.Limit != nil {
+					px = *ord.Entry.Limit
+				}
+				state.Open[ordID] = &Position{
+					EntryPx:   px,
+					EntryTime: bar.Date,
+					EntrySide: ord.Entry.Side,
+					OrdID:     ordID,
+				}
+				continue
+			}
+
+			pos, ok := state.Open[ord.ExitOrdID]
+			if !ok {
+				continue
+			}
+			pos.ExitPx = bar.Close
+			pos.ExitTime = bar.Date
+			closed = append(closed, *pos)
+			delete(state.Open, ord.ExitOrdID)
+		}
+	}
+
+	return newBacktestResult(closed), nil
+}
@@ -0,0 +1,112 @@
+package backtest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/marketdata"
+)
+
+func bar(t time.Time, close float64) marketdata.Bar {
+	return marketdata.Bar{Symbol: "TEST", Date: t, Open: close, High: close, Low: close, Close: close}
+}
+
+// buyAndHoldOnce enters Long on the first bar and never exits, to exercise
+// Run's handling of a position still open when bars is exhausted.
+type buyAndHoldOnce struct{ entered bool }
+
+func (s *buyAndHoldOnce) Name() string { return "buy-and-hold-once" }
+
+func (s *buyAndHoldOnce) OnBar(ctx context.Context, symbol string, b marketdata.Bar, state *State) ([]Order, error) {
+	if s.entered {
+		return nil, nil
+	}
+	s.entered = true
+	return []Order{{Entry: &EntryOpts{Side: Long, OrdID: "only"}}}, nil
+}
+
+func TestRunLeavesStillOpenPositionsOutOfResult(t *testing.T) {
+	bars := []marketdata.Bar{bar(time.Unix(0, 0), 100), bar(time.Unix(1, 0), 110)}
+	result, err := Run(context.Background(), &buyAndHoldOnce{}, "TEST", bars)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if result.TotalClosedTrades != 0 {
+		t.Errorf("TotalClosedTrades = %d, want 0 (position never closed)", result.TotalClosedTrades)
+	}
+}
+
+func TestRunAggregatesClosedPositions(t *testing.T) {
+	bars := []marketdata.Bar{
+		bar(time.Unix(0, 0), 100),
+		bar(time.Unix(1, 0), 120),
+		bar(time.Unix(2, 0), 90),
+	}
+	strategy := NewSellLimitStrategy(0.10)
+
+	result, err := Run(context.Background(), strategy, "TEST", bars)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if result.TotalClosedTrades != 1 {
+		t.Fatalf("TotalClosedTrades = %d, want 1", result.TotalClosedTrades)
+	}
+
+	pos := result.ClosedOrd[0]
+	if pos.EntryPx != 100 || pos.ExitPx != 90 {
+		t.Errorf("closed position = %+v, want EntryPx 100 ExitPx 90", pos)
+	}
+	if result.NetProfit != pos.Profit() {
+		t.Errorf("NetProfit = %v, want %v (single trade)", result.NetProfit, pos.Profit())
+	}
+}
+
+func TestPositionProfitInvertsForShort(t *testing.T) {
+	long := Position{EntryPx: 100, ExitPx: 110, EntrySide: Long}
+	if got := long.Profit(); got != 1.1 {
+		t.Errorf("Long Profit() = %v, want 1.1", got)
+	}
+
+	short := Position{EntryPx: 100, ExitPx: 110, EntrySide: Short}
+	want := 100.0 / 110.0
+	if got := short.Profit(); got != want {
+		t.Errorf("Short Profit() = %v, want %v", got, want)
+	}
+}
+
+func TestSellLimitStrategyExitsOnTrailingStopBreach(t *testing.T) {
+	strategy := NewSellLimitStrategy(0.10)
+	state := &State{Open: make(map[string]*Position)}
+
+	orders, err := strategy.OnBar(context.Background(), "TEST", bar(time.Unix(0, 0), 100), state)
+	if err != nil || len(orders) != 1 || orders[0].Entry == nil {
+		t.Fatalf("expected an entry order on the first bar, got %+v, err %v", orders, err)
+	}
+
+	orders, err = strategy.OnBar(context.Background(), "TEST", bar(time.Unix(1, 0), 120), state)
+	if err != nil || orders != nil {
+		t.Fatalf("expected no orders while rising, got %+v, err %v", orders, err)
+	}
+
+	orders, err = strategy.OnBar(context.Background(), "TEST", bar(time.Unix(2, 0), 105), state)
+	if err != nil || len(orders) != 1 || orders[0].ExitOrdID != sellLimitOrdID {
+		t.Fatalf("expected an exit order once 10%% below the 120 high (at or below 108), got %+v, err %v", orders, err)
+	}
+}
+
+func TestGetReturnsUnknownStrategyError(t *testing.T) {
+	if _, err := Get("does-not-exist"); err == nil {
+		t.Error("expected Get to error for an unregistered strategy name")
+	}
+}
+
+func TestGetDefaultsToSellLimit(t *testing.T) {
+	strategy, err := Get("")
+	if err != nil {
+		t.Fatalf("Get(\"\") returned error: %v", err)
+	}
+	if strategy.Name() != DefaultStrategyName {
+		t.Errorf("Get(\"\").Name() = %q, want %q", strategy.Name(), DefaultStrategyName)
+	}
+}
@@ -0,0 +1,28 @@
+package backtest
+
+import "fmt"
+
+// DefaultStrategyName is used when Get's name is empty.
+const DefaultStrategyName = "sell-limit"
+
+// factories builds a fresh Strategy instance per lookup: a Strategy's
+// OnBar tracks its own run state (e.g. SellLimitStrategy's high-water
+// mark), so one instance can't be shared across concurrent runs the way
+// pkg/providers' MarketDataProvider factories can.
+var factories = map[string]func() Strategy{
+	"sell-limit": func() Strategy { return NewSellLimitStrategy(0) },
+}
+
+// Get returns a fresh instance of the named strategy. An empty name
+// resolves to DefaultStrategyName.
+func Get(name string) (Strategy, error) {
+	if name == "" {
+		name = DefaultStrategyName
+	}
+
+	factory, ok := factories[name]
+	if !ok {
+		return nil, fmt.Errorf("backtest: unknown strategy %q (want one of sell-limit)", name)
+	}
+	return factory(), nil
+}
@@ -0,0 +1,68 @@
+package backtest
+
+import (
+	"context"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/marketdata"
+)
+
+// DefaultTrailingStopPct matches pkg/portfolio.DefaultTrailingStopPct:
+// exit once the close has fallen this fraction below its post-entry high.
+const DefaultTrailingStopPct = 0.10
+
+// sellLimitOrdID is the fixed OrdID SellLimitStrategy uses for its
+// position: it only ever holds one position per symbol at a time, so
+// there's no need to mint a fresh one per entry.
+const sellLimitOrdID = "sell-limit"
+
+// SellLimitStrategy buys on the first bar it sees (and again immediately
+// after any exit) and sells once the close has fallen TrailingStopPct
+// below its highest close since entry. It's the repo's original
+// sell-limit rule expressed as a Strategy, mirroring the trailing-stop
+// trigger pkg/portfolio.Valuator computes for live positions but driven
+// by a historical bar series instead of a real-time quote.
+type SellLimitStrategy struct {
+	TrailingStopPct float64
+
+	high float64
+	open bool
+}
+
+// NewSellLimitStrategy returns a SellLimitStrategy using trailingStopPct
+// (DefaultTrailingStopPct if zero).
+func NewSellLimitStrategy(trailingStopPct float64) *SellLimitStrategy {
+	if trailingStopPct == 0 {
+		trailingStopPct = DefaultTrailingStopPct
+	}
+	return &SellLimitStrategy{TrailingStopPct: trailingStopPct}
+}
+
+// Name identifies this strategy as "sell-limit".
+func (s *SellLimitStrategy) Name() string { return "sell-limit" }
+
+// OnBar enters Long on the first bar (or the first bar after an exit) and
+// exits once bar.Close has dropped TrailingStopPct below the highest
+// close seen since entry.
+func (s *SellLimitStrategy) OnBar(ctx context.Context, symbol string, bar marketdata.Bar, state *State) ([]Order, error) {
+	if !s.open {
+		s.open = true
+		s.high = bar.Close
+		return []Order{{Entry: &EntryOpts{Side: Long, OrdID: sellLimitOrdID, Comment: "sell-limit entry"}}}, nil
+	}
+
+	if bar.Close > s.high {
+		s.high = bar.Close
+	}
+
+	if s.high <= 0 {
+		return nil, nil
+	}
+
+	belowHigh := (s.high - bar.Close) / s.high
+	if belowHigh >= s.TrailingStopPct {
+		s.open = false
+		return []Order{{ExitOrdID: sellLimitOrdID}}, nil
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,65 @@
+// Package config provides a generic, executable-relative resource locator
+// used to find config files and other data files (e.g. sp500.html) no
+// matter how the binary was invoked.
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// ExecutableDir returns the directory the running binary actually lives in,
+// resolving any symlink so that invoking e.g. /usr/local/bin/flight, a
+// symlink into /opt/flight/bin/flight, still resolves data files relative
+// to the real install location rather than the symlink's directory.
+func ExecutableDir() string {
+	exe, err := os.Executable()
+	if err != nil {
+		return "."
+	}
+
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+
+	abs, err := filepath.Abs(exe)
+	if err != nil {
+		return filepath.Dir(exe)
+	}
+
+	return filepath.Dir(abs)
+}
+
+// SearchDirs returns the ordered list of directories FindFile walks when
+// looking for a named resource: the current directory, its two parents,
+// and the same three levels relative to the resolved executable directory.
+func SearchDirs() []string {
+	exeDir := ExecutableDir()
+
+	return []string{
+		".",
+		"..",
+		"../..",
+		"../../..",
+		exeDir,
+		filepath.Join(exeDir, ".."),
+		filepath.Join(exeDir, "..", ".."),
+	}
+}
+
+// FindFile walks SearchDirs looking for name and returns the first path
+// that exists as a regular file. It returns "" if name can't be found
+// anywhere, leaving callers to produce their own not-found error with
+// whatever context they have (config file vs. data fixture, etc).
+func FindFile(name string) string {
+	for _, dir := range SearchDirs() {
+		candidate := filepath.Join(dir, name)
+		info, err := os.Stat(candidate)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		return candidate
+	}
+
+	return ""
+}
@@ -0,0 +1,58 @@
+package credential
+
+// Well-known CredentialRef.Name values ResolveBundle looks for, following
+// the same "<thing>_api_key" convention Config.MarketDataProviders already
+// uses for market-data tokens (see GetCredential(name + "_api_key")).
+const (
+	ServiceAccountJSONRefName = "service_account_json"
+	PrivateKeyRefName         = "private_key"
+	IEXAPIKeyRefName          = "iex_api_key"
+	AlpacaAPIKeyRefName       = "alpaca_api_key"
+	DBPasswordRefName         = "db_password"
+)
+
+// Bundle groups the secrets a running service needs at once -- the GCP
+// service account key, a private key, market-data API tokens, and a DB
+// password -- resolved from whichever CredentialRef backends a deployment
+// configured (file, env, or GCP Secret Manager) rather than baked into the
+// (non-secret, safe to commit) config file itself.
+type Bundle struct {
+	ServiceAccountJSON []byte
+	PrivateKeyPEM      []byte
+	IEXAPIKey          string
+	AlpacaAPIKey       string
+	DBPassword         string
+}
+
+// Resolver resolves a single named credential. *Config (via GetCredential)
+// satisfies this, so ResolveBundle doesn't need to import the config
+// package that already imports credential.
+type Resolver interface {
+	GetCredential(name string) (*Credential, error)
+}
+
+// ResolveBundle looks up each of the well-known ref names above in
+// resolve, treating an unconfigured or unresolved name as simply absent
+// (the zero value in the returned Bundle) rather than an error -- a
+// deployment that only set up a service account JSON shouldn't have to
+// also configure a DB password credential it never uses.
+func ResolveBundle(resolve Resolver) Bundle {
+	bytes := func(name string) []byte {
+		cred, err := resolve.GetCredential(name)
+		if err != nil {
+			return nil
+		}
+		return cred.Data
+	}
+	str := func(name string) string {
+		return string(bytes(name))
+	}
+
+	return Bundle{
+		ServiceAccountJSON: bytes(ServiceAccountJSONRefName),
+		PrivateKeyPEM:      bytes(PrivateKeyRefName),
+		IEXAPIKey:          str(IEXAPIKeyRefName),
+		AlpacaAPIKey:       str(AlpacaAPIKeyRefName),
+		DBPassword:         str(DBPasswordRefName),
+	}
+}
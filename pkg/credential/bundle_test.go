@@ -0,0 +1,49 @@
+package credential
+
+import (
+	"fmt"
+	"testing"
+)
+
+// stubResolver is a Resolver backed by a fixed map, so ResolveBundle can be
+// tested without a *Config or any real provider backend.
+type stubResolver map[string]*Credential
+
+func (s stubResolver) GetCredential(name string) (*Credential, error) {
+	cred, ok := s[name]
+	if !ok {
+		return nil, fmt.Errorf("no credential named %q configured", name)
+	}
+	return cred, nil
+}
+
+func TestResolveBundleFillsConfiguredNames(t *testing.T) {
+	resolver := stubResolver{
+		IEXAPIKeyRefName:  {Name: IEXAPIKeyRefName, Data: []byte("iex-secret")},
+		DBPasswordRefName: {Name: DBPasswordRefName, Data: []byte("hunter2")},
+	}
+
+	bundle := ResolveBundle(resolver)
+
+	if bundle.IEXAPIKey != "iex-secret" {
+		t.Errorf("IEXAPIKey = %q, want %q", bundle.IEXAPIKey, "iex-secret")
+	}
+	if bundle.DBPassword != "hunter2" {
+		t.Errorf("DBPassword = %q, want %q", bundle.DBPassword, "hunter2")
+	}
+	if bundle.ServiceAccountJSON != nil {
+		t.Errorf("ServiceAccountJSON = %q, want nil (not configured)", bundle.ServiceAccountJSON)
+	}
+	if bundle.AlpacaAPIKey != "" {
+		t.Errorf("AlpacaAPIKey = %q, want empty (not configured)", bundle.AlpacaAPIKey)
+	}
+}
+
+func TestResolveBundleWithNoCredentialsConfiguredIsZeroValue(t *testing.T) {
+	bundle := ResolveBundle(stubResolver{})
+
+	if bundle.ServiceAccountJSON != nil || bundle.PrivateKeyPEM != nil ||
+		bundle.IEXAPIKey != "" || bundle.AlpacaAPIKey != "" || bundle.DBPassword != "" {
+		t.Errorf("ResolveBundle(empty resolver) = %+v, want zero value", bundle)
+	}
+}
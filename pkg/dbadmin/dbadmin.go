@@ -0,0 +1,133 @@
+// Package dbadmin centralizes the table-browsing endpoints' access to the
+// database: the allow-list of browsable tables, schema introspection, and
+// keyset-paginated row fetches, so handlers never interpolate a table name
+// or a raw OFFSET straight from the request into SQL.
+package dbadmin
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// MaxPageSize bounds how many rows a single page can request.
+const MaxPageSize = 500
+
+// DefaultPageSize is used when a caller doesn't specify one.
+const DefaultPageSize = 100
+
+// checkSQLiteDriver rejects every driver("") value except "sqlite" (the
+// empty string, matching store.Open's own default). Tables, IsAllowed,
+// Schema, and FetchPage all rely on sqlite_master, PRAGMA table_info, and
+// the implicit SQLite rowid column, none of which exist on Postgres; a
+// deployment running with Config.StorageDriver == "postgres" (see
+// pkg/types.Config) must get a clear error here rather than a confusing
+// "no such table: sqlite_master" or "relation does not exist" failure
+// several calls deep.
+func checkSQLiteDriver(driver string) error {
+	if driver != "" && driver != "sqlite" {
+		return fmt.Errorf("dbadmin: driver %q not supported (table browsing only works against sqlite)", driver)
+	}
+	return nil
+}
+
+// Tables returns the names of db's user tables (excluding sqlite_%
+// internal tables), the allow-list table names are checked against. driver
+// is Config.StorageDriver; Tables returns an error for anything but
+// "sqlite" (see checkSQLiteDriver).
+func Tables(ctx context.Context, db *sql.DB, driver string) ([]string, error) {
+	if err := checkSQLiteDriver(driver); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT name FROM sqlite_master
+		WHERE type='table' AND name NOT LIKE 'sqlite_%'
+		ORDER BY name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("dbadmin: failed to list tables: %w", err)
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("dbadmin: failed to scan table name: %w", err)
+		}
+		tables = append(tables, name)
+	}
+	return tables, rows.Err()
+}
+
+// IsAllowed reports whether table is one of db's user tables. Handlers must
+// call this before interpolating table into any query (PRAGMA, page
+// fetches) — it's the one place user input is allowed to become part of a
+// SQL string, rather than a bound parameter. driver is Config.StorageDriver;
+// see checkSQLiteDriver.
+func IsAllowed(ctx context.Context, db *sql.DB, driver, table string) (bool, error) {
+	if err := checkSQLiteDriver(driver); err != nil {
+		return false, err
+	}
+
+	var exists bool
+	err := db.QueryRowContext(ctx, `
+		SELECT 1 FROM sqlite_master
+		WHERE type='table' AND name = ? AND name NOT LIKE 'sqlite_%'
+	`, table).Scan(&exists)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("dbadmin: failed to check table %q: %w", table, err)
+	}
+	return exists, nil
+}
+
+// Column describes one column from PRAGMA table_info.
+type Column struct {
+	Name         string `json:"name"`
+	Type         string `json:"type"`
+	NotNull      bool   `json:"not_null"`
+	DefaultValue string `json:"default_value,omitempty"`
+	PrimaryKey   bool   `json:"primary_key"`
+}
+
+// Schema returns table's column definitions. Callers must have already
+// validated table with IsAllowed — PRAGMA doesn't accept bound parameters
+// for the table name. driver is Config.StorageDriver; see checkSQLiteDriver.
+func Schema(ctx context.Context, db *sql.DB, driver, table string) ([]Column, error) {
+	if err := checkSQLiteDriver(driver); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, fmt.Sprintf("PRAGMA table_info(%q)", table))
+	if err != nil {
+		return nil, fmt.Errorf("dbadmin: failed to read schema for %q: %w", table, err)
+	}
+	defer rows.Close()
+
+	var columns []Column
+	for rows.Next() {
+		var (
+			cid        int
+			name       string
+			ctype      string
+			notNull    int
+			defaultVal sql.NullString
+			pk         int
+		)
+		if err := rows.Scan(&cid, &name, &ctype, &notNull, &defaultVal, &pk); err != nil {
+			return nil, fmt.Errorf("dbadmin: failed to scan column for %q: %w", table, err)
+		}
+		columns = append(columns, Column{
+			Name:         name,
+			Type:         ctype,
+			NotNull:      notNull != 0,
+			DefaultValue: defaultVal.String,
+			PrimaryKey:   pk != 0,
+		})
+	}
+	return columns, rows.Err()
+}
@@ -0,0 +1,137 @@
+package dbadmin
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("sql.Open returned error: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if _, err := db.Exec(`CREATE TABLE widgets (name TEXT NOT NULL, price REAL)`); err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		if _, err := db.Exec(`INSERT INTO widgets (name, price) VALUES (?, ?)`, "widget", float64(i)); err != nil {
+			t.Fatalf("failed to insert row: %v", err)
+		}
+	}
+	return db
+}
+
+func TestTablesAndIsAllowed(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	tables, err := Tables(ctx, db, "")
+	if err != nil {
+		t.Fatalf("Tables returned error: %v", err)
+	}
+	if len(tables) != 1 || tables[0] != "widgets" {
+		t.Errorf("Tables = %v, want [widgets]", tables)
+	}
+
+	allowed, err := IsAllowed(ctx, db, "", "widgets")
+	if err != nil || !allowed {
+		t.Errorf("IsAllowed(widgets) = %v, %v, want true, nil", allowed, err)
+	}
+
+	allowed, err = IsAllowed(ctx, db, "", "sqlite_master")
+	if err != nil || allowed {
+		t.Errorf("IsAllowed(sqlite_master) = %v, %v, want false, nil", allowed, err)
+	}
+
+	allowed, err = IsAllowed(ctx, db, "", "nope; DROP TABLE widgets")
+	if err != nil || allowed {
+		t.Errorf("IsAllowed(nonexistent) = %v, %v, want false, nil", allowed, err)
+	}
+}
+
+func TestTablesRejectsNonSQLiteDriver(t *testing.T) {
+	db := newTestDB(t)
+
+	if _, err := Tables(context.Background(), db, "postgres"); err == nil {
+		t.Error("Tables with driver=postgres should error, got nil")
+	}
+	if _, err := IsAllowed(context.Background(), db, "postgres", "widgets"); err == nil {
+		t.Error("IsAllowed with driver=postgres should error, got nil")
+	}
+	if _, err := Schema(context.Background(), db, "postgres", "widgets"); err == nil {
+		t.Error("Schema with driver=postgres should error, got nil")
+	}
+	if _, err := FetchPage(context.Background(), db, "postgres", "widgets", PageParams{Size: 2}); err == nil {
+		t.Error("FetchPage with driver=postgres should error, got nil")
+	}
+}
+
+func TestSchema(t *testing.T) {
+	db := newTestDB(t)
+
+	columns, err := Schema(context.Background(), db, "", "widgets")
+	if err != nil {
+		t.Fatalf("Schema returned error: %v", err)
+	}
+	if len(columns) != 2 || columns[0].Name != "name" || !columns[0].NotNull {
+		t.Errorf("Schema = %+v, want [name NOT NULL, price]", columns)
+	}
+}
+
+func TestParsePageParams(t *testing.T) {
+	p, err := ParsePageParams("", "", "")
+	if err != nil || p.Size != DefaultPageSize {
+		t.Errorf("default params = %+v, %v, want Size=%d, nil", p, err, DefaultPageSize)
+	}
+
+	p, err = ParsePageParams("99999", "", "")
+	if err != nil || p.Size != MaxPageSize {
+		t.Errorf("oversized pageSize clamp = %+v, %v, want Size=%d, nil", p, err, MaxPageSize)
+	}
+
+	if _, err := ParsePageParams("not-a-number", "", ""); err == nil {
+		t.Error("ParsePageParams with garbage pageSize should error, got nil")
+	}
+
+	if _, err := ParsePageParams("10", "5", "5"); err == nil {
+		t.Error("ParsePageParams with both after and before should error, got nil")
+	}
+}
+
+func TestFetchPagePaginatesByRowid(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	page, err := FetchPage(ctx, db, "", "widgets", PageParams{Size: 2})
+	if err != nil {
+		t.Fatalf("FetchPage returned error: %v", err)
+	}
+	if page.Total != 5 || len(page.Rows) != 2 {
+		t.Fatalf("first page = total %d, %d rows; want 5, 2", page.Total, len(page.Rows))
+	}
+	if page.NextCursor == "" {
+		t.Fatal("first page NextCursor is empty")
+	}
+
+	after, err := ParsePageParams("2", page.NextCursor, "")
+	if err != nil {
+		t.Fatalf("ParsePageParams returned error: %v", err)
+	}
+	next, err := FetchPage(ctx, db, "", "widgets", after)
+	if err != nil {
+		t.Fatalf("FetchPage (next page) returned error: %v", err)
+	}
+	if len(next.Rows) != 2 {
+		t.Errorf("next page rows = %d, want 2", len(next.Rows))
+	}
+	if next.Rows[0]["rowid"] == page.Rows[0]["rowid"] {
+		t.Error("next page returned the same first row as the first page")
+	}
+}
@@ -0,0 +1,151 @@
+package dbadmin
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+)
+
+// PageParams are validated inputs to FetchPage: Size is clamped to
+// [1, MaxPageSize], and After/Before are rowid cursors (at most one set)
+// rather than an OFFSET that gets slower, and less stable under concurrent
+// writes, the deeper a caller pages.
+type PageParams struct {
+	Size   int
+	After  int64
+	Before int64
+}
+
+// ParsePageParams validates raw "pageSize"/"after"/"before" query-param
+// strings. An empty or unparsable pageSize defaults to DefaultPageSize
+// rather than silently becoming 0; an out-of-range pageSize is clamped
+// instead of rejected.
+func ParsePageParams(pageSizeParam, afterParam, beforeParam string) (PageParams, error) {
+	size := DefaultPageSize
+	if pageSizeParam != "" {
+		n, err := strconv.Atoi(pageSizeParam)
+		if err != nil {
+			return PageParams{}, fmt.Errorf("dbadmin: invalid pageSize %q", pageSizeParam)
+		}
+		size = n
+	}
+	if size < 1 {
+		size = 1
+	}
+	if size > MaxPageSize {
+		size = MaxPageSize
+	}
+
+	var after, before int64
+	if afterParam != "" {
+		n, err := strconv.ParseInt(afterParam, 10, 64)
+		if err != nil {
+			return PageParams{}, fmt.Errorf("dbadmin: invalid after cursor %q", afterParam)
+		}
+		after = n
+	}
+	if beforeParam != "" {
+		n, err := strconv.ParseInt(beforeParam, 10, 64)
+		if err != nil {
+			return PageParams{}, fmt.Errorf("dbadmin: invalid before cursor %q", beforeParam)
+		}
+		before = n
+	}
+	if after != 0 && before != 0 {
+		return PageParams{}, fmt.Errorf("dbadmin: after and before cursors are mutually exclusive")
+	}
+
+	return PageParams{Size: size, After: after, Before: before}, nil
+}
+
+// Page is a validated, keyset-paginated slice of rows from a single table.
+type Page struct {
+	Columns    []string                 `json:"columns"`
+	Rows       []map[string]interface{} `json:"rows"`
+	Total      int                      `json:"total"`
+	NextCursor string                   `json:"next_cursor,omitempty"`
+	PrevCursor string                   `json:"prev_cursor,omitempty"`
+}
+
+// FetchPage returns one page of table's rows, keyed on SQLite's implicit
+// rowid. Callers must have already validated table with IsAllowed. driver
+// is Config.StorageDriver; see checkSQLiteDriver.
+func FetchPage(ctx context.Context, db *sql.DB, driver, table string, params PageParams) (*Page, error) {
+	if err := checkSQLiteDriver(driver); err != nil {
+		return nil, err
+	}
+
+	var total int
+	if err := db.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %q", table)).Scan(&total); err != nil {
+		return nil, fmt.Errorf("dbadmin: failed to count rows in %q: %w", table, err)
+	}
+
+	var (
+		query string
+		args  []interface{}
+	)
+	switch {
+	case params.Before != 0:
+		query = fmt.Sprintf("SELECT rowid, * FROM %q WHERE rowid < ? ORDER BY rowid DESC LIMIT ?", table)
+		args = []interface{}{params.Before, params.Size}
+	default:
+		query = fmt.Sprintf("SELECT rowid, * FROM %q WHERE rowid > ? ORDER BY rowid ASC LIMIT ?", table)
+		args = []interface{}{params.After, params.Size}
+	}
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("dbadmin: failed to query %q: %w", table, err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("dbadmin: failed to read columns for %q: %w", table, err)
+	}
+
+	var rowids []int64
+	result := make([]map[string]interface{}, 0, params.Size)
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		valuePtrs := make([]interface{}, len(columns))
+		for i := range columns {
+			valuePtrs[i] = &values[i]
+		}
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return nil, fmt.Errorf("dbadmin: failed to scan row from %q: %w", table, err)
+		}
+
+		row := make(map[string]interface{}, len(columns))
+		for i, col := range columns {
+			if b, ok := values[i].([]byte); ok {
+				row[col] = string(b)
+			} else {
+				row[col] = values[i]
+			}
+		}
+		result = append(result, row)
+		rowids = append(rowids, row["rowid"].(int64))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("dbadmin: failed to read %q: %w", table, err)
+	}
+
+	// A "before" page is fetched newest-first so LIMIT keeps the rows
+	// nearest the cursor; put it back in ascending rowid order to match
+	// the "after"/no-cursor case.
+	if params.Before != 0 {
+		for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+			result[i], result[j] = result[j], result[i]
+			rowids[i], rowids[j] = rowids[j], rowids[i]
+		}
+	}
+
+	page := &Page{Columns: columns, Rows: result, Total: total}
+	if len(rowids) > 0 {
+		page.PrevCursor = strconv.FormatInt(rowids[0], 10)
+		page.NextCursor = strconv.FormatInt(rowids[len(rowids)-1], 10)
+	}
+	return page, nil
+}
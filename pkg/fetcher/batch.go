@@ -0,0 +1,63 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/marketdata"
+)
+
+// MaxSymbolsPerRequest bounds how many symbols a single FetchAll call will
+// accept, so a caller POSTing the full S&P 500 list (see fetchSP500List)
+// can't overwhelm the SQLite store or the upstream provider in one go.
+const MaxSymbolsPerRequest = 100
+
+// LimitExceededError reports that a caller asked for more symbols than
+// MaxSymbolsPerRequest in a single FetchAll call.
+type LimitExceededError struct {
+	Requested int
+	Limit     int
+}
+
+func (e *LimitExceededError) Error() string {
+	return fmt.Sprintf("fetcher: requested %d symbols, exceeds limit of %d per request", e.Requested, e.Limit)
+}
+
+// FetchAll fetches start..end daily bars for every symbol, chunking them
+// into sequential batches of at most MaxSymbolsPerRequest (each run
+// through FillAll's normal bounded-concurrency pool) instead of handing
+// FillAll an unbounded symbol list in one shot. A symbol-level failure
+// doesn't fail the whole call: every Result (success or error) is
+// returned, one per input symbol, so the caller can act on the partial
+// success rather than losing a 500-symbol fill to one bad ticker.
+func (f *Fetcher) FetchAll(ctx context.Context, provider marketdata.Provider, symbols []string, start, end time.Time) ([]Result, error) {
+	results := make([]Result, 0, len(symbols))
+
+	for batchStart := 0; batchStart < len(symbols); batchStart += MaxSymbolsPerRequest {
+		batchEnd := batchStart + MaxSymbolsPerRequest
+		if batchEnd > len(symbols) {
+			batchEnd = len(symbols)
+		}
+
+		for result := range f.FillAll(ctx, provider, symbols[batchStart:batchEnd], start, end) {
+			results = append(results, result)
+			if ctx.Err() != nil {
+				return results, ctx.Err()
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// CheckSymbolLimit returns a *LimitExceededError if len(symbols) exceeds
+// MaxSymbolsPerRequest, for a caller (e.g. an HTTP handler) that wants to
+// reject an oversized request before doing any work rather than letting
+// FetchAll silently batch it.
+func CheckSymbolLimit(symbols []string) error {
+	if len(symbols) > MaxSymbolsPerRequest {
+		return &LimitExceededError{Requested: len(symbols), Limit: MaxSymbolsPerRequest}
+	}
+	return nil
+}
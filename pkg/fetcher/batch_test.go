@@ -0,0 +1,67 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestFetchAllChunksLargerThanMaxSymbolsPerRequest(t *testing.T) {
+	f := New(4, 1000, 1000)
+	provider := stubProvider{}
+
+	symbols := make([]string, MaxSymbolsPerRequest+25)
+	for i := range symbols {
+		symbols[i] = fmt.Sprintf("SYM%d", i)
+	}
+
+	results, err := f.FetchAll(context.Background(), provider, symbols, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("FetchAll returned error: %v", err)
+	}
+	if len(results) != len(symbols) {
+		t.Errorf("len(results) = %d, want %d", len(results), len(symbols))
+	}
+}
+
+func TestFetchAllAggregatesPartialFailures(t *testing.T) {
+	f := New(4, 1000, 1000)
+	provider := stubProvider{errs: map[string]error{"BAD": errors.New("stub fetch failure")}}
+
+	results, err := f.FetchAll(context.Background(), provider, []string{"AAPL", "BAD", "MSFT"}, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("FetchAll returned error: %v", err)
+	}
+
+	var badErr error
+	for _, r := range results {
+		if r.Symbol == "BAD" {
+			badErr = r.Err
+		} else if r.Err != nil {
+			t.Errorf("unexpected error for %s: %v", r.Symbol, r.Err)
+		}
+	}
+	if badErr == nil {
+		t.Error("expected a non-nil error for BAD, with AAPL and MSFT unaffected")
+	}
+}
+
+func TestCheckSymbolLimitRejectsOversizedRequests(t *testing.T) {
+	symbols := make([]string, MaxSymbolsPerRequest+1)
+	err := CheckSymbolLimit(symbols)
+	if err == nil {
+		t.Fatal("expected an error for a request over MaxSymbolsPerRequest")
+	}
+	if _, ok := err.(*LimitExceededError); !ok {
+		t.Errorf("err = %T, want *LimitExceededError", err)
+	}
+}
+
+func TestCheckSymbolLimitAllowsRequestsAtTheLimit(t *testing.T) {
+	symbols := make([]string, MaxSymbolsPerRequest)
+	if err := CheckSymbolLimit(symbols); err != nil {
+		t.Errorf("CheckSymbolLimit at the limit returned %v, want nil", err)
+	}
+}
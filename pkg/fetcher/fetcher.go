@@ -0,0 +1,216 @@
+// Package fetcher runs bounded-concurrency, rate-limited fetches against a
+// marketdata.Provider. It replaces a naive goroutine-per-symbol fan-out
+// (which just trips an upstream's 429s faster) with a fixed worker pool
+// sharing one token-bucket limiter, so a 500-symbol fill backs off as a
+// whole instead of each goroutine sleeping independently.
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/marketdata"
+	"golang.org/x/time/rate"
+)
+
+// penaltyCooldown is how long a rate-limit penalty (see Fetcher.penalize)
+// stays in effect before the configured RPS is restored.
+const penaltyCooldown = 30 * time.Second
+
+// minPenalizedRPS floors how far repeated 429s can drive the limiter down.
+const minPenalizedRPS = 0.1
+
+// Result is one symbol's fetch outcome.
+type Result struct {
+	Symbol string
+	Bars   []marketdata.Bar
+	Err    error
+}
+
+// Fetcher runs FillAll's worker pool against a single marketdata.Provider.
+type Fetcher struct {
+	Concurrency int
+	RPS         float64
+	Burst       int
+
+	// JobTimeout, if positive, bounds each individual FetchOHLCV call
+	// rather than just the batch as a whole, so one slow ticker can't
+	// occupy a worker (and its FD) indefinitely while the rest of the
+	// batch finishes around it.
+	JobTimeout time.Duration
+
+	mu      sync.Mutex
+	limiter *rate.Limiter
+
+	inFlight int32
+}
+
+// Stats is a point-in-time snapshot of a Fetcher's limiter and worker pool,
+// meant for a caller exposing it over a metrics endpoint rather than for
+// anything FillAll itself consults.
+type Stats struct {
+	RPS         float64 `json:"rps"`
+	Concurrency int     `json:"concurrency"`
+	InFlight    int     `json:"in_flight"`
+}
+
+// Stats returns f's current rate (after any penalize() adjustment) and
+// in-flight fetch count.
+func (f *Fetcher) Stats() Stats {
+	f.mu.Lock()
+	rps := float64(f.limiter.Limit())
+	f.mu.Unlock()
+
+	return Stats{
+		RPS:         rps,
+		Concurrency: f.Concurrency,
+		InFlight:    int(atomic.LoadInt32(&f.inFlight)),
+	}
+}
+
+// New returns a Fetcher whose pool runs at most concurrency fetches at
+// once, each gated by a shared token-bucket limiter seeded at rps with the
+// given burst.
+func New(concurrency int, rps float64, burst int) *Fetcher {
+	return &Fetcher{
+		Concurrency: concurrency,
+		RPS:         rps,
+		Burst:       burst,
+		limiter:     rate.NewLimiter(rate.Limit(rps), burst),
+	}
+}
+
+// SymbolRange is one fetch request within a FillRanges call: symbol's bars
+// for [Start, End]. A caller that needs more than one disjoint window for
+// the same symbol (e.g. an incremental fill's pre-min and post-max gaps)
+// just lists it more than once.
+type SymbolRange struct {
+	Symbol     string
+	Start, End time.Time
+}
+
+// FillAll fetches start..end daily bars for each of symbols from provider.
+// It's a convenience wrapper over FillRanges for the common case where
+// every symbol wants the same window.
+func (f *Fetcher) FillAll(ctx context.Context, provider marketdata.Provider, symbols []string, start, end time.Time) <-chan Result {
+	ranges := make([]SymbolRange, len(symbols))
+	for i, symbol := range symbols {
+		ranges[i] = SymbolRange{Symbol: symbol, Start: start, End: end}
+	}
+	return f.FillRanges(ctx, provider, ranges)
+}
+
+// FillRanges fetches each of ranges' [Start, End] window from provider,
+// streaming one Result per range on the returned channel as it completes
+// (not necessarily in ranges' order, and with more than one Result per
+// symbol if ranges lists that symbol more than once). It honors ctx:
+// canceling it stops scheduling new fetches, unblocks any in-flight
+// rate-limiter wait, and stops pending sends so a disconnected caller can't
+// deadlock the pool. The channel is closed once every range has been
+// attempted or ctx ends the run early.
+func (f *Fetcher) FillRanges(ctx context.Context, provider marketdata.Provider, ranges []SymbolRange) <-chan Result {
+	results := make(chan Result)
+	sem := make(chan struct{}, f.Concurrency)
+
+	go func() {
+		defer close(results)
+
+		var wg sync.WaitGroup
+		for _, r := range ranges {
+			select {
+			case <-ctx.Done():
+				wg.Wait()
+				return
+			case sem <- struct{}{}:
+			}
+
+			wg.Add(1)
+			go func(r SymbolRange) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				f.fetchOne(ctx, provider, r.Symbol, r.Start, r.End, results)
+			}(r)
+		}
+		wg.Wait()
+	}()
+
+	return results
+}
+
+func (f *Fetcher) fetchOne(ctx context.Context, provider marketdata.Provider, symbol string, start, end time.Time, results chan<- Result) {
+	if err := f.limiter.Wait(ctx); err != nil {
+		f.send(ctx, results, Result{Symbol: symbol, Err: err})
+		return
+	}
+
+	jobCtx := ctx
+	if f.JobTimeout > 0 {
+		var cancel context.CancelFunc
+		jobCtx, cancel = context.WithTimeout(ctx, f.JobTimeout)
+		defer cancel()
+	}
+
+	atomic.AddInt32(&f.inFlight, 1)
+	bars, err := fetchOHLCVWithContext(jobCtx, provider, symbol, start, end)
+	atomic.AddInt32(&f.inFlight, -1)
+
+	var rateLimited *marketdata.RateLimitError
+	if errors.As(err, &rateLimited) {
+		f.penalize()
+	}
+
+	f.send(ctx, results, Result{Symbol: symbol, Bars: bars, Err: err})
+}
+
+// fetchOHLCVWithContext runs provider.FetchOHLCV (which takes no context of
+// its own) in a goroutine so JobTimeout/ctx cancellation can bound it from
+// outside. The goroutine is left to finish on its own if ctx ends first
+// (the buffered channel lets it send without blocking), since
+// marketdata.Provider has no way to cancel an in-flight HTTP request.
+func fetchOHLCVWithContext(ctx context.Context, provider marketdata.Provider, symbol string, start, end time.Time) ([]marketdata.Bar, error) {
+	type outcome struct {
+		bars []marketdata.Bar
+		err  error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		bars, err := provider.FetchOHLCV(symbol, start, end, "")
+		done <- outcome{bars, err}
+	}()
+
+	select {
+	case o := <-done:
+		return o.bars, o.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// send delivers result unless ctx ends first, so a worker never blocks
+// forever on a caller that stopped draining results after canceling ctx.
+func (f *Fetcher) send(ctx context.Context, results chan<- Result, result Result) {
+	select {
+	case results <- result:
+	case <-ctx.Done():
+	}
+}
+
+// penalize halves the limiter's current rate (down to minPenalizedRPS) in
+// response to a 429, then restores the configured RPS after
+// penaltyCooldown so one bad burst doesn't cripple the rest of the run.
+func (f *Fetcher) penalize() {
+	f.mu.Lock()
+	reduced := f.limiter.Limit() / 2
+	if reduced < rate.Limit(minPenalizedRPS) {
+		reduced = rate.Limit(minPenalizedRPS)
+	}
+	f.limiter.SetLimit(reduced)
+	f.mu.Unlock()
+
+	time.AfterFunc(penaltyCooldown, func() {
+		f.limiter.SetLimit(rate.Limit(f.RPS))
+	})
+}
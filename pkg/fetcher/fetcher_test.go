@@ -0,0 +1,145 @@
+package fetcher
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/marketdata"
+)
+
+// stubProvider returns a fixed bar per symbol, or an error if the symbol is
+// listed in errs.
+type stubProvider struct {
+	errs map[string]error
+}
+
+func (s stubProvider) Name() string { return "stub" }
+
+func (s stubProvider) FetchOHLCV(ticker string, start, end time.Time, interval string) ([]marketdata.Bar, error) {
+	if err, ok := s.errs[ticker]; ok {
+		return nil, err
+	}
+	return []marketdata.Bar{{Symbol: ticker}}, nil
+}
+
+func TestFillAllFetchesEverySymbol(t *testing.T) {
+	f := New(4, 1000, 1000)
+	provider := stubProvider{}
+	symbols := []string{"AAPL", "MSFT", "GOOG"}
+
+	seen := make(map[string]bool)
+	for r := range f.FillAll(context.Background(), provider, symbols, time.Time{}, time.Time{}) {
+		if r.Err != nil {
+			t.Fatalf("unexpected error for %s: %v", r.Symbol, r.Err)
+		}
+		seen[r.Symbol] = true
+	}
+
+	for _, s := range symbols {
+		if !seen[s] {
+			t.Errorf("missing result for %s", s)
+		}
+	}
+}
+
+func TestFillAllReportsPerSymbolErrors(t *testing.T) {
+	f := New(4, 1000, 1000)
+	provider := stubProvider{errs: map[string]error{"BAD": &marketdata.RateLimitError{Provider: "stub", Ticker: "BAD"}}}
+
+	var gotErr bool
+	for r := range f.FillAll(context.Background(), provider, []string{"AAPL", "BAD"}, time.Time{}, time.Time{}) {
+		if r.Symbol == "BAD" {
+			gotErr = r.Err != nil
+		}
+	}
+	if !gotErr {
+		t.Error("expected a non-nil error for BAD")
+	}
+}
+
+func TestFillAllStopsOnCanceledContext(t *testing.T) {
+	f := New(1, 1000, 1000)
+	provider := stubProvider{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for range f.FillAll(ctx, provider, []string{"AAPL", "MSFT", "GOOG"}, time.Time{}, time.Time{}) {
+		// Draining to completion must not hang even though ctx is already done.
+	}
+}
+
+func TestPenalizeLowersAndRestoresLimit(t *testing.T) {
+	f := New(1, 10, 10)
+	f.penalize()
+
+	if got := f.limiter.Limit(); got != 5 {
+		t.Errorf("limiter.Limit() after penalize = %v, want 5", got)
+	}
+}
+
+func TestFillRangesSupportsMultipleWindowsPerSymbol(t *testing.T) {
+	f := New(4, 1000, 1000)
+	provider := stubProvider{}
+	ranges := []SymbolRange{
+		{Symbol: "AAPL", Start: time.Unix(0, 0), End: time.Unix(100, 0)},
+		{Symbol: "AAPL", Start: time.Unix(200, 0), End: time.Unix(300, 0)},
+	}
+
+	var count int
+	for r := range f.FillRanges(context.Background(), provider, ranges) {
+		if r.Err != nil {
+			t.Fatalf("unexpected error: %v", r.Err)
+		}
+		count++
+	}
+	if count != 2 {
+		t.Errorf("got %d results, want 2 (one per range, same symbol)", count)
+	}
+}
+
+// slowProvider blocks FetchOHLCV until delay has elapsed, to exercise
+// Fetcher.JobTimeout.
+type slowProvider struct {
+	delay time.Duration
+}
+
+func (s slowProvider) Name() string { return "slow" }
+
+func (s slowProvider) FetchOHLCV(ticker string, start, end time.Time, interval string) ([]marketdata.Bar, error) {
+	time.Sleep(s.delay)
+	return []marketdata.Bar{{Symbol: ticker}}, nil
+}
+
+func TestJobTimeoutBoundsASlowFetch(t *testing.T) {
+	f := New(1, 1000, 1000)
+	f.JobTimeout = 10 * time.Millisecond
+	provider := slowProvider{delay: time.Second}
+
+	start := time.Now()
+	var r Result
+	for r = range f.FillAll(context.Background(), provider, []string{"SLOW"}, time.Time{}, time.Time{}) {
+	}
+
+	if r.Err == nil {
+		t.Fatal("expected a timeout error, got nil")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("fetch took %v, want it bounded by JobTimeout well under provider's 1s delay", elapsed)
+	}
+}
+
+func TestStatsReflectsConcurrencyAndPenalize(t *testing.T) {
+	f := New(4, 10, 10)
+
+	stats := f.Stats()
+	if stats.Concurrency != 4 || stats.RPS != 10 || stats.InFlight != 0 {
+		t.Errorf("Stats() = %+v, want {RPS:10 Concurrency:4 InFlight:0}", stats)
+	}
+
+	f.penalize()
+	if got := f.Stats().RPS; got != 5 {
+		t.Errorf("Stats().RPS after penalize = %v, want 5", got)
+	}
+}
@@ -0,0 +1,50 @@
+package fetcher
+
+// fdSafetyMargin is reserved for the DB connection pool, the HTTP server's
+// own listener/accepted connections, and stdio, so RecommendedWorkers
+// never sizes a pool right up against the process's open-file ceiling.
+const fdSafetyMargin = 64
+
+// fallbackMaxOpenFiles is MaxOpenFiles' answer on a platform (or a Getrlimit
+// failure) where the real RLIMIT_NOFILE can't be read.
+const fallbackMaxOpenFiles = 256
+
+// minRecommendedWorkers floors RecommendedWorkers so a very low FD limit
+// still leaves a usable (if small) pool rather than zero workers.
+const minRecommendedWorkers = 2
+
+// maxRecommendedWorkers caps RecommendedWorkers on a system with a very
+// high (or unlimited) RLIMIT_NOFILE: FDs aren't the only constraint on a
+// 500-symbol fan-out, and an upstream provider or this process's own DB
+// writer would choke on thousands of workers just as badly as on an FD
+// exhaustion panic.
+const maxRecommendedWorkers = 64
+
+// RecommendedWorkers returns a worker-pool size derived from MaxOpenFiles:
+// the FD ceiling minus fdSafetyMargin, clamped to [minRecommendedWorkers,
+// maxRecommendedWorkers]. Each in-flight fetch holds roughly one socket,
+// so this keeps a 500-symbol fan-out from exhausting the process's file
+// descriptors alongside the DB pool and the HTTP server's own connections.
+func RecommendedWorkers() int {
+	n := MaxOpenFiles() - fdSafetyMargin
+	if n < minRecommendedWorkers {
+		n = minRecommendedWorkers
+	}
+	if n > maxRecommendedWorkers {
+		n = maxRecommendedWorkers
+	}
+	return n
+}
+
+// maxOpenFilesImpl is platform-specific; see limits_unix.go (a windows
+// build keeps this var's fallbackMaxOpenFiles default, since
+// syscall.Getrlimit/RLIMIT_NOFILE don't exist there).
+var maxOpenFilesImpl = func() int { return fallbackMaxOpenFiles }
+
+// MaxOpenFiles returns the process's current RLIMIT_NOFILE soft limit (the
+// number of file descriptors, including sockets, it may have open at
+// once), or fallbackMaxOpenFiles on a platform without that syscall or if
+// reading it fails.
+func MaxOpenFiles() int {
+	return maxOpenFilesImpl()
+}
@@ -0,0 +1,40 @@
+package fetcher
+
+import "testing"
+
+func TestRecommendedWorkersFloorsOnLowFDLimit(t *testing.T) {
+	orig := maxOpenFilesImpl
+	defer func() { maxOpenFilesImpl = orig }()
+
+	maxOpenFilesImpl = func() int { return 32 }
+	if got := RecommendedWorkers(); got != minRecommendedWorkers {
+		t.Errorf("RecommendedWorkers() = %d, want %d (floor)", got, minRecommendedWorkers)
+	}
+}
+
+func TestRecommendedWorkersCapsOnHighFDLimit(t *testing.T) {
+	orig := maxOpenFilesImpl
+	defer func() { maxOpenFilesImpl = orig }()
+
+	maxOpenFilesImpl = func() int { return 1 << 20 }
+	if got := RecommendedWorkers(); got != maxRecommendedWorkers {
+		t.Errorf("RecommendedWorkers() = %d, want %d (cap)", got, maxRecommendedWorkers)
+	}
+}
+
+func TestRecommendedWorkersSubtractsSafetyMargin(t *testing.T) {
+	orig := maxOpenFilesImpl
+	defer func() { maxOpenFilesImpl = orig }()
+
+	maxOpenFilesImpl = func() int { return 100 }
+	want := 100 - fdSafetyMargin
+	if got := RecommendedWorkers(); got != want {
+		t.Errorf("RecommendedWorkers() = %d, want %d", got, want)
+	}
+}
+
+func TestMaxOpenFilesReturnsPositive(t *testing.T) {
+	if got := MaxOpenFiles(); got <= 0 {
+		t.Errorf("MaxOpenFiles() = %d, want a positive limit", got)
+	}
+}
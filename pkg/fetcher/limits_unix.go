@@ -0,0 +1,24 @@
+//go:build !windows
+
+package fetcher
+
+import "syscall"
+
+func init() {
+	maxOpenFilesImpl = getrlimitMaxOpenFiles
+}
+
+// getrlimitMaxOpenFiles reads RLIMIT_NOFILE's current soft limit via
+// syscall.Getrlimit, falling back to fallbackMaxOpenFiles if the syscall
+// fails (it shouldn't, in practice, but MaxOpenFiles must still return
+// something usable).
+func getrlimitMaxOpenFiles() int {
+	var rlim syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rlim); err != nil {
+		return fallbackMaxOpenFiles
+	}
+	if rlim.Cur <= 0 || rlim.Cur > 1<<31-1 {
+		return fallbackMaxOpenFiles
+	}
+	return int(rlim.Cur)
+}
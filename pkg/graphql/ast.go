@@ -0,0 +1,34 @@
+// Package graphql is a small, dependency-free GraphQL query engine: a
+// tokenizer/parser for a useful subset of the GraphQL query language
+// (field selections, arguments, nested selection sets, list/object
+// argument values) plus an executor that walks a tree of Go resolver
+// functions to answer a parsed query.
+//
+// gqlgen (github.com/99designs/gqlgen) is what this would normally be
+// built on, but it isn't available in this module's dependency cache and
+// there's no network access to fetch it in this environment, so this
+// package implements the same query/resolve/paginate/access-control
+// semantics as original code instead, following the pattern already used
+// elsewhere in this repo (see pkg/providers, pkg/sink) when a suggested
+// third-party package can't be vendored offline.
+//
+// It does not implement the full GraphQL spec: no mutations,
+// subscriptions, fragments, or introspection. It covers exactly what this
+// repo's API surface needs — single-operation queries selecting nested
+// fields with scalar/list/object arguments.
+package graphql
+
+// Document is a parsed query: its single operation's top-level selection
+// set.
+type Document struct {
+	Selections []*Selection
+}
+
+// Selection is one selected field in a query: its name, the arguments it
+// was called with, and (if it selects a nested object or list) its own
+// SelectionSet.
+type Selection struct {
+	Name         string
+	Arguments    map[string]any
+	SelectionSet []*Selection
+}
@@ -0,0 +1,28 @@
+package graphql
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// cursorSeparator joins a cursor's parts (e.g. symbol and date) before
+// base64 encoding. It's chosen to be unlikely to appear in a ticker
+// symbol or an RFC3339 timestamp.
+const cursorSeparator = "\x1f"
+
+// EncodeCursor returns an opaque, base64url-encoded cursor over parts
+// (e.g. EncodeCursor(symbol, date) for a (symbol,date)-keyed connection,
+// or EncodeCursor(id) for an id-keyed one).
+func EncodeCursor(parts ...string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strings.Join(parts, cursorSeparator)))
+}
+
+// DecodeCursor reverses EncodeCursor.
+func DecodeCursor(cursor string) ([]string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("graphql: invalid cursor: %v", err)
+	}
+	return strings.Split(string(raw), cursorSeparator), nil
+}
@@ -0,0 +1,78 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+)
+
+// Execute resolves doc's top-level selections against root (the query
+// root Object) and returns the result tree, ready for JSON encoding. The
+// Viewer on ctx (see WithViewer) gates any field with an Access
+// requirement.
+func Execute(ctx context.Context, root Object, doc *Document) (map[string]any, error) {
+	return executeSelectionSet(ctx, root, doc.Selections)
+}
+
+func executeSelectionSet(ctx context.Context, obj Object, selections []*Selection) (map[string]any, error) {
+	result := make(map[string]any, len(selections))
+
+	for _, sel := range selections {
+		def, ok := obj[sel.Name]
+		if !ok {
+			return nil, fmt.Errorf("graphql: unknown field %q", sel.Name)
+		}
+
+		if !ViewerFromContext(ctx).allows(def.Access) {
+			return nil, fmt.Errorf("graphql: field %q requires authentication%s", sel.Name, scopeSuffix(def.Access))
+		}
+
+		value, err := def.Resolve(ctx, sel.Arguments, sel.SelectionSet)
+		if err != nil {
+			return nil, fmt.Errorf("graphql: field %q: %w", sel.Name, err)
+		}
+
+		resolved, err := resolveSelection(ctx, value, sel.SelectionSet)
+		if err != nil {
+			return nil, fmt.Errorf("graphql: field %q: %w", sel.Name, err)
+		}
+		result[sel.Name] = resolved
+	}
+
+	return result, nil
+}
+
+func scopeSuffix(access *Access) string {
+	if access == nil || access.Scope == "" {
+		return ""
+	}
+	return fmt.Sprintf(" and scope %s", access.Scope)
+}
+
+// resolveSelection applies sel's selection set (if any) to a resolver's
+// return value: an Object is executed against it, a []any of Objects is
+// mapped over, and anything else (scalars, plain maps/slices a resolver
+// built itself) is returned unchanged.
+func resolveSelection(ctx context.Context, value any, sel []*Selection) (any, error) {
+	if len(sel) == 0 {
+		return value, nil
+	}
+
+	switch v := value.(type) {
+	case Object:
+		return executeSelectionSet(ctx, v, sel)
+
+	case []Object:
+		out := make([]any, len(v))
+		for i, item := range v {
+			resolved, err := executeSelectionSet(ctx, item, sel)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = resolved
+		}
+		return out, nil
+
+	default:
+		return value, nil
+	}
+}
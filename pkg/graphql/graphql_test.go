@@ -0,0 +1,192 @@
+package graphql
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestParseSimpleSelection(t *testing.T) {
+	doc, err := Parse(`{ stocks(first: 2, after: "abc") { symbol price } }`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(doc.Selections) != 1 || doc.Selections[0].Name != "stocks" {
+		t.Fatalf("Selections = %+v, want one field named stocks", doc.Selections)
+	}
+
+	stocks := doc.Selections[0]
+	if stocks.Arguments["first"] != int64(2) || stocks.Arguments["after"] != "abc" {
+		t.Errorf("Arguments = %+v, want first=2 after=abc", stocks.Arguments)
+	}
+
+	var names []string
+	for _, f := range stocks.SelectionSet {
+		names = append(names, f.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"symbol", "price"}) {
+		t.Errorf("SelectionSet names = %v, want [symbol price]", names)
+	}
+}
+
+func TestParseListArgument(t *testing.T) {
+	doc, err := Parse(`{ stocks(symbols: ["AAPL", "MSFT"]) { symbol } }`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	symbols, ok := doc.Selections[0].Arguments["symbols"].([]any)
+	if !ok || len(symbols) != 2 || symbols[0] != "AAPL" || symbols[1] != "MSFT" {
+		t.Errorf("symbols argument = %+v, want [AAPL MSFT]", doc.Selections[0].Arguments["symbols"])
+	}
+}
+
+func TestParseQueryKeywordAndOperationName(t *testing.T) {
+	if _, err := Parse(`query GetStocks { stocks { symbol } }`); err != nil {
+		t.Errorf("Parse with query keyword + operation name: %v", err)
+	}
+}
+
+func TestExecuteResolvesNestedObject(t *testing.T) {
+	stockType := Object{
+		"symbol": Field(func(ctx context.Context, args map[string]any, sub []*Selection) (any, error) {
+			return "AAPL", nil
+		}),
+		"price": Field(func(ctx context.Context, args map[string]any, sub []*Selection) (any, error) {
+			return 123.45, nil
+		}),
+	}
+	root := Object{
+		"stock": Field(func(ctx context.Context, args map[string]any, sub []*Selection) (any, error) {
+			return stockType, nil
+		}),
+	}
+
+	doc, err := Parse(`{ stock { symbol price } }`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	result, err := Execute(context.Background(), root, doc)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	stock, ok := result["stock"].(map[string]any)
+	if !ok || stock["symbol"] != "AAPL" || stock["price"] != 123.45 {
+		t.Errorf("result[stock] = %+v, want symbol=AAPL price=123.45", result["stock"])
+	}
+}
+
+func TestExecuteResolvesListOfObjects(t *testing.T) {
+	stockType := func(symbol string) Object {
+		return Object{
+			"symbol": Field(func(ctx context.Context, args map[string]any, sub []*Selection) (any, error) {
+				return symbol, nil
+			}),
+		}
+	}
+	root := Object{
+		"stocks": Field(func(ctx context.Context, args map[string]any, sub []*Selection) (any, error) {
+			return []Object{stockType("AAPL"), stockType("MSFT")}, nil
+		}),
+	}
+
+	doc, err := Parse(`{ stocks { symbol } }`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	result, err := Execute(context.Background(), root, doc)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	stocks, ok := result["stocks"].([]any)
+	if !ok || len(stocks) != 2 {
+		t.Fatalf("result[stocks] = %+v, want a 2-element list", result["stocks"])
+	}
+	if stocks[0].(map[string]any)["symbol"] != "AAPL" || stocks[1].(map[string]any)["symbol"] != "MSFT" {
+		t.Errorf("result[stocks] = %+v, want AAPL then MSFT", stocks)
+	}
+}
+
+func TestExecuteDeniesUnauthenticatedAccessField(t *testing.T) {
+	root := Object{
+		"emails": RestrictedField(&Access{Scope: ScopeEmails, Kind: KindRO}, func(ctx context.Context, args map[string]any, sub []*Selection) (any, error) {
+			return []Object{}, nil
+		}),
+	}
+	doc, err := Parse(`{ emails { subject } }`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	if _, err := Execute(context.Background(), root, doc); err == nil {
+		t.Error("Execute with no Viewer on context = nil error, want access denied")
+	}
+
+	authed := WithViewer(context.Background(), Viewer{Authenticated: true, Scopes: map[AccessScope]bool{ScopeEmails: true}})
+	if _, err := Execute(authed, root, doc); err != nil {
+		t.Errorf("Execute with authorized Viewer = %v, want nil", err)
+	}
+}
+
+func TestExecutePrivateFieldRequiresAuthOnly(t *testing.T) {
+	root := Object{
+		"job": RestrictedField(Private, func(ctx context.Context, args map[string]any, sub []*Selection) (any, error) {
+			return "running", nil
+		}),
+	}
+	doc, err := Parse(`{ job }`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	if _, err := Execute(context.Background(), root, doc); err == nil {
+		t.Error("Execute(unauthenticated) = nil error, want access denied for @private field")
+	}
+
+	authed := WithViewer(context.Background(), Viewer{Authenticated: true})
+	if _, err := Execute(authed, root, doc); err != nil {
+		t.Errorf("Execute(authenticated, no scope) = %v, want nil for @private field", err)
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursor := EncodeCursor("AAPL", "2024-01-02")
+	parts, err := DecodeCursor(cursor)
+	if err != nil {
+		t.Fatalf("DecodeCursor: %v", err)
+	}
+	if !reflect.DeepEqual(parts, []string{"AAPL", "2024-01-02"}) {
+		t.Errorf("DecodeCursor(%q) = %v, want [AAPL 2024-01-02]", cursor, parts)
+	}
+}
+
+func TestLoaderBatchesOncePerKeySet(t *testing.T) {
+	calls := 0
+	loader := NewLoader([]string{"AAPL", "MSFT"}, func(ctx context.Context, keys []string) (map[string]int, error) {
+		calls++
+		out := make(map[string]int, len(keys))
+		for i, k := range keys {
+			out[k] = i
+		}
+		return out, nil
+	})
+
+	v1, err := loader.Load(context.Background(), "AAPL")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	v2, err := loader.Load(context.Background(), "MSFT")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if calls != 1 {
+		t.Errorf("batchFn called %d times, want exactly 1", calls)
+	}
+	if v1 != 0 || v2 != 1 {
+		t.Errorf("Load results = %d, %d, want 0, 1", v1, v2)
+	}
+}
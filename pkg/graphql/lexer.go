@@ -0,0 +1,171 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenName
+	tokenString
+	tokenInt
+	tokenFloat
+	tokenPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// lexer tokenizes a GraphQL query document. It understands names,
+// quoted strings, integers/floats, and the punctuation this package's
+// grammar needs ({ } ( ) [ ] : ,).
+type lexer struct {
+	src []rune
+	pos int
+}
+
+func newLexer(src string) *lexer {
+	return &lexer{src: []rune(src)}
+}
+
+func (l *lexer) peekRune() (rune, bool) {
+	if l.pos >= len(l.src) {
+		return 0, false
+	}
+	return l.src[l.pos], true
+}
+
+func (l *lexer) skipIgnored() {
+	for {
+		r, ok := l.peekRune()
+		if !ok {
+			return
+		}
+		if unicode.IsSpace(r) || r == ',' {
+			l.pos++
+			continue
+		}
+		if r == '#' {
+			for {
+				r, ok := l.peekRune()
+				if !ok || r == '\n' {
+					break
+				}
+				l.pos++
+			}
+			continue
+		}
+		return
+	}
+}
+
+func isNameStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isNameCont(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// next returns the next token in the stream, or a tokenEOF token once the
+// input is exhausted.
+func (l *lexer) next() (token, error) {
+	l.skipIgnored()
+
+	r, ok := l.peekRune()
+	if !ok {
+		return token{kind: tokenEOF}, nil
+	}
+
+	switch {
+	case isNameStart(r):
+		start := l.pos
+		for {
+			r, ok := l.peekRune()
+			if !ok || !isNameCont(r) {
+				break
+			}
+			l.pos++
+		}
+		return token{kind: tokenName, text: string(l.src[start:l.pos])}, nil
+
+	case r == '"':
+		return l.lexString()
+
+	case r == '-' || unicode.IsDigit(r):
+		return l.lexNumber()
+
+	case strings.ContainsRune("{}()[]:", r):
+		l.pos++
+		return token{kind: tokenPunct, text: string(r)}, nil
+	}
+
+	return token{}, fmt.Errorf("graphql: unexpected character %q", r)
+}
+
+func (l *lexer) lexString() (token, error) {
+	l.pos++ // opening quote
+	var sb strings.Builder
+	for {
+		r, ok := l.peekRune()
+		if !ok {
+			return token{}, fmt.Errorf("graphql: unterminated string")
+		}
+		l.pos++
+		if r == '"' {
+			return token{kind: tokenString, text: sb.String()}, nil
+		}
+		if r == '\\' {
+			escaped, ok := l.peekRune()
+			if !ok {
+				return token{}, fmt.Errorf("graphql: unterminated string escape")
+			}
+			l.pos++
+			sb.WriteRune(escaped)
+			continue
+		}
+		sb.WriteRune(r)
+	}
+}
+
+func (l *lexer) lexNumber() (token, error) {
+	start := l.pos
+	if r, ok := l.peekRune(); ok && r == '-' {
+		l.pos++
+	}
+	isFloat := false
+	for {
+		r, ok := l.peekRune()
+		if !ok {
+			break
+		}
+		if unicode.IsDigit(r) {
+			l.pos++
+			continue
+		}
+		if r == '.' && !isFloat {
+			isFloat = true
+			l.pos++
+			continue
+		}
+		break
+	}
+	text := string(l.src[start:l.pos])
+	if isFloat {
+		if _, err := strconv.ParseFloat(text, 64); err != nil {
+			return token{}, fmt.Errorf("graphql: invalid number %q: %v", text, err)
+		}
+		return token{kind: tokenFloat, text: text}, nil
+	}
+	if _, err := strconv.ParseInt(text, 10, 64); err != nil {
+		return token{}, fmt.Errorf("graphql: invalid number %q: %v", text, err)
+	}
+	return token{kind: tokenInt, text: text}, nil
+}
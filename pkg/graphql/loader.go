@@ -0,0 +1,48 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+)
+
+// Loader batches one kind of per-key lookup (e.g. historical bars per
+// stock symbol) into a single batchFn call, the way a dataloader avoids
+// N+1 queries: a resolver list (e.g. the stocks connection) constructs a
+// Loader over every key it's about to hand out, and each child resolver
+// (e.g. a Stock's historicalBars field) calls Load for its own key; only
+// the first Load call actually runs batchFn, and it runs it once for
+// every key the parent already knew about.
+//
+// Unlike a classic dataloader, Load is not async/event-loop-batched —
+// this package's Execute resolves fields synchronously — so a Loader only
+// pays off when its full key set is known before any Load call, which is
+// true for every N+1 join this schema has (a connection resolver always
+// knows the full page of parent keys before a child field is resolved).
+type Loader[K comparable, V any] struct {
+	keys    []K
+	batchFn func(ctx context.Context, keys []K) (map[K]V, error)
+
+	once   sync.Once
+	result map[K]V
+	err    error
+}
+
+// NewLoader returns a Loader that will batch-fetch every key in keys (via
+// batchFn) the first time Load is called for any of them.
+func NewLoader[K comparable, V any](keys []K, batchFn func(ctx context.Context, keys []K) (map[K]V, error)) *Loader[K, V] {
+	return &Loader[K, V]{keys: keys, batchFn: batchFn}
+}
+
+// Load returns the value batchFn produced for key, running batchFn (once,
+// across every key the Loader was constructed with) on the first call.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	l.once.Do(func() {
+		l.result, l.err = l.batchFn(ctx, l.keys)
+	})
+
+	var zero V
+	if l.err != nil {
+		return zero, l.err
+	}
+	return l.result[key], nil
+}
@@ -0,0 +1,182 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// parser turns a lexer's token stream into a Document. It buffers one
+// token of lookahead.
+type parser struct {
+	lex *lexer
+	tok token
+}
+
+// Parse parses src, a GraphQL query document, optionally preceded by the
+// "query" keyword and an operation name (both are accepted and ignored,
+// since this package only ever executes a single operation).
+func Parse(src string) (*Document, error) {
+	p := &parser{lex: newLexer(src)}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	if p.tok.kind == tokenName && p.tok.text == "query" {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if p.tok.kind == tokenName {
+			if err := p.advance(); err != nil { // operation name
+				return nil, err
+			}
+		}
+	}
+
+	selections, err := p.parseSelectionSet()
+	if err != nil {
+		return nil, err
+	}
+	return &Document{Selections: selections}, nil
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) expectPunct(text string) error {
+	if p.tok.kind != tokenPunct || p.tok.text != text {
+		return fmt.Errorf("graphql: expected %q, got %q", text, p.tok.text)
+	}
+	return p.advance()
+}
+
+// parseSelectionSet parses a brace-delimited list of fields, e.g.
+// "{ stocks(first: 10) { symbol price } }".
+func (p *parser) parseSelectionSet() ([]*Selection, error) {
+	if err := p.expectPunct("{"); err != nil {
+		return nil, err
+	}
+
+	var fields []*Selection
+	for !(p.tok.kind == tokenPunct && p.tok.text == "}") {
+		if p.tok.kind != tokenName {
+			return nil, fmt.Errorf("graphql: expected a field name, got %q", p.tok.text)
+		}
+
+		field := &Selection{Name: p.tok.text}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+
+		if p.tok.kind == tokenPunct && p.tok.text == "(" {
+			args, err := p.parseArguments()
+			if err != nil {
+				return nil, err
+			}
+			field.Arguments = args
+		}
+
+		if p.tok.kind == tokenPunct && p.tok.text == "{" {
+			sub, err := p.parseSelectionSet()
+			if err != nil {
+				return nil, err
+			}
+			field.SelectionSet = sub
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields, p.expectPunct("}")
+}
+
+func (p *parser) parseArguments() (map[string]any, error) {
+	if err := p.expectPunct("("); err != nil {
+		return nil, err
+	}
+
+	args := make(map[string]any)
+	for !(p.tok.kind == tokenPunct && p.tok.text == ")") {
+		if p.tok.kind != tokenName {
+			return nil, fmt.Errorf("graphql: expected an argument name, got %q", p.tok.text)
+		}
+		name := p.tok.text
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if err := p.expectPunct(":"); err != nil {
+			return nil, err
+		}
+
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		args[name] = value
+	}
+
+	return args, p.expectPunct(")")
+}
+
+func (p *parser) parseValue() (any, error) {
+	switch {
+	case p.tok.kind == tokenString:
+		v := p.tok.text
+		return v, p.advance()
+
+	case p.tok.kind == tokenInt:
+		n, err := strconv.ParseInt(p.tok.text, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return n, p.advance()
+
+	case p.tok.kind == tokenFloat:
+		f, err := strconv.ParseFloat(p.tok.text, 64)
+		if err != nil {
+			return nil, err
+		}
+		return f, p.advance()
+
+	case p.tok.kind == tokenName && (p.tok.text == "true" || p.tok.text == "false"):
+		v := p.tok.text == "true"
+		return v, p.advance()
+
+	case p.tok.kind == tokenName && p.tok.text == "null":
+		return nil, p.advance()
+
+	case p.tok.kind == tokenName:
+		// Bare identifiers (enum values like STOCKS/RO) are passed through
+		// as plain strings — this package has no enum type declarations to
+		// validate them against.
+		v := p.tok.text
+		return v, p.advance()
+
+	case p.tok.kind == tokenPunct && p.tok.text == "[":
+		return p.parseList()
+	}
+
+	return nil, fmt.Errorf("graphql: unexpected token %q in value position", p.tok.text)
+}
+
+func (p *parser) parseList() (any, error) {
+	if err := p.expectPunct("["); err != nil {
+		return nil, err
+	}
+
+	var values []any
+	for !(p.tok.kind == tokenPunct && p.tok.text == "]") {
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+
+	return values, p.expectPunct("]")
+}
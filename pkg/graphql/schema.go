@@ -0,0 +1,68 @@
+package graphql
+
+import "context"
+
+// AccessScope names the resource family a @access-style field restriction
+// applies to (the request's "analogous to the sourcehut pattern" access
+// directive).
+type AccessScope string
+
+const (
+	ScopeStocks    AccessScope = "STOCKS"
+	ScopeEmails    AccessScope = "EMAILS"
+	ScopeBacktests AccessScope = "BACKTESTS"
+)
+
+// AccessKind is RO or RW, mirroring a field's read/write intent. Every
+// field in this schema is read-only today; RW is carried through so a
+// future mutation type has somewhere to declare write access.
+type AccessKind string
+
+const (
+	KindRO AccessKind = "RO"
+	KindRW AccessKind = "RW"
+)
+
+// Access is the equivalent of a schema's `@access(scope: ..., kind: ...)`
+// directive: Execute rejects the field unless Viewer.Authenticated (see
+// Viewer) and, when Scope is non-empty, Viewer.Scopes contains it.
+type Access struct {
+	Scope AccessScope
+	Kind  AccessKind
+}
+
+// Private marks a field as the equivalent of `@private`: resolvable only
+// for an authenticated viewer, with no specific scope required.
+var Private = &Access{}
+
+// Resolver is a field's resolve function. ctx carries the Viewer (see
+// WithViewer); args are the query's arguments for this field, already
+// parsed into Go values by Parse; sub is the field's own selection set,
+// for resolvers (like paginated connections) that need to know which
+// nested fields were asked for before doing expensive work.
+//
+// Its result is one of: a scalar (string/int/float/bool/nil), an Object,
+// or a []any of Objects — anything else is returned as-is to the caller.
+type Resolver func(ctx context.Context, args map[string]any, sub []*Selection) (any, error)
+
+// FieldDef is one field's resolver plus its optional access requirement.
+type FieldDef struct {
+	Resolve Resolver
+	Access  *Access
+}
+
+// Object is a selectable GraphQL object type: its field name to FieldDef
+// mapping. The root query type, and every nested type this schema
+// exposes (Stock, HistoricalBar, ...), is one of these.
+type Object map[string]FieldDef
+
+// Field is a convenience constructor for an unrestricted FieldDef.
+func Field(resolve Resolver) FieldDef {
+	return FieldDef{Resolve: resolve}
+}
+
+// RestrictedField is a convenience constructor for a FieldDef requiring
+// access.
+func RestrictedField(access *Access, resolve Resolver) FieldDef {
+	return FieldDef{Resolve: resolve, Access: access}
+}
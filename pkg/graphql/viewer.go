@@ -0,0 +1,41 @@
+package graphql
+
+import "context"
+
+// Viewer is the caller identity Execute checks @access/@private fields
+// against. It's populated by whatever middleware sits in front of the
+// /query handler reading the OAuth session (see the main package's
+// graphql.go), not by this package.
+type Viewer struct {
+	Authenticated bool
+	Scopes        map[AccessScope]bool
+}
+
+type viewerContextKey struct{}
+
+// WithViewer returns a context carrying viewer, for Execute to read back
+// via ViewerFromContext.
+func WithViewer(ctx context.Context, viewer Viewer) context.Context {
+	return context.WithValue(ctx, viewerContextKey{}, viewer)
+}
+
+// ViewerFromContext returns the Viewer WithViewer stored on ctx, or the
+// zero Viewer (unauthenticated, no scopes) if none was set.
+func ViewerFromContext(ctx context.Context) Viewer {
+	v, _ := ctx.Value(viewerContextKey{}).(Viewer)
+	return v
+}
+
+// allows reports whether viewer satisfies access.
+func (v Viewer) allows(access *Access) bool {
+	if access == nil {
+		return true
+	}
+	if !v.Authenticated {
+		return false
+	}
+	if access.Scope == "" {
+		return true
+	}
+	return v.Scopes[access.Scope]
+}
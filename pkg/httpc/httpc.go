@@ -0,0 +1,135 @@
+// Package httpc tracks an upstream's advertised rate limit across calls so
+// concurrent fetchers (the S&P 500 list fetch, the Yahoo price
+// downloaders) can self-throttle instead of running headlong into a ban.
+// State is package-level and shared by every caller, since it reflects
+// what the upstream itself is telling every goroutine at once, not
+// anything scoped to one request.
+package httpc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/types"
+)
+
+// rateLimit is the most recently observed X-RateLimit-Remaining value, or
+// -1 if no upstream response has reported one yet.
+var rateLimit atomic.Int64
+
+// rateLimitResetAt is the unix-seconds timestamp the current rate-limit
+// window resets at, or 0 if unknown.
+var rateLimitResetAt atomic.Int64
+
+func init() {
+	rateLimit.Store(-1)
+}
+
+// RecordHeaders updates the package-level rate-limit state from resp's
+// X-RateLimit-Remaining and X-RateLimit-Reset headers, falling back to
+// Retry-After for the reset time when X-RateLimit-Reset is absent. It's a
+// no-op for any header that isn't present or doesn't parse.
+func RecordHeaders(resp *http.Response) {
+	if raw := resp.Header.Get("X-RateLimit-Remaining"); raw != "" {
+		if n, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			rateLimit.Store(n)
+		}
+	}
+
+	if raw := resp.Header.Get("X-RateLimit-Reset"); raw != "" {
+		if n, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			rateLimitResetAt.Store(n)
+		}
+		return
+	}
+
+	if raw := resp.Header.Get("Retry-After"); raw != "" {
+		if secs, err := strconv.Atoi(raw); err == nil {
+			rateLimitResetAt.Store(time.Now().Add(time.Duration(secs) * time.Second).Unix())
+		}
+	}
+}
+
+// GetRateLimit returns the most recently observed X-RateLimit-Remaining
+// value across every caller in this process, or -1 if none has been
+// reported yet.
+func GetRateLimit() int64 {
+	return rateLimit.Load()
+}
+
+// GetRateLimitResetAt returns when the current rate-limit window resets,
+// per the most recently observed X-RateLimit-Reset or Retry-After header.
+// The zero Time means no upstream has reported one yet.
+func GetRateLimitResetAt() time.Time {
+	secs := rateLimitResetAt.Load()
+	if secs == 0 {
+		return time.Time{}
+	}
+	return time.Unix(secs, 0)
+}
+
+// WaitIfLimited blocks until GetRateLimitResetAt has passed if the last
+// recorded GetRateLimit was exhausted (<= 0), so a caller about to make
+// another request backs off instead of piling onto an upstream that's
+// already rate-limiting this process. It returns ctx.Err() if ctx is
+// canceled first, and is a no-op if no exhausted limit has been recorded.
+func WaitIfLimited(ctx context.Context) error {
+	if rateLimit.Load() > 0 {
+		return nil
+	}
+
+	resetAt := GetRateLimitResetAt()
+	if resetAt.IsZero() {
+		return nil
+	}
+
+	d := time.Until(resetAt)
+	if d <= 0 {
+		return nil
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Get issues a GET to url via client (a types.RetryableClient, so 429/5xx
+// responses already get exponential backoff with jitter and an honored
+// Retry-After header — see pkg/types.RetryableClient), waiting out any
+// still-active rate-limit window first and recording the response's
+// rate-limit headers before decode runs against the body.
+func Get[T any](ctx context.Context, client *types.RetryableClient, url string, decode func(*http.Response) (T, error)) (T, error) {
+	var zero T
+
+	if err := WaitIfLimited(ctx); err != nil {
+		return zero, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return zero, fmt.Errorf("httpc: failed to create request for %s: %w", url, err)
+	}
+
+	resp, _, err := client.Do(req)
+	if err != nil {
+		return zero, err
+	}
+	defer resp.Body.Close()
+
+	RecordHeaders(resp)
+
+	if resp.StatusCode != http.StatusOK {
+		return zero, fmt.Errorf("httpc: %s returned status %d: %w", url, resp.StatusCode, types.ClassifyHTTPStatus(resp.StatusCode))
+	}
+
+	return decode(resp)
+}
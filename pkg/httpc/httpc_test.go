@@ -0,0 +1,57 @@
+package httpc
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRecordHeadersUpdatesRateLimit(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rec.Header().Set("X-RateLimit-Remaining", "5")
+	rec.Header().Set("X-RateLimit-Reset", "9999999999")
+	resp := rec.Result()
+
+	RecordHeaders(resp)
+
+	if got := GetRateLimit(); got != 5 {
+		t.Errorf("GetRateLimit() = %d, want 5", got)
+	}
+	if got := GetRateLimitResetAt(); got.Unix() != 9999999999 {
+		t.Errorf("GetRateLimitResetAt() = %v, want unix 9999999999", got)
+	}
+}
+
+func TestRecordHeadersFallsBackToRetryAfter(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rec.Header().Set("X-RateLimit-Remaining", "0")
+	rec.Header().Set("Retry-After", "30")
+	resp := rec.Result()
+
+	before := time.Now()
+	RecordHeaders(resp)
+
+	resetAt := GetRateLimitResetAt()
+	if resetAt.Before(before.Add(29 * time.Second)) {
+		t.Errorf("GetRateLimitResetAt() = %v, want roughly 30s from now", resetAt)
+	}
+}
+
+func TestWaitIfLimitedReturnsImmediatelyWhenNotLimited(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rec.Header().Set("X-RateLimit-Remaining", "10")
+	RecordHeaders(rec.Result())
+
+	done := make(chan error, 1)
+	go func() { done <- WaitIfLimited(context.Background()) }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("WaitIfLimited returned error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("WaitIfLimited blocked despite remaining > 0")
+	}
+}
@@ -0,0 +1,94 @@
+package marketdata
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/types"
+)
+
+// AlphaVantageProvider fetches daily adjusted bars from Alpha Vantage.
+type AlphaVantageProvider struct {
+	apiKey string
+	client *http.Client
+}
+
+// NewAlphaVantageProvider returns an AlphaVantageProvider using apiKey.
+func NewAlphaVantageProvider(apiKey string) *AlphaVantageProvider {
+	return &AlphaVantageProvider{
+		apiKey: apiKey,
+		client: &http.Client{Timeout: 20 * time.Second},
+	}
+}
+
+func (p *AlphaVantageProvider) Name() string { return "alphavantage" }
+
+// FetchOHLCV ignores interval and always returns daily adjusted bars within
+// [start, end].
+func (p *AlphaVantageProvider) FetchOHLCV(ticker string, start, end time.Time, interval string) ([]Bar, error) {
+	if p.apiKey == "" {
+		return nil, fmt.Errorf("marketdata: alphavantage: no API key configured")
+	}
+
+	url := fmt.Sprintf(
+		"https://www.alphavantage.co/query?function=TIME_SERIES_DAILY_ADJUSTED&symbol=%s&outputsize=full&apikey=%s",
+		ticker, p.apiKey,
+	)
+
+	resp, err := p.client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("marketdata: alphavantage: failed to fetch %s: %v", ticker, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("marketdata: alphavantage: %w for %s", types.ClassifyHTTPStatus(resp.StatusCode), ticker)
+	}
+
+	var avResp struct {
+		Information  string                       `json:"Information"`
+		Note         string                       `json:"Note"`
+		ErrorMessage string                       `json:"Error Message"`
+		Series       map[string]map[string]string `json:"Time Series (Daily)"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&avResp); err != nil {
+		return nil, fmt.Errorf("marketdata: alphavantage: %w for %s: %v", types.ErrParse, ticker, err)
+	}
+
+	// A throttled or invalid-key request comes back as 200 with
+	// Information/Note/Error Message instead of a series, so it must be
+	// treated as a failure for the chain to fall back correctly.
+	if avResp.Information != "" {
+		return nil, fmt.Errorf("marketdata: alphavantage: %s", avResp.Information)
+	}
+	if avResp.Note != "" {
+		return nil, fmt.Errorf("marketdata: alphavantage: %s", avResp.Note)
+	}
+	if avResp.ErrorMessage != "" {
+		return nil, fmt.Errorf("marketdata: alphavantage: %s", avResp.ErrorMessage)
+	}
+
+	var bars []Bar
+	for dateStr, day := range avResp.Series {
+		date, err := time.Parse("2006-01-02", dateStr)
+		if err != nil || date.Before(start) || date.After(end) {
+			continue
+		}
+
+		bar := Bar{Symbol: ticker, Date: date, Source: p.Name()}
+		bar.Open, _ = strconv.ParseFloat(day["1. open"], 64)
+		bar.High, _ = strconv.ParseFloat(day["2. high"], 64)
+		bar.Low, _ = strconv.ParseFloat(day["3. low"], 64)
+		bar.Close, _ = strconv.ParseFloat(day["4. close"], 64)
+		bar.AdjClose, _ = strconv.ParseFloat(day["5. adjusted close"], 64)
+		volume, _ := strconv.ParseInt(day["6. volume"], 10, 64)
+		bar.Volume = volume
+
+		bars = append(bars, bar)
+	}
+
+	return bars, nil
+}
@@ -0,0 +1,78 @@
+package marketdata
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/types"
+)
+
+// FetchQuote fetches a current-price snapshot from Alpha Vantage's
+// GLOBAL_QUOTE function.
+func (p *AlphaVantageProvider) FetchQuote(ticker string) (Quote, error) {
+	if p.apiKey == "" {
+		return Quote{}, fmt.Errorf("marketdata: alphavantage: no API key configured")
+	}
+
+	url := fmt.Sprintf(
+		"https://www.alphavantage.co/query?function=GLOBAL_QUOTE&symbol=%s&apikey=%s",
+		ticker, p.apiKey,
+	)
+
+	resp, err := p.client.Get(url)
+	if err != nil {
+		return Quote{}, fmt.Errorf("marketdata: alphavantage: failed to fetch quote for %s: %v", ticker, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Quote{}, fmt.Errorf("marketdata: alphavantage: %w for %s", types.ClassifyHTTPStatus(resp.StatusCode), ticker)
+	}
+
+	var avResp struct {
+		Information  string            `json:"Information"`
+		Note         string            `json:"Note"`
+		ErrorMessage string            `json:"Error Message"`
+		GlobalQuote  map[string]string `json:"Global Quote"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&avResp); err != nil {
+		return Quote{}, fmt.Errorf("marketdata: alphavantage: %w for %s: %v", types.ErrParse, ticker, err)
+	}
+
+	// Same throttled/invalid-key-as-200 behavior FetchOHLCV guards against.
+	if avResp.Information != "" {
+		return Quote{}, fmt.Errorf("marketdata: alphavantage: %s", avResp.Information)
+	}
+	if avResp.Note != "" {
+		return Quote{}, fmt.Errorf("marketdata: alphavantage: %s", avResp.Note)
+	}
+	if avResp.ErrorMessage != "" {
+		return Quote{}, fmt.Errorf("marketdata: alphavantage: %s", avResp.ErrorMessage)
+	}
+	if len(avResp.GlobalQuote) == 0 {
+		return Quote{}, fmt.Errorf("marketdata: alphavantage: %w for %s", types.ErrNoData, ticker)
+	}
+
+	q := avResp.GlobalQuote
+	price, _ := strconv.ParseFloat(q["05. price"], 64)
+	prevClose, _ := strconv.ParseFloat(q["08. previous close"], 64)
+	open, _ := strconv.ParseFloat(q["02. open"], 64)
+	high, _ := strconv.ParseFloat(q["03. high"], 64)
+	low, _ := strconv.ParseFloat(q["04. low"], 64)
+	volume, _ := strconv.ParseInt(q["06. volume"], 10, 64)
+
+	return Quote{
+		Symbol:        ticker,
+		Price:         price,
+		PreviousClose: prevClose,
+		Open:          open,
+		High:          high,
+		Low:           low,
+		Volume:        volume,
+		UpdatedAt:     time.Now(),
+		Source:        p.Name(),
+	}, nil
+}
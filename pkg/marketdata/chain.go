@@ -0,0 +1,43 @@
+package marketdata
+
+import "time"
+
+// ChainedProvider tries each of Providers in order, falling back to the
+// next one when a provider errors or returns an empty series — an
+// upstream's auth failure, rate limit, or a delisted ticker all surface as
+// one of those two cases rather than needing special-casing here.
+type ChainedProvider struct {
+	Providers []Provider
+}
+
+func (c ChainedProvider) Name() string { return "chain" }
+
+func (c ChainedProvider) FetchOHLCV(ticker string, start, end time.Time, interval string) ([]Bar, error) {
+	var lastErr error
+	for _, p := range c.Providers {
+		bars, err := p.FetchOHLCV(ticker, start, end, interval)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if len(bars) == 0 {
+			lastErr = &emptySeriesError{provider: p.Name(), ticker: ticker}
+			continue
+		}
+		return bars, nil
+	}
+
+	if lastErr == nil {
+		lastErr = &emptySeriesError{provider: "chain", ticker: ticker}
+	}
+	return nil, lastErr
+}
+
+type emptySeriesError struct {
+	provider string
+	ticker   string
+}
+
+func (e *emptySeriesError) Error() string {
+	return "marketdata: " + e.provider + ": no data returned for " + e.ticker
+}
@@ -0,0 +1,16 @@
+package marketdata
+
+import "fmt"
+
+// RateLimitError indicates a provider's upstream rejected a request for
+// exceeding its rate limit (HTTP 429), once any in-provider retries are
+// exhausted. pkg/fetcher watches for this specifically so it can back its
+// own limiter off instead of a caller sleeping a fixed duration.
+type RateLimitError struct {
+	Provider string
+	Ticker   string
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("marketdata: %s: rate limited fetching %s", e.Provider, e.Ticker)
+}
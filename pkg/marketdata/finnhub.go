@@ -0,0 +1,96 @@
+package marketdata
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/types"
+)
+
+// FinnhubProvider fetches daily candles from Finnhub's /stock/candle
+// endpoint.
+type FinnhubProvider struct {
+	apiKey string
+	client *http.Client
+}
+
+// NewFinnhubProvider returns a FinnhubProvider using apiKey.
+func NewFinnhubProvider(apiKey string) *FinnhubProvider {
+	return &FinnhubProvider{
+		apiKey: apiKey,
+		client: &http.Client{Timeout: 20 * time.Second},
+	}
+}
+
+func (p *FinnhubProvider) Name() string { return "finnhub" }
+
+// finnhubCandles mirrors Finnhub's column-oriented /stock/candle response:
+// parallel arrays indexed by position, plus a status code in "s" ("ok" or
+// "no_data").
+type finnhubCandles struct {
+	Open   []float64 `json:"o"`
+	High   []float64 `json:"h"`
+	Low    []float64 `json:"l"`
+	Close  []float64 `json:"c"`
+	Volume []float64 `json:"v"`
+	Time   []int64   `json:"t"`
+	Status string    `json:"s"`
+}
+
+// FetchOHLCV ignores interval and returns daily bars for [start, end].
+// Finnhub's free tier doesn't adjust close for splits/dividends, so
+// AdjClose mirrors Close.
+func (p *FinnhubProvider) FetchOHLCV(ticker string, start, end time.Time, interval string) ([]Bar, error) {
+	if p.apiKey == "" {
+		return nil, fmt.Errorf("marketdata: finnhub: no API key configured")
+	}
+
+	url := fmt.Sprintf(
+		"https://finnhub.io/api/v1/stock/candle?symbol=%s&resolution=D&from=%d&to=%d&token=%s",
+		ticker, start.Unix(), end.Unix(), p.apiKey,
+	)
+
+	resp, err := p.client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("marketdata: finnhub: failed to fetch %s: %v", ticker, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, &RateLimitError{Provider: p.Name(), Ticker: ticker}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("marketdata: finnhub: %w for %s", types.ClassifyHTTPStatus(resp.StatusCode), ticker)
+	}
+
+	var candles finnhubCandles
+	if err := json.NewDecoder(resp.Body).Decode(&candles); err != nil {
+		return nil, fmt.Errorf("marketdata: finnhub: %w for %s: %v", types.ErrParse, ticker, err)
+	}
+	if candles.Status != "ok" {
+		return nil, nil
+	}
+
+	bars := make([]Bar, 0, len(candles.Time))
+	for i, ts := range candles.Time {
+		if i >= len(candles.Open) || i >= len(candles.High) || i >= len(candles.Low) ||
+			i >= len(candles.Close) || i >= len(candles.Volume) {
+			continue
+		}
+		bars = append(bars, Bar{
+			Symbol:   ticker,
+			Date:     time.Unix(ts, 0),
+			Open:     candles.Open[i],
+			High:     candles.High[i],
+			Low:      candles.Low[i],
+			Close:    candles.Close[i],
+			AdjClose: candles.Close[i],
+			Volume:   int64(candles.Volume[i]),
+			Source:   p.Name(),
+		})
+	}
+
+	return bars, nil
+}
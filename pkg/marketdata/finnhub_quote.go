@@ -0,0 +1,60 @@
+package marketdata
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/types"
+)
+
+// FetchQuote fetches a current-price snapshot from Finnhub's /quote
+// endpoint.
+func (p *FinnhubProvider) FetchQuote(ticker string) (Quote, error) {
+	if p.apiKey == "" {
+		return Quote{}, fmt.Errorf("marketdata: finnhub: no API key configured")
+	}
+
+	url := fmt.Sprintf("https://finnhub.io/api/v1/quote?symbol=%s&token=%s", ticker, p.apiKey)
+
+	resp, err := p.client.Get(url)
+	if err != nil {
+		return Quote{}, fmt.Errorf("marketdata: finnhub: failed to fetch quote for %s: %v", ticker, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return Quote{}, &RateLimitError{Provider: p.Name(), Ticker: ticker}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Quote{}, fmt.Errorf("marketdata: finnhub: %w for %s", types.ClassifyHTTPStatus(resp.StatusCode), ticker)
+	}
+
+	var q struct {
+		Current       float64 `json:"c"`
+		High          float64 `json:"h"`
+		Low           float64 `json:"l"`
+		Open          float64 `json:"o"`
+		PreviousClose float64 `json:"pc"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&q); err != nil {
+		return Quote{}, fmt.Errorf("marketdata: finnhub: %w for %s: %v", types.ErrParse, ticker, err)
+	}
+	// Finnhub's free tier returns all-zero fields (rather than an error
+	// status) for an unknown symbol.
+	if q.Current == 0 {
+		return Quote{}, fmt.Errorf("marketdata: finnhub: %w for %s", types.ErrNoData, ticker)
+	}
+
+	return Quote{
+		Symbol:        ticker,
+		Price:         q.Current,
+		PreviousClose: q.PreviousClose,
+		Open:          q.Open,
+		High:          q.High,
+		Low:           q.Low,
+		UpdatedAt:     time.Now(),
+		Source:        p.Name(),
+	}, nil
+}
@@ -0,0 +1,96 @@
+package marketdata
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/types"
+)
+
+// IEXProvider fetches daily bars from IEX Cloud's historical-prices
+// endpoint.
+type IEXProvider struct {
+	apiKey string
+	client *http.Client
+}
+
+// NewIEXProvider returns an IEXProvider using apiKey.
+func NewIEXProvider(apiKey string) *IEXProvider {
+	return &IEXProvider{
+		apiKey: apiKey,
+		client: &http.Client{Timeout: 20 * time.Second},
+	}
+}
+
+func (p *IEXProvider) Name() string { return "iex" }
+
+type iexBar struct {
+	Date   string  `json:"date"`
+	Open   float64 `json:"open"`
+	High   float64 `json:"high"`
+	Low    float64 `json:"low"`
+	Close  float64 `json:"close"` // IEX's "close" is already split/dividend adjusted
+	Volume int64   `json:"volume"`
+}
+
+// FetchOHLCV ignores interval and returns daily bars for the range
+// covering [start, end] (IEX's "range" param is coarse-grained: 1y/2y/5y).
+func (p *IEXProvider) FetchOHLCV(ticker string, start, end time.Time, interval string) ([]Bar, error) {
+	if p.apiKey == "" {
+		return nil, fmt.Errorf("marketdata: iex: no API key configured")
+	}
+
+	rangeParam := "2y"
+	if time.Since(start) > 2*365*24*time.Hour {
+		rangeParam = "5y"
+	}
+
+	url := fmt.Sprintf(
+		"https://cloud.iexapis.com/stable/stock/%s/chart/%s?token=%s",
+		ticker, rangeParam, p.apiKey,
+	)
+
+	resp, err := p.client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("marketdata: iex: failed to fetch %s: %v", ticker, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, &RateLimitError{Provider: p.Name(), Ticker: ticker}
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, fmt.Errorf("marketdata: iex: %w for %s", types.ClassifyHTTPStatus(resp.StatusCode), ticker)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("marketdata: iex: request for %s failed with status %d", ticker, resp.StatusCode)
+	}
+
+	var raw []iexBar
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("marketdata: iex: %w for %s: %v", types.ErrParse, ticker, err)
+	}
+
+	var bars []Bar
+	for _, b := range raw {
+		date, err := time.Parse("2006-01-02", b.Date)
+		if err != nil || date.Before(start) || date.After(end) {
+			continue
+		}
+		bars = append(bars, Bar{
+			Symbol:   ticker,
+			Date:     date,
+			Open:     b.Open,
+			High:     b.High,
+			Low:      b.Low,
+			Close:    b.Close,
+			AdjClose: b.Close,
+			Volume:   b.Volume,
+			Source:   p.Name(),
+		})
+	}
+
+	return bars, nil
+}
@@ -0,0 +1,54 @@
+// Package marketdata fetches historical OHLCV bars for a ticker from one
+// of several upstream providers (Yahoo Finance, Alpha Vantage, IEX), with a
+// Chain that falls back to the next configured provider when one errors,
+// returns an empty series, or reports an auth/rate-limit failure — so a
+// delisted ticker tripping Yahoo's Chart.Error doesn't silently look like
+// "no data" for the whole request.
+package marketdata
+
+import (
+	"fmt"
+	"time"
+)
+
+// Bar is a single day (or intraday interval) of OHLCV data for a symbol.
+type Bar struct {
+	Symbol   string
+	Date     time.Time
+	Open     float64
+	High     float64
+	Low      float64
+	Close    float64
+	AdjClose float64
+	Volume   int64
+
+	// Source names the provider that produced this bar (see Provider.Name),
+	// so a caller that saves it can record where it came from.
+	Source string
+}
+
+// Provider fetches historical bars for a single ticker from one upstream
+// source. interval is provider-specific; an empty string means daily bars.
+type Provider interface {
+	Name() string
+	FetchOHLCV(ticker string, start, end time.Time, interval string) ([]Bar, error)
+}
+
+// New constructs the named provider. apiKey is ignored by providers that
+// don't need one (currently just "yahoo").
+func New(name, apiKey string) (Provider, error) {
+	switch name {
+	case "", "yahoo":
+		return NewYahooProvider(), nil
+	case "alphavantage":
+		return NewAlphaVantageProvider(apiKey), nil
+	case "iex":
+		return NewIEXProvider(apiKey), nil
+	case "finnhub":
+		return NewFinnhubProvider(apiKey), nil
+	case "static":
+		return NewStaticProvider(apiKey)
+	default:
+		return nil, fmt.Errorf("marketdata: unknown provider %q", name)
+	}
+}
@@ -0,0 +1,200 @@
+package marketdata
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewDefaultsToYahoo(t *testing.T) {
+	p, err := New("", "")
+	if err != nil {
+		t.Fatalf("New(\"\", \"\") returned error: %v", err)
+	}
+	if p.Name() != "yahoo" {
+		t.Errorf("New(\"\", \"\").Name() = %q, want %q", p.Name(), "yahoo")
+	}
+}
+
+func TestNewEachProvider(t *testing.T) {
+	for _, name := range []string{"yahoo", "alphavantage", "iex", "finnhub"} {
+		p, err := New(name, "key")
+		if err != nil {
+			t.Fatalf("New(%q) returned error: %v", name, err)
+		}
+		if p.Name() != name {
+			t.Errorf("New(%q).Name() = %q, want %q", name, p.Name(), name)
+		}
+	}
+}
+
+func TestNewUnknownProvider(t *testing.T) {
+	if _, err := New("not-a-real-provider", ""); err == nil {
+		t.Error("expected New to error for an unregistered provider name")
+	}
+}
+
+// stubProvider is a Provider whose behavior is fixed at construction,
+// used to exercise ChainedProvider without making network calls.
+type stubProvider struct {
+	name string
+	bars []Bar
+	err  error
+}
+
+func (s stubProvider) Name() string { return s.name }
+
+func (s stubProvider) FetchOHLCV(ticker string, start, end time.Time, interval string) ([]Bar, error) {
+	return s.bars, s.err
+}
+
+func TestChainedProviderReturnsFirstNonEmptyResult(t *testing.T) {
+	want := []Bar{{Symbol: "AAPL"}}
+	c := ChainedProvider{Providers: []Provider{
+		stubProvider{name: "a", err: errors.New("boom")},
+		stubProvider{name: "b", bars: want},
+		stubProvider{name: "c", bars: []Bar{{Symbol: "should-not-be-reached"}}},
+	}}
+
+	got, err := c.FetchOHLCV("AAPL", time.Time{}, time.Time{}, "")
+	if err != nil {
+		t.Fatalf("FetchOHLCV returned error: %v", err)
+	}
+	if len(got) != 1 || got[0].Symbol != "AAPL" {
+		t.Errorf("FetchOHLCV = %v, want %v", got, want)
+	}
+}
+
+func TestChainedProviderSkipsEmptyResults(t *testing.T) {
+	want := []Bar{{Symbol: "MSFT"}}
+	c := ChainedProvider{Providers: []Provider{
+		stubProvider{name: "a", bars: nil},
+		stubProvider{name: "b", bars: want},
+	}}
+
+	got, err := c.FetchOHLCV("MSFT", time.Time{}, time.Time{}, "")
+	if err != nil {
+		t.Fatalf("FetchOHLCV returned error: %v", err)
+	}
+	if len(got) != 1 || got[0].Symbol != "MSFT" {
+		t.Errorf("FetchOHLCV = %v, want %v", got, want)
+	}
+}
+
+func TestChainedProviderReturnsErrorWhenAllFail(t *testing.T) {
+	c := ChainedProvider{Providers: []Provider{
+		stubProvider{name: "a", err: errors.New("boom")},
+		stubProvider{name: "b", bars: nil},
+	}}
+
+	if _, err := c.FetchOHLCV("ZZZZ", time.Time{}, time.Time{}, ""); err == nil {
+		t.Error("expected FetchOHLCV to error when every provider fails or returns empty")
+	}
+}
+
+func TestNewQuoteEachProvider(t *testing.T) {
+	for _, name := range []string{"yahoo", "alphavantage", "finnhub"} {
+		p, err := NewQuote(name, "key")
+		if err != nil {
+			t.Fatalf("NewQuote(%q) returned error: %v", name, err)
+		}
+		if p.Name() != name {
+			t.Errorf("NewQuote(%q).Name() = %q, want %q", name, p.Name(), name)
+		}
+	}
+}
+
+// stubQuoteProvider is a QuoteProvider whose behavior is fixed at
+// construction, used to exercise ChainedQuoteProvider without making
+// network calls.
+type stubQuoteProvider struct {
+	name  string
+	quote Quote
+	err   error
+}
+
+func (s stubQuoteProvider) Name() string { return s.name }
+
+func (s stubQuoteProvider) FetchQuote(ticker string) (Quote, error) {
+	return s.quote, s.err
+}
+
+func TestChainedQuoteProviderReturnsFirstNonEmptyResult(t *testing.T) {
+	want := Quote{Symbol: "AAPL", Price: 190.5}
+	c := ChainedQuoteProvider{Providers: []QuoteProvider{
+		stubQuoteProvider{name: "a", err: errors.New("boom")},
+		stubQuoteProvider{name: "b", quote: want},
+		stubQuoteProvider{name: "c", quote: Quote{Symbol: "should-not-be-reached", Price: 1}},
+	}}
+
+	got, err := c.FetchQuote("AAPL")
+	if err != nil {
+		t.Fatalf("FetchQuote returned error: %v", err)
+	}
+	if got.Symbol != "AAPL" || got.Price != 190.5 {
+		t.Errorf("FetchQuote = %+v, want %+v", got, want)
+	}
+}
+
+func TestChainedQuoteProviderSkipsZeroPriceResults(t *testing.T) {
+	want := Quote{Symbol: "MSFT", Price: 420}
+	c := ChainedQuoteProvider{Providers: []QuoteProvider{
+		stubQuoteProvider{name: "a", quote: Quote{Symbol: "MSFT"}},
+		stubQuoteProvider{name: "b", quote: want},
+	}}
+
+	got, err := c.FetchQuote("MSFT")
+	if err != nil {
+		t.Fatalf("FetchQuote returned error: %v", err)
+	}
+	if got.Price != 420 {
+		t.Errorf("FetchQuote = %+v, want %+v", got, want)
+	}
+}
+
+func TestStaticProviderServesQuoteAndBarsFromFixtureFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixtures.json")
+	fixture := `{"AAPL": {"quote": {"Price": 190.5}, "bars": [{"Date": "2024-01-02T00:00:00Z", "Close": 185.6}]}}`
+	if err := os.WriteFile(path, []byte(fixture), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	p, err := NewStaticProvider(path)
+	if err != nil {
+		t.Fatalf("NewStaticProvider: %v", err)
+	}
+
+	q, err := p.FetchQuote("AAPL")
+	if err != nil {
+		t.Fatalf("FetchQuote: %v", err)
+	}
+	if q.Price != 190.5 || q.Source != "static" {
+		t.Errorf("FetchQuote = %+v, want Price 190.5 and Source static", q)
+	}
+
+	bars, err := p.FetchOHLCV("AAPL", time.Time{}, time.Now().AddDate(1, 0, 0), "")
+	if err != nil {
+		t.Fatalf("FetchOHLCV: %v", err)
+	}
+	if len(bars) != 1 || bars[0].Close != 185.6 {
+		t.Errorf("FetchOHLCV = %+v, want one bar with Close 185.6", bars)
+	}
+}
+
+func TestStaticProviderErrorsForUnknownSymbol(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixtures.json")
+	if err := os.WriteFile(path, []byte(`{}`), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	p, err := NewStaticProvider(path)
+	if err != nil {
+		t.Fatalf("NewStaticProvider: %v", err)
+	}
+
+	if _, err := p.FetchQuote("ZZZZ"); err == nil {
+		t.Error("expected FetchQuote to error for a symbol with no fixture")
+	}
+}
@@ -0,0 +1,53 @@
+package marketdata
+
+import (
+	"fmt"
+	"time"
+)
+
+// Quote is a single point-in-time price snapshot for a symbol, the data
+// fetchStockData persists into stock_data (see Bar for the historical-bar
+// equivalent persisted into stock_historical_data).
+type Quote struct {
+	Symbol           string
+	CompanyName      string
+	Price            float64
+	PreviousClose    float64
+	Open             float64
+	High             float64
+	Low              float64
+	Volume           int64
+	MarketCap        int64
+	FiftyTwoWeekHigh float64
+	FiftyTwoWeekLow  float64
+	UpdatedAt        time.Time
+
+	// Source names the provider that produced this quote (see
+	// QuoteProvider.Name), mirroring Bar.Source.
+	Source string
+}
+
+// QuoteProvider fetches a single current-price snapshot for a ticker from
+// one upstream source.
+type QuoteProvider interface {
+	Name() string
+	FetchQuote(ticker string) (Quote, error)
+}
+
+// NewQuote constructs the named QuoteProvider. apiKey is ignored by
+// providers that don't need one ("yahoo"); for "static" it's instead the
+// path to the fixture file (see StaticProvider).
+func NewQuote(name, apiKey string) (QuoteProvider, error) {
+	switch name {
+	case "", "yahoo":
+		return NewYahooProvider(), nil
+	case "alphavantage":
+		return NewAlphaVantageProvider(apiKey), nil
+	case "finnhub":
+		return NewFinnhubProvider(apiKey), nil
+	case "static":
+		return NewStaticProvider(apiKey)
+	default:
+		return nil, fmt.Errorf("marketdata: unknown quote provider %q", name)
+	}
+}
@@ -0,0 +1,40 @@
+package marketdata
+
+// ChainedQuoteProvider tries each of Providers in order, the same
+// fall-through-on-error-or-empty-price rule ChainedProvider uses for
+// historical bars (see that type's doc comment).
+type ChainedQuoteProvider struct {
+	Providers []QuoteProvider
+}
+
+func (c ChainedQuoteProvider) Name() string { return "chain" }
+
+func (c ChainedQuoteProvider) FetchQuote(ticker string) (Quote, error) {
+	var lastErr error
+	for _, p := range c.Providers {
+		q, err := p.FetchQuote(ticker)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if q.Price == 0 {
+			lastErr = &emptyQuoteError{provider: p.Name(), ticker: ticker}
+			continue
+		}
+		return q, nil
+	}
+
+	if lastErr == nil {
+		lastErr = &emptyQuoteError{provider: "chain", ticker: ticker}
+	}
+	return Quote{}, lastErr
+}
+
+type emptyQuoteError struct {
+	provider string
+	ticker   string
+}
+
+func (e *emptyQuoteError) Error() string {
+	return "marketdata: " + e.provider + ": no quote returned for " + e.ticker
+}
@@ -0,0 +1,82 @@
+package marketdata
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// staticFixture is one symbol's entry in a StaticProvider's JSON file: a
+// quote snapshot plus the daily bars FetchOHLCV serves, so a single file
+// backs both paths.
+type staticFixture struct {
+	Quote Quote `json:"quote"`
+	Bars  []Bar `json:"bars"`
+}
+
+// StaticProvider serves canned quotes and bars from a JSON file instead of
+// calling any upstream, for offline tests and demos that shouldn't depend
+// on network access or API keys. Its file maps ticker to a staticFixture,
+// e.g.:
+//
+//	{"AAPL": {"quote": {"Price": 190.5}, "bars": [{"Date": "2024-01-02T00:00:00Z", "Close": 185.6}]}}
+type StaticProvider struct {
+	fixtures map[string]staticFixture
+}
+
+// NewStaticProvider loads fixtures from the JSON file at path.
+func NewStaticProvider(path string) (*StaticProvider, error) {
+	if path == "" {
+		return nil, fmt.Errorf("marketdata: static: no fixture file configured")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("marketdata: static: failed to read fixture file %s: %v", path, err)
+	}
+
+	var fixtures map[string]staticFixture
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return nil, fmt.Errorf("marketdata: static: failed to parse fixture file %s: %v", path, err)
+	}
+	return &StaticProvider{fixtures: fixtures}, nil
+}
+
+func (p *StaticProvider) Name() string { return "static" }
+
+// FetchQuote returns the fixture quote for ticker.
+func (p *StaticProvider) FetchQuote(ticker string) (Quote, error) {
+	fixture, ok := p.fixtures[ticker]
+	if !ok {
+		return Quote{}, fmt.Errorf("marketdata: static: no fixture for %s", ticker)
+	}
+
+	q := fixture.Quote
+	q.Symbol = ticker
+	q.Source = p.Name()
+	if q.UpdatedAt.IsZero() {
+		q.UpdatedAt = time.Now()
+	}
+	return q, nil
+}
+
+// FetchOHLCV returns the fixture bars for ticker within [start, end];
+// interval is ignored, same as every other Provider here.
+func (p *StaticProvider) FetchOHLCV(ticker string, start, end time.Time, interval string) ([]Bar, error) {
+	fixture, ok := p.fixtures[ticker]
+	if !ok {
+		return nil, fmt.Errorf("marketdata: static: no fixture for %s", ticker)
+	}
+
+	var bars []Bar
+	for _, b := range fixture.Bars {
+		if b.Date.Before(start) || b.Date.After(end) {
+			continue
+		}
+		b.Symbol = ticker
+		b.Source = p.Name()
+		bars = append(bars, b)
+	}
+	return bars, nil
+}
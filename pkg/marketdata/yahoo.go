@@ -0,0 +1,212 @@
+package marketdata
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/httpc"
+	"github.com/darianmavgo/backtest-sell-limit/pkg/retry"
+	"github.com/darianmavgo/backtest-sell-limit/pkg/types"
+	"github.com/darianmavgo/backtest-sell-limit/pkg/yahoosession"
+)
+
+// yahooRetryConfig backs FetchOHLCV's backoff between a network error or a
+// non-429 retry; yahooRateLimitRetryConfig backs off further for a 429,
+// since Yahoo's rate limit takes longer to clear than a dropped
+// connection.
+var (
+	yahooRetryConfig = retry.Config{
+		InitialInterval:     time.Second,
+		Multiplier:          1.5,
+		RandomizationFactor: 0.3,
+		MaxInterval:         10 * time.Second,
+	}
+	yahooRateLimitRetryConfig = retry.Config{
+		InitialInterval:     2 * time.Second,
+		Multiplier:          1.5,
+		RandomizationFactor: 0.3,
+		MaxInterval:         20 * time.Second,
+	}
+)
+
+// YahooProvider fetches daily bars from Yahoo Finance's undocumented chart
+// API. It needs no API key, but Yahoo requires a consent cookie and crumb
+// token on every chart request now; see pkg/yahoosession.
+type YahooProvider struct {
+	session *yahoosession.Session
+}
+
+// NewYahooProvider returns a YahooProvider ready to use. All instances
+// share one process-wide yahoosession.Session (also shared with
+// pkg/providers' YahooProvider), so the consent-cookie handshake, crumb
+// fetch, and request rate limit apply once process-wide regardless of how
+// many providers are constructed across either chain's worker pool.
+func NewYahooProvider() *YahooProvider {
+	return &YahooProvider{session: yahoosession.Get()}
+}
+
+func (p *YahooProvider) Name() string { return "yahoo" }
+
+// FetchOHLCV ignores interval and always returns daily bars; Yahoo's chart
+// API supports intraday intervals too, but nothing in this codebase needs
+// them yet.
+func (p *YahooProvider) FetchOHLCV(ticker string, start, end time.Time, interval string) ([]Bar, error) {
+	ctx := context.Background()
+	if err := p.session.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("marketdata: yahoo: rate limit wait: %w", err)
+	}
+	if err := httpc.WaitIfLimited(ctx); err != nil {
+		return nil, fmt.Errorf("marketdata: yahoo: %w", err)
+	}
+
+	url := fmt.Sprintf(
+		"https://query1.finance.yahoo.com/v8/finance/chart/%s?period1=%d&period2=%d&interval=1d&includeAdjustedClose=true",
+		ticker,
+		start.Unix(),
+		end.Unix(),
+	)
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("marketdata: failed to create request: %v", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
+	req.Header.Set("Accept-Encoding", "gzip, deflate, br")
+	req.Header.Set("Origin", "https://finance.yahoo.com")
+	req.Header.Set("Referer", fmt.Sprintf("https://finance.yahoo.com/quote/%s", ticker))
+
+	var resp *http.Response
+	maxRetries := 3
+	for i := 0; i < maxRetries; i++ {
+		if err := p.session.Attach(req); err != nil {
+			return nil, fmt.Errorf("marketdata: failed to attach yahoo session to request for %s: %w", ticker, err)
+		}
+
+		resp, err = p.session.Client().Do(req)
+		if err != nil {
+			if i == maxRetries-1 {
+				return nil, fmt.Errorf("marketdata: failed to fetch %s after %d retries: %v", ticker, maxRetries, err)
+			}
+			time.Sleep(yahooRetryConfig.Delay(i))
+			continue
+		}
+		httpc.RecordHeaders(resp)
+
+		// 401/403 mean the crumb or cookies were rejected outright; Yahoo
+		// also uses the non-standard 999 status for its own "blocked,
+		// refresh and try again" signal. All three call for a fresh
+		// session rather than a plain retry.
+		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden || resp.StatusCode == 999 {
+			resp.Body.Close()
+			if i == maxRetries-1 {
+				return nil, fmt.Errorf("marketdata: yahoo request for %s still rejected (status %d) after %d retries", ticker, resp.StatusCode, maxRetries)
+			}
+			if err := p.session.Refresh(); err != nil {
+				return nil, fmt.Errorf("marketdata: failed to refresh yahoo session for %s: %w", ticker, err)
+			}
+			continue
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests {
+			resp.Body.Close()
+			if i == maxRetries-1 {
+				return nil, &RateLimitError{Provider: p.Name(), Ticker: ticker}
+			}
+			time.Sleep(yahooRateLimitRetryConfig.Delay(i))
+			continue
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("marketdata: yahoo: %w for %s", types.ClassifyHTTPStatus(resp.StatusCode), ticker)
+		}
+		break
+	}
+	defer resp.Body.Close()
+
+	var reader io.Reader = resp.Body
+	if resp.Header.Get("Content-Encoding") == "gzip" {
+		gzReader, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("marketdata: failed to create gzip reader: %v", err)
+		}
+		defer gzReader.Close()
+		reader = gzReader
+	}
+
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("marketdata: failed to read yahoo response for %s: %v", ticker, err)
+	}
+
+	var yahooResp struct {
+		Chart struct {
+			Result []struct {
+				Timestamp  []int64 `json:"timestamp"`
+				Indicators struct {
+					Quote []struct {
+						Open   []float64 `json:"open"`
+						High   []float64 `json:"high"`
+						Low    []float64 `json:"low"`
+						Close  []float64 `json:"close"`
+						Volume []int64   `json:"volume"`
+					} `json:"quote"`
+					Adjclose []struct {
+						Adjclose []float64 `json:"adjclose"`
+					} `json:"adjclose"`
+				} `json:"indicators"`
+			} `json:"result"`
+			Error *struct {
+				Code        string `json:"code"`
+				Description string `json:"description"`
+			} `json:"error"`
+		} `json:"chart"`
+	}
+	if err := json.Unmarshal(body, &yahooResp); err != nil {
+		return nil, fmt.Errorf("marketdata: yahoo: %w for %s: %v", types.ErrParse, ticker, err)
+	}
+
+	// Yahoo reports delisted/unknown tickers as a 200 with a Chart.Error
+	// body rather than a non-2xx status, so a caller relying on the HTTP
+	// status alone would treat this as success with zero bars.
+	if yahooResp.Chart.Error != nil {
+		return nil, fmt.Errorf("marketdata: yahoo error for %s: %s - %s", ticker, yahooResp.Chart.Error.Code, yahooResp.Chart.Error.Description)
+	}
+	if len(yahooResp.Chart.Result) == 0 {
+		return nil, fmt.Errorf("marketdata: yahoo: %w for %s", types.ErrNoData, ticker)
+	}
+
+	result := yahooResp.Chart.Result[0]
+	if len(result.Indicators.Quote) == 0 || len(result.Indicators.Adjclose) == 0 {
+		return nil, fmt.Errorf("marketdata: yahoo: %w for %s", types.ErrNoData, ticker)
+	}
+	quote := result.Indicators.Quote[0]
+	adjclose := result.Indicators.Adjclose[0]
+
+	var bars []Bar
+	for i, ts := range result.Timestamp {
+		if i >= len(quote.Open) || i >= len(quote.High) || i >= len(quote.Low) ||
+			i >= len(quote.Close) || i >= len(quote.Volume) || i >= len(adjclose.Adjclose) {
+			continue
+		}
+		bars = append(bars, Bar{
+			Symbol:   ticker,
+			Date:     time.Unix(ts, 0),
+			Open:     quote.Open[i],
+			High:     quote.High[i],
+			Low:      quote.Low[i],
+			Close:    quote.Close[i],
+			AdjClose: adjclose.Adjclose[i],
+			Volume:   quote.Volume[i],
+			Source:   p.Name(),
+		})
+	}
+
+	return bars, nil
+}
@@ -0,0 +1,122 @@
+package marketdata
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/httpc"
+	"github.com/darianmavgo/backtest-sell-limit/pkg/types"
+)
+
+// FetchQuote fetches a current-price snapshot from the same chart API
+// FetchOHLCV uses, reading its "meta" block instead of its OHLCV series.
+// Unlike FetchOHLCV it doesn't retry on a rejected crumb/cookie; a quote is
+// refetched often enough that the next call's session refresh (see
+// pkg/yahoosession) covers it.
+func (p *YahooProvider) FetchQuote(ticker string) (Quote, error) {
+	ctx := context.Background()
+	if err := p.session.Wait(ctx); err != nil {
+		return Quote{}, fmt.Errorf("marketdata: yahoo: rate limit wait: %w", err)
+	}
+	if err := httpc.WaitIfLimited(ctx); err != nil {
+		return Quote{}, fmt.Errorf("marketdata: yahoo: %w", err)
+	}
+
+	url := fmt.Sprintf("https://query1.finance.yahoo.com/v8/finance/chart/%s?range=1d&interval=1d", ticker)
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return Quote{}, fmt.Errorf("marketdata: failed to create request: %v", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip, deflate, br")
+	req.Header.Set("Origin", "https://finance.yahoo.com")
+	req.Header.Set("Referer", fmt.Sprintf("https://finance.yahoo.com/quote/%s", ticker))
+
+	if err := p.session.Attach(req); err != nil {
+		return Quote{}, fmt.Errorf("marketdata: failed to attach yahoo session to request for %s: %w", ticker, err)
+	}
+
+	resp, err := p.session.Client().Do(req)
+	if err != nil {
+		return Quote{}, fmt.Errorf("marketdata: failed to fetch quote for %s: %v", ticker, err)
+	}
+	defer resp.Body.Close()
+	httpc.RecordHeaders(resp)
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return Quote{}, &RateLimitError{Provider: p.Name(), Ticker: ticker}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Quote{}, fmt.Errorf("marketdata: yahoo: %w for %s", types.ClassifyHTTPStatus(resp.StatusCode), ticker)
+	}
+
+	var reader io.Reader = resp.Body
+	if resp.Header.Get("Content-Encoding") == "gzip" {
+		gzReader, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return Quote{}, fmt.Errorf("marketdata: failed to create gzip reader: %v", err)
+		}
+		defer gzReader.Close()
+		reader = gzReader
+	}
+
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return Quote{}, fmt.Errorf("marketdata: failed to read yahoo quote response for %s: %v", ticker, err)
+	}
+
+	var yahooResp struct {
+		Chart struct {
+			Result []struct {
+				Meta struct {
+					RegularMarketPrice   float64 `json:"regularMarketPrice"`
+					PreviousClose        float64 `json:"previousClose"`
+					RegularMarketOpen    float64 `json:"regularMarketOpen"`
+					RegularMarketDayHigh float64 `json:"regularMarketDayHigh"`
+					RegularMarketDayLow  float64 `json:"regularMarketDayLow"`
+					RegularMarketVolume  int64   `json:"regularMarketVolume"`
+					MarketCap            int64   `json:"marketCap"`
+					FiftyTwoWeekHigh     float64 `json:"fiftyTwoWeekHigh"`
+					FiftyTwoWeekLow      float64 `json:"fiftyTwoWeekLow"`
+					LongName             string  `json:"longName"`
+				} `json:"meta"`
+			} `json:"result"`
+			Error *struct {
+				Code        string `json:"code"`
+				Description string `json:"description"`
+			} `json:"error"`
+		} `json:"chart"`
+	}
+	if err := json.Unmarshal(body, &yahooResp); err != nil {
+		return Quote{}, fmt.Errorf("marketdata: yahoo: %w for %s: %v", types.ErrParse, ticker, err)
+	}
+	if yahooResp.Chart.Error != nil {
+		return Quote{}, fmt.Errorf("marketdata: yahoo error for %s: %s - %s", ticker, yahooResp.Chart.Error.Code, yahooResp.Chart.Error.Description)
+	}
+	if len(yahooResp.Chart.Result) == 0 {
+		return Quote{}, fmt.Errorf("marketdata: yahoo: %w for %s", types.ErrNoData, ticker)
+	}
+
+	meta := yahooResp.Chart.Result[0].Meta
+	return Quote{
+		Symbol:           ticker,
+		CompanyName:      meta.LongName,
+		Price:            meta.RegularMarketPrice,
+		PreviousClose:    meta.PreviousClose,
+		Open:             meta.RegularMarketOpen,
+		High:             meta.RegularMarketDayHigh,
+		Low:              meta.RegularMarketDayLow,
+		Volume:           meta.RegularMarketVolume,
+		MarketCap:        meta.MarketCap,
+		FiftyTwoWeekHigh: meta.FiftyTwoWeekHigh,
+		FiftyTwoWeekLow:  meta.FiftyTwoWeekLow,
+		UpdatedAt:        time.Now(),
+		Source:           p.Name(),
+	}, nil
+}
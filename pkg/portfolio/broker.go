@@ -0,0 +1,76 @@
+package portfolio
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// brokerPosition is the JSON shape HTTPPositionProvider expects each
+// element of the response array to match.
+type brokerPosition struct {
+	Symbol    string  `json:"symbol"`
+	Quantity  float64 `json:"quantity"`
+	CostBasis float64 `json:"cost_basis"`
+	BuyDate   string  `json:"buy_date"` // YYYY-MM-DD
+}
+
+// HTTPPositionProvider fetches positions from a broker or budgeting
+// service's API (e.g. a YNAB-style account export) that returns a JSON
+// array of {symbol, quantity, cost_basis, buy_date}. It's deliberately
+// generic rather than tied to one vendor's SDK, since which broker an
+// installation uses varies; point URL at whatever endpoint/proxy already
+// speaks this shape.
+type HTTPPositionProvider struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewHTTPPositionProvider returns an HTTPPositionProvider fetching url with
+// a 10s timeout client.
+func NewHTTPPositionProvider(url string) *HTTPPositionProvider {
+	return &HTTPPositionProvider{
+		URL:    url,
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Positions fetches p.URL and decodes its JSON array into Positions.
+func (p *HTTPPositionProvider) Positions(ctx context.Context) ([]Position, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("portfolio: failed to create request: %w", err)
+	}
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("portfolio: request to %s failed: %w", p.URL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("portfolio: %s returned status %d", p.URL, resp.StatusCode)
+	}
+
+	var raw []brokerPosition
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("portfolio: failed to decode response: %w", err)
+	}
+
+	positions := make([]Position, 0, len(raw))
+	for _, r := range raw {
+		buyDate, err := time.Parse("2006-01-02", r.BuyDate)
+		if err != nil {
+			return nil, fmt.Errorf("portfolio: invalid buy_date %q for %s: %w", r.BuyDate, r.Symbol, err)
+		}
+		positions = append(positions, Position{
+			Symbol:    r.Symbol,
+			Quantity:  r.Quantity,
+			CostBasis: r.CostBasis,
+			BuyDate:   buyDate,
+		})
+	}
+	return positions, nil
+}
@@ -0,0 +1,94 @@
+package portfolio
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSVPositionProvider reads positions from a CSV file with a header row
+// naming "symbol", "quantity", "cost_basis", and "buy_date" (YYYY-MM-DD)
+// columns, in any order — the same header-keyed approach sp500.FileSource
+// uses, so column reordering in an exported file doesn't silently misread
+// a column.
+type CSVPositionProvider struct {
+	FileName string
+}
+
+// NewCSVPositionProvider returns a CSVPositionProvider reading fileName.
+func NewCSVPositionProvider(fileName string) *CSVPositionProvider {
+	return &CSVPositionProvider{FileName: fileName}
+}
+
+// Positions parses FileName's rows into Positions.
+func (p *CSVPositionProvider) Positions(ctx context.Context) ([]Position, error) {
+	f, err := os.Open(p.FileName)
+	if err != nil {
+		return nil, fmt.Errorf("portfolio: failed to open %s: %w", p.FileName, err)
+	}
+	defer f.Close()
+
+	return parsePositionsCSV(f)
+}
+
+func parsePositionsCSV(r io.Reader) ([]Position, error) {
+	reader := csv.NewReader(r)
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("portfolio: failed to parse CSV: %w", err)
+	}
+	if len(records) < 1 {
+		return nil, fmt.Errorf("portfolio: CSV has no header row")
+	}
+
+	columns := make(map[string]int, len(records[0]))
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	field := func(row []string, name string) (string, bool) {
+		i, ok := columns[name]
+		if !ok || i >= len(row) {
+			return "", false
+		}
+		return strings.TrimSpace(row[i]), true
+	}
+
+	var positions []Position
+	for _, row := range records[1:] {
+		symbol, ok := field(row, "symbol")
+		if !ok || symbol == "" {
+			continue
+		}
+
+		quantityStr, _ := field(row, "quantity")
+		quantity, err := strconv.ParseFloat(quantityStr, 64)
+		if err != nil {
+			return nil, fmt.Errorf("portfolio: invalid quantity %q for %s: %w", quantityStr, symbol, err)
+		}
+
+		costBasisStr, _ := field(row, "cost_basis")
+		costBasis, err := strconv.ParseFloat(costBasisStr, 64)
+		if err != nil {
+			return nil, fmt.Errorf("portfolio: invalid cost_basis %q for %s: %w", costBasisStr, symbol, err)
+		}
+
+		buyDateStr, _ := field(row, "buy_date")
+		buyDate, err := time.Parse("2006-01-02", buyDateStr)
+		if err != nil {
+			return nil, fmt.Errorf("portfolio: invalid buy_date %q for %s: %w", buyDateStr, symbol, err)
+		}
+
+		positions = append(positions, Position{
+			Symbol:    symbol,
+			Quantity:  quantity,
+			CostBasis: costBasis,
+			BuyDate:   buyDate,
+		})
+	}
+	return positions, nil
+}
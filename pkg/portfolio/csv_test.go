@@ -0,0 +1,38 @@
+package portfolio
+
+import (
+	"strings"
+	"testing"
+)
+
+const sampleCSV = `buy_date,symbol,quantity,cost_basis
+2024-01-02,AAPL,10,150.5
+2024-02-01,MSFT,5,380
+`
+
+func TestParsePositionsCSVIsRobustToColumnReordering(t *testing.T) {
+	positions, err := parsePositionsCSV(strings.NewReader(sampleCSV))
+	if err != nil {
+		t.Fatalf("parsePositionsCSV returned error: %v", err)
+	}
+	if len(positions) != 2 {
+		t.Fatalf("len(positions) = %d, want 2", len(positions))
+	}
+	if positions[0].Symbol != "AAPL" || positions[0].Quantity != 10 || positions[0].CostBasis != 150.5 {
+		t.Errorf("positions[0] = %+v, unexpected", positions[0])
+	}
+	if positions[1].Symbol != "MSFT" {
+		t.Errorf("positions[1].Symbol = %q, want MSFT", positions[1].Symbol)
+	}
+}
+
+func TestParsePositionsCSVSkipsRowsWithoutSymbol(t *testing.T) {
+	const csv = "symbol,quantity,cost_basis,buy_date\n,10,100,2024-01-01\nAAPL,10,100,2024-01-01\n"
+	positions, err := parsePositionsCSV(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("parsePositionsCSV returned error: %v", err)
+	}
+	if len(positions) != 1 {
+		t.Fatalf("len(positions) = %d, want 1", len(positions))
+	}
+}
@@ -0,0 +1,30 @@
+// Package portfolio values a set of stock positions against the latest
+// data in stock_data/stock_historical_data, computing market value,
+// unrealized P/L, and trailing-stop trigger status. Where the positions
+// themselves come from is pluggable (see PositionProvider) so a local
+// SQLite table, an imported CSV, or a broker's own API can all back the
+// same Valuator.
+package portfolio
+
+import (
+	"context"
+	"time"
+)
+
+// Position is one lot of a symbol: how many shares, what they cost, and
+// when they were bought (BuyDate anchors Valuator's trailing-stop "high
+// since purchase" calculation).
+type Position struct {
+	Symbol    string
+	Quantity  float64
+	CostBasis float64 // price per share at purchase
+	BuyDate   time.Time
+}
+
+// PositionProvider resolves the positions a Valuator should price.
+// Implementations include a local SQLite table (SQLitePositionProvider), a
+// CSV import (CSVPositionProvider), and a generic broker/budgeting API
+// adapter (HTTPPositionProvider).
+type PositionProvider interface {
+	Positions(ctx context.Context) ([]Position, error)
+}
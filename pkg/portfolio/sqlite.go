@@ -0,0 +1,76 @@
+package portfolio
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/store"
+)
+
+// SQLitePositionProvider resolves positions from the positions table of a
+// store.Store-backed database (the same one holding stock_data and
+// stock_historical_data), so a Valuator can join them without crossing
+// databases.
+type SQLitePositionProvider struct {
+	DB store.Store
+}
+
+// NewSQLitePositionProvider returns a SQLitePositionProvider reading from
+// db's positions table, creating it first if it doesn't exist.
+func NewSQLitePositionProvider(db store.Store) (*SQLitePositionProvider, error) {
+	if err := ensurePositionsTable(db); err != nil {
+		return nil, err
+	}
+	return &SQLitePositionProvider{DB: db}, nil
+}
+
+// ensurePositionsTable creates the positions table if it doesn't already
+// exist. Each row is one lot (a symbol can have more than one, bought at
+// different times/prices).
+func ensurePositionsTable(db store.Store) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS positions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			symbol TEXT NOT NULL,
+			quantity REAL NOT NULL,
+			cost_basis REAL NOT NULL,
+			buy_date INTEGER NOT NULL
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("portfolio: failed to create positions table: %w", err)
+	}
+	return nil
+}
+
+// Positions returns every lot in the positions table.
+func (p *SQLitePositionProvider) Positions(ctx context.Context) ([]Position, error) {
+	rows, err := p.DB.QueryContext(ctx, `
+		SELECT symbol, quantity, cost_basis, buy_date FROM positions ORDER BY symbol
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("portfolio: failed to query positions: %w", err)
+	}
+	defer rows.Close()
+
+	var positions []Position
+	for rows.Next() {
+		var symbol string
+		var quantity, costBasis float64
+		var buyDate int64
+		if err := rows.Scan(&symbol, &quantity, &costBasis, &buyDate); err != nil {
+			return nil, fmt.Errorf("portfolio: failed to scan position: %w", err)
+		}
+		positions = append(positions, Position{
+			Symbol:    symbol,
+			Quantity:  quantity,
+			CostBasis: costBasis,
+			BuyDate:   time.Unix(buyDate, 0),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("portfolio: row iteration error: %w", err)
+	}
+	return positions, nil
+}
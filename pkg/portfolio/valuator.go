@@ -0,0 +1,171 @@
+package portfolio
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/store"
+)
+
+// DefaultTrailingStopPct is used when Valuator.TrailingStopPct is zero.
+const DefaultTrailingStopPct = 0.10
+
+// ValuedPosition is a Position priced against the latest available quote.
+// Percent fields (DailyChangePct, UnrealizedPLPct) are in percent units
+// (1.5 means 1.5%), matching stock_data.change_percent's convention.
+// BelowHighPct is a fraction (0.1 means 10% below the post-purchase high),
+// compared directly against Valuator.TrailingStopPct.
+type ValuedPosition struct {
+	Position
+	Price            float64
+	MarketValue      float64
+	CostValue        float64
+	UnrealizedPL     float64
+	UnrealizedPLPct  float64
+	DailyChangePct   float64
+	PostPurchaseHigh float64
+	BelowHighPct     float64
+	TrailingStopHit  bool
+}
+
+// Valuator joins positions with the latest market data in stock_data
+// (falling back to stock_historical_data's most recent rows when
+// stock_data has nothing for a symbol) to compute market value,
+// unrealized P/L, and trailing-stop trigger status.
+type Valuator struct {
+	DB              store.Store
+	TrailingStopPct float64
+}
+
+// NewValuator returns a Valuator using trailingStopPct (DefaultTrailingStopPct if zero).
+func NewValuator(db store.Store, trailingStopPct float64) *Valuator {
+	if trailingStopPct == 0 {
+		trailingStopPct = DefaultTrailingStopPct
+	}
+	return &Valuator{DB: db, TrailingStopPct: trailingStopPct}
+}
+
+// Value prices every position in positions.
+func (v *Valuator) Value(ctx context.Context, positions []Position) ([]ValuedPosition, error) {
+	valued := make([]ValuedPosition, 0, len(positions))
+	for _, pos := range positions {
+		price, dailyChangePct, err := v.latestQuote(ctx, pos.Symbol)
+		if err != nil {
+			return nil, fmt.Errorf("portfolio: failed to quote %s: %w", pos.Symbol, err)
+		}
+
+		high, err := v.postPurchaseHigh(ctx, pos.Symbol, pos.BuyDate, price)
+		if err != nil {
+			return nil, fmt.Errorf("portfolio: failed to compute post-purchase high for %s: %w", pos.Symbol, err)
+		}
+
+		marketValue := price * pos.Quantity
+		costValue := pos.CostBasis * pos.Quantity
+		belowHighPct := 0.0
+		if high > 0 {
+			belowHighPct = (high - price) / high
+		}
+
+		valued = append(valued, ValuedPosition{
+			Position:         pos,
+			Price:            price,
+			MarketValue:      marketValue,
+			CostValue:        costValue,
+			UnrealizedPL:     marketValue - costValue,
+			UnrealizedPLPct:  percentOf(marketValue-costValue, costValue),
+			DailyChangePct:   dailyChangePct,
+			PostPurchaseHigh: high,
+			BelowHighPct:     belowHighPct,
+			TrailingStopHit:  belowHighPct >= v.TrailingStopPct,
+		})
+	}
+	return valued, nil
+}
+
+// Alerts returns only the positions whose trailing stop has triggered.
+func (v *Valuator) Alerts(ctx context.Context, positions []Position) ([]ValuedPosition, error) {
+	valued, err := v.Value(ctx, positions)
+	if err != nil {
+		return nil, err
+	}
+
+	var alerts []ValuedPosition
+	for _, vp := range valued {
+		if vp.TrailingStopHit {
+			alerts = append(alerts, vp)
+		}
+	}
+	return alerts, nil
+}
+
+// latestQuote returns symbol's current price and daily change percent,
+// preferring stock_data's live snapshot and falling back to the two most
+// recent stock_historical_data closes (computing the change itself) when
+// stock_data has no row for symbol.
+func (v *Valuator) latestQuote(ctx context.Context, symbol string) (price, dailyChangePct float64, err error) {
+	err = v.DB.QueryRowContext(ctx, `
+		SELECT price, change_percent FROM stock_data WHERE symbol = ?
+	`, symbol).Scan(&price, &dailyChangePct)
+	if err == nil {
+		return price, dailyChangePct, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, 0, fmt.Errorf("failed to query stock_data: %w", err)
+	}
+
+	rows, err := v.DB.QueryContext(ctx, `
+		SELECT close FROM stock_historical_data WHERE symbol = ? ORDER BY date DESC LIMIT 2
+	`, symbol)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to query stock_historical_data: %w", err)
+	}
+	defer rows.Close()
+
+	var closes []float64
+	for rows.Next() {
+		var c float64
+		if err := rows.Scan(&c); err != nil {
+			return 0, 0, fmt.Errorf("failed to scan stock_historical_data row: %w", err)
+		}
+		closes = append(closes, c)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, 0, fmt.Errorf("row iteration error: %w", err)
+	}
+	if len(closes) == 0 {
+		return 0, 0, fmt.Errorf("no price data found for %s", symbol)
+	}
+
+	price = closes[0]
+	if len(closes) == 2 && closes[1] != 0 {
+		dailyChangePct = (closes[0] - closes[1]) / closes[1] * 100
+	}
+	return price, dailyChangePct, nil
+}
+
+// postPurchaseHigh returns the highest close in stock_historical_data for
+// symbol since buyDate, falling back to current when there's no
+// historical data yet (e.g. the position was only just bought).
+func (v *Valuator) postPurchaseHigh(ctx context.Context, symbol string, buyDate time.Time, current float64) (float64, error) {
+	var high sql.NullFloat64
+	err := v.DB.QueryRowContext(ctx, `
+		SELECT MAX(close) FROM stock_historical_data WHERE symbol = ? AND date >= ?
+	`, symbol, buyDate.Unix()).Scan(&high)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query stock_historical_data: %w", err)
+	}
+	if !high.Valid || high.Float64 < current {
+		return current, nil
+	}
+	return high.Float64, nil
+}
+
+// percentOf returns a/b as a percentage, or 0 if b is zero.
+func percentOf(a, b float64) float64 {
+	if b == 0 {
+		return 0
+	}
+	return a / b * 100
+}
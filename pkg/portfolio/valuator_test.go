@@ -0,0 +1,125 @@
+package portfolio
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/store"
+)
+
+func newTestStore(t *testing.T) store.Store {
+	t.Helper()
+	dsn := filepath.Join(t.TempDir(), "test.db")
+
+	s, err := store.NewSQLiteStore(dsn)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore returned error: %v", err)
+	}
+	t.Cleanup(func() { s.DB().Close() })
+
+	if _, err := s.Exec(`
+		CREATE TABLE stock_data (
+			symbol TEXT PRIMARY KEY, price REAL, change_percent REAL
+		)
+	`); err != nil {
+		t.Fatalf("failed to create stock_data: %v", err)
+	}
+	if _, err := s.Exec(`
+		CREATE TABLE stock_historical_data (
+			symbol TEXT, date INTEGER, close REAL,
+			PRIMARY KEY (symbol, date)
+		)
+	`); err != nil {
+		t.Fatalf("failed to create stock_historical_data: %v", err)
+	}
+
+	return s
+}
+
+func TestValuatorValueUsesStockDataWhenPresent(t *testing.T) {
+	s := newTestStore(t)
+	if _, err := s.Exec(`INSERT INTO stock_data (symbol, price, change_percent) VALUES ('AAPL', 150, 1.5)`); err != nil {
+		t.Fatalf("failed to seed stock_data: %v", err)
+	}
+
+	v := NewValuator(s, 0.10)
+	positions := []Position{{Symbol: "AAPL", Quantity: 10, CostBasis: 100, BuyDate: time.Unix(0, 0)}}
+
+	valued, err := v.Value(context.Background(), positions)
+	if err != nil {
+		t.Fatalf("Value returned error: %v", err)
+	}
+	if len(valued) != 1 {
+		t.Fatalf("len(valued) = %d, want 1", len(valued))
+	}
+	if valued[0].Price != 150 {
+		t.Errorf("Price = %v, want 150", valued[0].Price)
+	}
+	if valued[0].MarketValue != 1500 {
+		t.Errorf("MarketValue = %v, want 1500", valued[0].MarketValue)
+	}
+	if valued[0].UnrealizedPL != 500 {
+		t.Errorf("UnrealizedPL = %v, want 500", valued[0].UnrealizedPL)
+	}
+}
+
+func TestValuatorValueFallsBackToHistoricalData(t *testing.T) {
+	s := newTestStore(t)
+	if _, err := s.Exec(`INSERT INTO stock_historical_data (symbol, date, close) VALUES ('MSFT', 0, 100), ('MSFT', 86400, 110)`); err != nil {
+		t.Fatalf("failed to seed stock_historical_data: %v", err)
+	}
+
+	v := NewValuator(s, 0.10)
+	positions := []Position{{Symbol: "MSFT", Quantity: 1, CostBasis: 100, BuyDate: time.Unix(0, 0)}}
+
+	valued, err := v.Value(context.Background(), positions)
+	if err != nil {
+		t.Fatalf("Value returned error: %v", err)
+	}
+	if valued[0].Price != 110 {
+		t.Errorf("Price = %v, want 110", valued[0].Price)
+	}
+	wantChange := 10.0
+	if valued[0].DailyChangePct != wantChange {
+		t.Errorf("DailyChangePct = %v, want %v", valued[0].DailyChangePct, wantChange)
+	}
+}
+
+func TestValuatorAlertsReturnsOnlyTrailingStopHits(t *testing.T) {
+	s := newTestStore(t)
+	if _, err := s.Exec(`INSERT INTO stock_data (symbol, price, change_percent) VALUES ('AAPL', 90, 0), ('MSFT', 100, 0)`); err != nil {
+		t.Fatalf("failed to seed stock_data: %v", err)
+	}
+	if _, err := s.Exec(`INSERT INTO stock_historical_data (symbol, date, close) VALUES ('AAPL', 0, 100), ('MSFT', 0, 100)`); err != nil {
+		t.Fatalf("failed to seed stock_historical_data: %v", err)
+	}
+
+	v := NewValuator(s, 0.10)
+	positions := []Position{
+		{Symbol: "AAPL", Quantity: 1, CostBasis: 100, BuyDate: time.Unix(0, 0)}, // 10% below high: triggers
+		{Symbol: "MSFT", Quantity: 1, CostBasis: 100, BuyDate: time.Unix(0, 0)}, // at high: no trigger
+	}
+
+	alerts, err := v.Alerts(context.Background(), positions)
+	if err != nil {
+		t.Fatalf("Alerts returned error: %v", err)
+	}
+	if len(alerts) != 1 {
+		t.Fatalf("len(alerts) = %d, want 1", len(alerts))
+	}
+	if alerts[0].Symbol != "AAPL" {
+		t.Errorf("alerts[0].Symbol = %q, want AAPL", alerts[0].Symbol)
+	}
+}
+
+func TestNewValuatorDefaultsTrailingStopPct(t *testing.T) {
+	s := newTestStore(t)
+	v := NewValuator(s, 0)
+	if v.TrailingStopPct != DefaultTrailingStopPct {
+		t.Errorf("TrailingStopPct = %v, want %v", v.TrailingStopPct, DefaultTrailingStopPct)
+	}
+}
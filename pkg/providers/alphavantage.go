@@ -0,0 +1,165 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/types"
+	"golang.org/x/time/rate"
+)
+
+// AlphaVantageProvider fetches bars from Alpha Vantage's TIME_SERIES_DAILY
+// and TIME_SERIES_INTRADAY endpoints.
+type AlphaVantageProvider struct {
+	apiKey  string
+	client  *types.RetryableClient
+	limiter *rate.Limiter
+}
+
+// NewAlphaVantageProvider returns an AlphaVantageProvider using apiKey. Its
+// limiter matches Alpha Vantage's free-tier rate of 5 requests/minute.
+func NewAlphaVantageProvider(apiKey string) *AlphaVantageProvider {
+	return &AlphaVantageProvider{
+		apiKey:  apiKey,
+		client:  types.NewRetryableClient(20 * time.Second),
+		limiter: rate.NewLimiter(rate.Every(12*time.Second), 1),
+	}
+}
+
+// Name identifies this provider as "alphavantage".
+func (p *AlphaVantageProvider) Name() string { return "alphavantage" }
+
+// FetchDaily returns one row per trading day between start and end.
+func (p *AlphaVantageProvider) FetchDaily(ctx context.Context, symbol string, start, end time.Time) ([]types.StockData, error) {
+	url := fmt.Sprintf(
+		"https://www.alphavantage.co/query?function=TIME_SERIES_DAILY&symbol=%s&outputsize=full&apikey=%s",
+		symbol, p.apiKey,
+	)
+	series, err := p.fetchSeries(ctx, url, "Time Series (Daily)")
+	if err != nil {
+		return nil, err
+	}
+	return p.filterRange(symbol, series, start, end), nil
+}
+
+// FetchIntraday returns bars at the given interval, one of "1min", "5min",
+// "15min", "30min", or "60min" (Alpha Vantage's supported set).
+func (p *AlphaVantageProvider) FetchIntraday(ctx context.Context, symbol string, start, end time.Time, interval string) ([]types.StockData, error) {
+	if interval == "" {
+		interval = "5min"
+	}
+	url := fmt.Sprintf(
+		"https://www.alphavantage.co/query?function=TIME_SERIES_INTRADAY&symbol=%s&interval=%s&outputsize=full&apikey=%s",
+		symbol, interval, p.apiKey,
+	)
+	series, err := p.fetchSeries(ctx, url, fmt.Sprintf("Time Series (%s)", interval))
+	if err != nil {
+		return nil, err
+	}
+	return p.filterRange(symbol, series, start, end), nil
+}
+
+// alphaVantageBar is the per-timestamp object nested under the series key.
+type alphaVantageBar struct {
+	Open   string `json:"1. open"`
+	High   string `json:"2. high"`
+	Low    string `json:"3. low"`
+	Close  string `json:"4. close"`
+	Volume string `json:"5. volume"`
+}
+
+func (p *AlphaVantageProvider) fetchSeries(ctx context.Context, url, seriesKey string) (map[string]alphaVantageBar, error) {
+	if p.apiKey == "" {
+		return nil, fmt.Errorf("alphavantage: no API key configured (set ALPHAVANTAGE_API_KEY)")
+	}
+	if err := p.limiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("alphavantage: rate limit wait: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("alphavantage: failed to create request: %w", err)
+	}
+
+	resp, _, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("alphavantage: request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("alphavantage: %w", types.ClassifyHTTPStatus(resp.StatusCode))
+	}
+
+	var payload map[string]json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("alphavantage: %w: %v", types.ErrParse, err)
+	}
+
+	if msg, ok := payload["Error Message"]; ok {
+		var s string
+		json.Unmarshal(msg, &s)
+		return nil, fmt.Errorf("alphavantage: %s", s)
+	}
+	if msg, ok := payload["Note"]; ok {
+		var s string
+		json.Unmarshal(msg, &s)
+		return nil, fmt.Errorf("alphavantage: %s", s)
+	}
+
+	raw, ok := payload[seriesKey]
+	if !ok {
+		return nil, fmt.Errorf("alphavantage: %w: response missing %q", types.ErrNoData, seriesKey)
+	}
+
+	var series map[string]alphaVantageBar
+	if err := json.Unmarshal(raw, &series); err != nil {
+		return nil, fmt.Errorf("alphavantage: failed to decode series: %w", err)
+	}
+	return series, nil
+}
+
+func (p *AlphaVantageProvider) filterRange(symbol string, series map[string]alphaVantageBar, start, end time.Time) []types.StockData {
+	layout := "2006-01-02"
+	if len(series) > 0 {
+		for k := range series {
+			if len(k) > len(layout) {
+				layout = "2006-01-02 15:04:05"
+			}
+			break
+		}
+	}
+
+	data := make([]types.StockData, 0, len(series))
+	for ts, bar := range series {
+		date, err := time.Parse(layout, ts)
+		if err != nil || date.Before(start) || date.After(end) {
+			continue
+		}
+
+		open, _ := strconv.ParseFloat(bar.Open, 64)
+		high, _ := strconv.ParseFloat(bar.High, 64)
+		low, _ := strconv.ParseFloat(bar.Low, 64)
+		closePrice, _ := strconv.ParseFloat(bar.Close, 64)
+		volume, _ := strconv.ParseInt(bar.Volume, 10, 64)
+
+		data = append(data, types.StockData{
+			Symbol:   symbol,
+			Date:     date,
+			Open:     open,
+			High:     high,
+			Low:      low,
+			Close:    closePrice,
+			AdjClose: closePrice,
+			Volume:   volume,
+		})
+	}
+
+	sort.Slice(data, func(i, j int) bool { return data[i].Date.Before(data[j].Date) })
+	return data
+}
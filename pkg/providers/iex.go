@@ -0,0 +1,298 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/types"
+	"golang.org/x/time/rate"
+)
+
+// IssueType is IEX Cloud's single-letter classification of what kind of
+// security a symbol is (ad = ADR, cs = common stock, et = ETF, ...). It's
+// left as an opaque string rather than an enum of known letters: IEX adds
+// new ones over time and an unrecognized value shouldn't fail to decode.
+type IssueType string
+
+// AnnounceTime is when a company reported (or plans to report) earnings,
+// relative to market hours, as IEX Cloud encodes it.
+type AnnounceTime string
+
+// AnnounceTime values IEX Cloud's earnings endpoint returns.
+const (
+	AnnounceBeforeOpen  AnnounceTime = "BTO" // before the opening bell
+	AnnounceDuringHours AnnounceTime = "DMT" // during market hours
+	AnnounceAfterClose  AnnounceTime = "AMC" // after market close
+)
+
+// Earning is one quarter's reported or estimated earnings figures, as
+// returned by IEX Cloud's /stock/{symbol}/earnings endpoint.
+type Earning struct {
+	ActualEPS         float64      `json:"actualEPS"`
+	EstimatedEPS      float64      `json:"estimatedEPS"`
+	AnnounceTime      AnnounceTime `json:"announceTime"`
+	FiscalPeriod      string       `json:"fiscalPeriod"`
+	FiscalEndDate     string       `json:"fiscalEndDate"`
+	EPSSurpriseDollar float64      `json:"EPSSurpriseDollar"`
+	EPSReportDate     string       `json:"EPSReportDate"`
+}
+
+// iexBar mirrors one entry of IEX Cloud's /stock/{symbol}/chart response.
+type iexBar struct {
+	Date   string  `json:"date"`
+	Minute string  `json:"minute"`
+	Open   float64 `json:"open"`
+	High   float64 `json:"high"`
+	Low    float64 `json:"low"`
+	Close  float64 `json:"close"`
+	Volume int64   `json:"volume"`
+}
+
+// IEXProvider fetches bars from IEX Cloud's chart endpoint.
+type IEXProvider struct {
+	apiKey  string
+	baseURL string
+	client  *types.RetryableClient
+	limiter *rate.Limiter
+}
+
+// productionBaseURL and sandboxBaseURL are IEX Cloud's two API hosts.
+// Sandbox returns randomized placeholder data under the same schema, so
+// it's picked via IEX_SANDBOX rather than apiKey shape (sandbox and
+// production tokens aren't distinguishable by format alone).
+const (
+	productionBaseURL = "https://cloud.iexapis.com/stable"
+	sandboxBaseURL    = "https://sandbox.iexapis.com/stable"
+)
+
+// NewIEXProvider returns an IEXProvider using apiKey (an IEX Cloud token).
+// It targets IEX's sandbox host instead of production when IEX_SANDBOX is
+// set to a non-empty value.
+func NewIEXProvider(apiKey string) *IEXProvider {
+	baseURL := productionBaseURL
+	if os.Getenv("IEX_SANDBOX") != "" {
+		baseURL = sandboxBaseURL
+	}
+	return &IEXProvider{
+		apiKey:  apiKey,
+		baseURL: baseURL,
+		client:  types.NewRetryableClient(20 * time.Second),
+		limiter: rate.NewLimiter(rate.Every(200*time.Millisecond), 5),
+	}
+}
+
+// Name identifies this provider as "iex".
+func (p *IEXProvider) Name() string { return "iex" }
+
+// FetchDaily returns one row per trading day between start and end, using
+// IEX's date-range chart endpoint.
+func (p *IEXProvider) FetchDaily(ctx context.Context, symbol string, start, end time.Time) ([]types.StockData, error) {
+	// "5y" is the widest IEX range token; chartFromDate/chartToDate narrow
+	// it down to the actual window requested.
+	url := fmt.Sprintf("%s/stock/%s/chart/range/5y?chartFromDate=%s&chartToDate=%s&token=%s",
+		p.baseURL, symbol, start.Format("2006-01-02"), end.Format("2006-01-02"), p.apiKey)
+
+	bars, err := p.fetchBars(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]types.StockData, 0, len(bars))
+	for _, b := range bars {
+		date, err := time.Parse("2006-01-02", b.Date)
+		if err != nil {
+			continue
+		}
+		data = append(data, types.StockData{
+			Symbol:   symbol,
+			Date:     date,
+			Open:     b.Open,
+			High:     b.High,
+			Low:      b.Low,
+			Close:    b.Close,
+			AdjClose: b.Close,
+			Volume:   b.Volume,
+		})
+	}
+	return data, nil
+}
+
+// FetchIntraday returns minute bars for the trading day containing start.
+// IEX's free chart API only exposes one-minute granularity; interval is
+// accepted for interface symmetry but otherwise ignored.
+func (p *IEXProvider) FetchIntraday(ctx context.Context, symbol string, start, end time.Time, interval string) ([]types.StockData, error) {
+	url := fmt.Sprintf("%s/stock/%s/chart/date/%s?token=%s", p.baseURL, symbol, start.Format("20060102"), p.apiKey)
+
+	bars, err := p.fetchBars(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]types.StockData, 0, len(bars))
+	for _, b := range bars {
+		ts, err := time.Parse("2006-01-02 15:04", fmt.Sprintf("%s %s", b.Date, b.Minute))
+		if err != nil {
+			continue
+		}
+		if ts.Before(start) || ts.After(end) {
+			continue
+		}
+		data = append(data, types.StockData{
+			Symbol:   symbol,
+			Date:     ts,
+			Open:     b.Open,
+			High:     b.High,
+			Low:      b.Low,
+			Close:    b.Close,
+			AdjClose: b.Close,
+			Volume:   b.Volume,
+		})
+	}
+	return data, nil
+}
+
+func (p *IEXProvider) fetchBars(ctx context.Context, url string) ([]iexBar, error) {
+	var bars []iexBar
+	if err := p.get(ctx, url, &bars); err != nil {
+		return nil, err
+	}
+	return bars, nil
+}
+
+// iexQuote mirrors IEX Cloud's /stock/{symbol}/quote response, trimmed to
+// the fields Quote surfaces through types.StockData.
+type iexQuote struct {
+	Symbol        string    `json:"symbol"`
+	CompanyName   string    `json:"companyName"`
+	LatestPrice   float64   `json:"latestPrice"`
+	Change        float64   `json:"change"`
+	ChangePercent float64   `json:"changePercent"`
+	Volume        int64     `json:"latestVolume"`
+	MarketCap     int64     `json:"marketCap"`
+	PreviousClose float64   `json:"previousClose"`
+	Open          float64   `json:"open"`
+	High          float64   `json:"high"`
+	Low           float64   `json:"low"`
+	Close         float64   `json:"close"`
+	Week52High    float64   `json:"week52High"`
+	Week52Low     float64   `json:"week52Low"`
+	Week52Change  float64   `json:"week52Change"`
+	YTDChange     float64   `json:"ytdChange"`
+	PERatio       float64   `json:"peRatio"`
+	LatestUpdate  int64     `json:"latestUpdate"`
+	IssueType     IssueType `json:"issueType"`
+}
+
+// Quote returns IEX Cloud's real-time (or 15-minute delayed, on a free
+// plan) quote for symbol, including the peRatio/week52Change/ytdChange
+// fields that FetchDaily/FetchIntraday's chart endpoint doesn't return.
+func (p *IEXProvider) Quote(ctx context.Context, symbol string) (types.StockData, error) {
+	url := fmt.Sprintf("%s/stock/%s/quote?token=%s", p.baseURL, symbol, p.apiKey)
+
+	var q iexQuote
+	if err := p.get(ctx, url, &q); err != nil {
+		return types.StockData{}, err
+	}
+
+	return types.StockData{
+		Symbol:           q.Symbol,
+		CompanyName:      q.CompanyName,
+		Price:            q.LatestPrice,
+		ChangeAmount:     q.Change,
+		ChangePercent:    q.ChangePercent,
+		Volume:           q.Volume,
+		MarketCap:        q.MarketCap,
+		PreviousClose:    q.PreviousClose,
+		OpenPrice:        q.Open,
+		High:             q.High,
+		Low:              q.Low,
+		FiftyTwoWeekHigh: q.Week52High,
+		FiftyTwoWeekLow:  q.Week52Low,
+		LastUpdated:      q.LatestUpdate,
+		Close:            q.Close,
+		AdjClose:         q.Close,
+		Source:           p.Name(),
+		PERatio:          q.PERatio,
+		Week52Change:     q.Week52Change,
+		YTDChange:        q.YTDChange,
+	}, nil
+}
+
+// Chart returns daily bars for symbol over rangeToken (an IEX range token
+// such as "1m", "6m", "1y"), in the same shape FetchDaily produces.
+func (p *IEXProvider) Chart(ctx context.Context, symbol, rangeToken string) ([]types.HistoricalData, error) {
+	url := fmt.Sprintf("%s/stock/%s/chart/%s?token=%s", p.baseURL, symbol, rangeToken, p.apiKey)
+
+	bars, err := p.fetchBars(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]types.HistoricalData, 0, len(bars))
+	for _, b := range bars {
+		date, err := time.Parse("2006-01-02", b.Date)
+		if err != nil {
+			continue
+		}
+		data = append(data, types.HistoricalData{
+			Symbol:   symbol,
+			Date:     date,
+			Open:     b.Open,
+			High:     b.High,
+			Low:      b.Low,
+			Close:    b.Close,
+			AdjClose: b.Close,
+			Volume:   b.Volume,
+		})
+	}
+	return data, nil
+}
+
+// Earnings returns symbol's most recent reported quarters from IEX
+// Cloud's /stock/{symbol}/earnings endpoint.
+func (p *IEXProvider) Earnings(ctx context.Context, symbol string) ([]Earning, error) {
+	url := fmt.Sprintf("%s/stock/%s/earnings?token=%s", p.baseURL, symbol, p.apiKey)
+
+	var wrapper struct {
+		Earnings []Earning `json:"earnings"`
+	}
+	if err := p.get(ctx, url, &wrapper); err != nil {
+		return nil, err
+	}
+	return wrapper.Earnings, nil
+}
+
+// get issues a rate-limited, retried GET against url and decodes the JSON
+// response into out.
+func (p *IEXProvider) get(ctx context.Context, url string, out interface{}) error {
+	if p.apiKey == "" {
+		return fmt.Errorf("iex: no API key configured (set IEX_API_KEY)")
+	}
+	if err := p.limiter.Wait(ctx); err != nil {
+		return fmt.Errorf("iex: rate limit wait: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("iex: failed to create request: %w", err)
+	}
+
+	resp, _, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("iex: request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("iex: %w", types.ClassifyHTTPStatus(resp.StatusCode))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("iex: %w: %v", types.ErrParse, err)
+	}
+	return nil
+}
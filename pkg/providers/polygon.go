@@ -0,0 +1,134 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/types"
+	"golang.org/x/time/rate"
+)
+
+// polygonAggsResponse mirrors Polygon's /v2/aggs/ticker response.
+type polygonAggsResponse struct {
+	Results []struct {
+		O float64 `json:"o"`
+		H float64 `json:"h"`
+		L float64 `json:"l"`
+		C float64 `json:"c"`
+		V float64 `json:"v"`
+		T int64   `json:"t"` // Unix ms
+	} `json:"results"`
+	Status       string `json:"status"`
+	ErrorMessage string `json:"error"`
+}
+
+// PolygonProvider fetches bars from Polygon.io's aggregates (OHLC bars)
+// endpoint.
+type PolygonProvider struct {
+	apiKey  string
+	baseURL string
+	client  *types.RetryableClient
+	limiter *rate.Limiter
+}
+
+// NewPolygonProvider returns a PolygonProvider using apiKey. Its limiter
+// matches Polygon's free-tier rate of 5 requests/minute.
+func NewPolygonProvider(apiKey string) *PolygonProvider {
+	return &PolygonProvider{
+		apiKey:  apiKey,
+		baseURL: "https://api.polygon.io",
+		client:  types.NewRetryableClient(20 * time.Second),
+		limiter: rate.NewLimiter(rate.Every(12*time.Second), 1),
+	}
+}
+
+// Name identifies this provider as "polygon".
+func (p *PolygonProvider) Name() string { return "polygon" }
+
+// FetchDaily returns one row per trading day between start and end.
+func (p *PolygonProvider) FetchDaily(ctx context.Context, symbol string, start, end time.Time) ([]types.StockData, error) {
+	return p.fetchAggs(ctx, symbol, 1, "day", start, end)
+}
+
+// FetchIntraday returns bars at the given interval, e.g. "1m", "5m", "1h".
+// Polygon expects a (multiplier, timespan) pair, so interval is parsed
+// accordingly; an empty interval defaults to 5-minute bars.
+func (p *PolygonProvider) FetchIntraday(ctx context.Context, symbol string, start, end time.Time, interval string) ([]types.StockData, error) {
+	multiplier, timespan := parsePolygonInterval(interval)
+	return p.fetchAggs(ctx, symbol, multiplier, timespan, start, end)
+}
+
+func (p *PolygonProvider) fetchAggs(ctx context.Context, symbol string, multiplier int, timespan string, start, end time.Time) ([]types.StockData, error) {
+	if p.apiKey == "" {
+		return nil, fmt.Errorf("polygon: no API key configured (set POLYGON_API_KEY)")
+	}
+	if err := p.limiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("polygon: rate limit wait: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/v2/aggs/ticker/%s/range/%d/%s/%s/%s?adjusted=true&sort=asc&limit=50000&apiKey=%s",
+		p.baseURL, symbol, multiplier, timespan, start.Format("2006-01-02"), end.Format("2006-01-02"), p.apiKey)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("polygon: failed to create request: %w", err)
+	}
+
+	resp, _, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("polygon: request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("polygon: %w", types.ClassifyHTTPStatus(resp.StatusCode))
+	}
+
+	var payload polygonAggsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("polygon: %w: %v", types.ErrParse, err)
+	}
+	if payload.ErrorMessage != "" {
+		return nil, fmt.Errorf("polygon: %s", payload.ErrorMessage)
+	}
+
+	data := make([]types.StockData, 0, len(payload.Results))
+	for _, r := range payload.Results {
+		data = append(data, types.StockData{
+			Symbol:   symbol,
+			Date:     time.UnixMilli(r.T),
+			Open:     r.O,
+			High:     r.H,
+			Low:      r.L,
+			Close:    r.C,
+			AdjClose: r.C,
+			Volume:   int64(r.V),
+		})
+	}
+	return data, nil
+}
+
+// parsePolygonInterval maps a Yahoo-style interval string ("1m", "5m",
+// "1h", "1d") to Polygon's (multiplier, timespan) pair, defaulting to
+// 5-minute bars for anything it doesn't recognize.
+func parsePolygonInterval(interval string) (int, string) {
+	switch interval {
+	case "1m":
+		return 1, "minute"
+	case "5m", "":
+		return 5, "minute"
+	case "15m":
+		return 15, "minute"
+	case "30m":
+		return 30, "minute"
+	case "1h":
+		return 1, "hour"
+	case "1d":
+		return 1, "day"
+	default:
+		return 5, "minute"
+	}
+}
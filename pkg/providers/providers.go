@@ -0,0 +1,75 @@
+// Package providers abstracts historical and intraday market-data lookups
+// behind a common MarketDataProvider interface, so the web handlers can
+// switch data sources via config/env without changing any fetch logic.
+package providers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/types"
+)
+
+// MarketDataProvider fetches historical stock data from a single upstream
+// source (Yahoo Finance, Alpha Vantage, IEX Cloud, Polygon, ...).
+type MarketDataProvider interface {
+	// Name identifies the provider, e.g. for logging or the "provider" field
+	// in an API response. It matches the key it's registered under.
+	Name() string
+
+	// FetchDaily returns one row per trading day between start and end
+	// (inclusive), oldest first.
+	FetchDaily(ctx context.Context, symbol string, start, end time.Time) ([]types.StockData, error)
+
+	// FetchIntraday returns bars between start and end at the given bar
+	// size (e.g. "1m", "5m", "1h"). Providers that don't support a
+	// requested interval should return an error naming the ones they do.
+	FetchIntraday(ctx context.Context, symbol string, start, end time.Time, interval string) ([]types.StockData, error)
+}
+
+// DefaultProviderName is used when neither a query param nor the
+// MARKET_DATA_PROVIDER env var selects one.
+const DefaultProviderName = "yahoo"
+
+// factories builds a fresh provider instance per lookup so that each one
+// picks up the current environment (API keys can rotate without a restart).
+var factories = map[string]func() MarketDataProvider{
+	"yahoo":        func() MarketDataProvider { return NewYahooProvider() },
+	"alphavantage": func() MarketDataProvider { return NewAlphaVantageProvider(os.Getenv("ALPHAVANTAGE_API_KEY")) },
+	"iex":          func() MarketDataProvider { return NewIEXProvider(os.Getenv("IEX_API_KEY")) },
+	"polygon":      func() MarketDataProvider { return NewPolygonProvider(os.Getenv("POLYGON_API_KEY")) },
+}
+
+// Get returns the named provider. An empty name resolves to the
+// MARKET_DATA_PROVIDER env var, falling back to DefaultProviderName.
+func Get(name string) (MarketDataProvider, error) {
+	if name == "" {
+		name = os.Getenv("MARKET_DATA_PROVIDER")
+	}
+	if name == "" {
+		name = DefaultProviderName
+	}
+
+	factory, ok := factories[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown market data provider %q (want one of yahoo, alphavantage, iex, polygon)", name)
+	}
+	return factory(), nil
+}
+
+// sleepOrDone waits for d or until ctx is canceled, whichever comes first.
+// It reports whether the sleep ran to completion, so a retry loop's "sleep
+// then retry" backoff exits immediately on cancellation instead of idling
+// for a backoff nobody can use.
+func sleepOrDone(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,108 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGetDefaultsToYahoo(t *testing.T) {
+	p, err := Get("")
+	if err != nil {
+		t.Fatalf("Get(\"\") returned error: %v", err)
+	}
+	if p.Name() != DefaultProviderName {
+		t.Errorf("Get(\"\").Name() = %q, want %q", p.Name(), DefaultProviderName)
+	}
+}
+
+func TestGetUnknownProvider(t *testing.T) {
+	if _, err := Get("not-a-real-provider"); err == nil {
+		t.Error("expected Get to error for an unregistered provider name")
+	}
+}
+
+func TestGetEachRegisteredProvider(t *testing.T) {
+	for _, name := range []string{"yahoo", "alphavantage", "iex", "polygon"} {
+		p, err := Get(name)
+		if err != nil {
+			t.Fatalf("Get(%q) returned error: %v", name, err)
+		}
+		if p.Name() != name {
+			t.Errorf("Get(%q).Name() = %q, want %q", name, p.Name(), name)
+		}
+	}
+}
+
+func TestSleepOrDoneReturnsFalseOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if sleepOrDone(ctx, time.Minute) {
+		t.Error("sleepOrDone returned true on an already-canceled context")
+	}
+}
+
+func TestSleepOrDoneReturnsTrueWhenItElapses(t *testing.T) {
+	if !sleepOrDone(context.Background(), time.Millisecond) {
+		t.Error("sleepOrDone returned false for an uncanceled context")
+	}
+}
+
+func TestIEXQuoteParsesExtendedFields(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"symbol":       "AAPL",
+			"companyName":  "Apple Inc.",
+			"latestPrice":  190.5,
+			"peRatio":      31.2,
+			"week52Change": 0.18,
+			"ytdChange":    0.05,
+		})
+	}))
+	defer srv.Close()
+
+	p := NewIEXProvider("test-token")
+	p.baseURL = srv.URL
+
+	data, err := p.Quote(context.Background(), "AAPL")
+	if err != nil {
+		t.Fatalf("Quote: %v", err)
+	}
+	if data.PERatio != 31.2 || data.Week52Change != 0.18 || data.YTDChange != 0.05 {
+		t.Errorf("Quote did not carry through extended fields, got %+v", data)
+	}
+}
+
+func TestIEXEarningsParsesAnnounceTime(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"earnings": []map[string]interface{}{
+				{"actualEPS": 1.5, "announceTime": "AMC", "fiscalPeriod": "Q4 2025"},
+			},
+		})
+	}))
+	defer srv.Close()
+
+	p := NewIEXProvider("test-token")
+	p.baseURL = srv.URL
+
+	earnings, err := p.Earnings(context.Background(), "AAPL")
+	if err != nil {
+		t.Fatalf("Earnings: %v", err)
+	}
+	if len(earnings) != 1 || earnings[0].AnnounceTime != AnnounceAfterClose {
+		t.Errorf("Earnings = %+v, want one entry with AnnounceTime %q", earnings, AnnounceAfterClose)
+	}
+}
+
+func TestNewIEXProviderSandboxEnv(t *testing.T) {
+	t.Setenv("IEX_SANDBOX", "1")
+	p := NewIEXProvider("test-token")
+	if p.baseURL != sandboxBaseURL {
+		t.Errorf("baseURL = %q, want sandbox URL %q", p.baseURL, sandboxBaseURL)
+	}
+}
@@ -0,0 +1,205 @@
+package providers
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/types"
+	"github.com/darianmavgo/backtest-sell-limit/pkg/yahoosession"
+)
+
+// yahooFetchTimeout bounds a single symbol's fetch (including retries), so
+// a stuck Yahoo connection can't outlive the caller's own context by more
+// than this regardless of how many retries it burns through.
+const yahooFetchTimeout = 20 * time.Second
+
+// yahooChartResponse mirrors the subset of Yahoo Finance's chart API
+// response this provider cares about.
+type yahooChartResponse struct {
+	Chart struct {
+		Result []struct {
+			Timestamp  []int64 `json:"timestamp"`
+			Indicators struct {
+				Quote []struct {
+					Open   []float64 `json:"open"`
+					High   []float64 `json:"high"`
+					Low    []float64 `json:"low"`
+					Close  []float64 `json:"close"`
+					Volume []int64   `json:"volume"`
+				} `json:"quote"`
+				Adjclose []struct {
+					Adjclose []float64 `json:"adjclose"`
+				} `json:"adjclose"`
+			} `json:"indicators"`
+		} `json:"result"`
+		Error *struct {
+			Description string `json:"description"`
+		} `json:"error"`
+	} `json:"chart"`
+}
+
+// YahooProvider fetches historical and intraday bars from Yahoo Finance's
+// chart API, which now requires a consent cookie and crumb token on every
+// request, and informally rate-limits by IP. Both are handled by a single
+// process-wide yahoosession.Session shared with pkg/marketdata's
+// YahooProvider (see pkg/yahoosession), since Get builds a fresh
+// YahooProvider on each lookup.
+type YahooProvider struct {
+	client  *types.RetryableClient
+	session *yahoosession.Session
+}
+
+// NewYahooProvider returns a YahooProvider ready to use.
+func NewYahooProvider() *YahooProvider {
+	return &YahooProvider{
+		client:  types.NewRetryableClient(yahooFetchTimeout),
+		session: yahoosession.Get(),
+	}
+}
+
+// Name identifies this provider as "yahoo".
+func (p *YahooProvider) Name() string { return "yahoo" }
+
+// FetchDaily returns one row per trading day between start and end.
+func (p *YahooProvider) FetchDaily(ctx context.Context, symbol string, start, end time.Time) ([]types.StockData, error) {
+	return p.fetch(ctx, symbol, start, end, "1d")
+}
+
+// FetchIntraday returns bars at the given interval (e.g. "1m", "5m", "1h"),
+// which Yahoo's chart API accepts directly.
+func (p *YahooProvider) FetchIntraday(ctx context.Context, symbol string, start, end time.Time, interval string) ([]types.StockData, error) {
+	if interval == "" {
+		interval = "1m"
+	}
+	return p.fetch(ctx, symbol, start, end, interval)
+}
+
+// maxYahooSessionRetries bounds how many times fetch will refresh the
+// shared yahooSession and retry after a 401/403/999 before giving up.
+const maxYahooSessionRetries = 2
+
+func (p *YahooProvider) fetch(ctx context.Context, symbol string, start, end time.Time, interval string) ([]types.StockData, error) {
+	ctx, cancel := context.WithTimeout(ctx, yahooFetchTimeout)
+	defer cancel()
+
+	var resp *http.Response
+	for attempt := 0; ; attempt++ {
+		if err := p.session.Wait(ctx); err != nil {
+			return nil, fmt.Errorf("yahoo: rate limit wait: %w", err)
+		}
+
+		url := fmt.Sprintf(
+			"https://query1.finance.yahoo.com/v8/finance/chart/%s?period1=%d&period2=%d&interval=%s&includeAdjustedClose=true",
+			symbol,
+			start.Unix(),
+			end.Unix(),
+			interval,
+		)
+
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("yahoo: failed to create request: %w", err)
+		}
+		if err := p.session.Attach(req); err != nil {
+			return nil, fmt.Errorf("yahoo: failed to attach session: %w", err)
+		}
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("Accept-Encoding", "gzip, deflate, br")
+		req.Header.Set("Origin", "https://finance.yahoo.com")
+		req.Header.Set("Referer", fmt.Sprintf("https://finance.yahoo.com/quote/%s", symbol))
+
+		resp, _, err = p.client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("yahoo: failed to fetch data: %w", err)
+		}
+
+		// 401/403 mean the crumb or cookies were rejected outright; Yahoo
+		// also uses the non-standard 999 status for its own "blocked,
+		// refresh and try again" signal. All three call for a fresh
+		// session rather than a plain retry.
+		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden || resp.StatusCode == 999 {
+			resp.Body.Close()
+			if attempt >= maxYahooSessionRetries-1 {
+				return nil, fmt.Errorf("yahoo: API request for %s still rejected (status %d) after refreshing session", symbol, resp.StatusCode)
+			}
+			if err := p.session.Refresh(); err != nil {
+				return nil, fmt.Errorf("yahoo: failed to refresh session: %w", err)
+			}
+			continue
+		}
+		break
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("yahoo: %w", types.ClassifyHTTPStatus(resp.StatusCode))
+	}
+
+	var reader io.Reader = resp.Body
+	if resp.Header.Get("Content-Encoding") == "gzip" {
+		gzReader, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("yahoo: failed to create gzip reader: %w", err)
+		}
+		defer gzReader.Close()
+		reader = gzReader
+	}
+
+	var chart yahooChartResponse
+	if err := json.NewDecoder(reader).Decode(&chart); err != nil {
+		return nil, fmt.Errorf("yahoo: %w: %v", types.ErrParse, err)
+	}
+	if chart.Chart.Error != nil {
+		return nil, fmt.Errorf("yahoo: %s", chart.Chart.Error.Description)
+	}
+	if len(chart.Chart.Result) == 0 {
+		return nil, fmt.Errorf("yahoo: %w for %s", types.ErrNoData, symbol)
+	}
+
+	result := chart.Chart.Result[0]
+	if len(result.Indicators.Quote) == 0 {
+		return nil, fmt.Errorf("yahoo: %w for %s", types.ErrNoData, symbol)
+	}
+	quote := result.Indicators.Quote[0]
+
+	var adjClose []float64
+	if len(result.Indicators.Adjclose) > 0 {
+		adjClose = result.Indicators.Adjclose[0].Adjclose
+	}
+
+	data := make([]types.StockData, 0, len(result.Timestamp))
+	for i, ts := range result.Timestamp {
+		d := types.StockData{
+			Symbol: symbol,
+			Date:   time.Unix(ts, 0),
+		}
+		if i < len(quote.Open) {
+			d.Open = quote.Open[i]
+		}
+		if i < len(quote.High) {
+			d.High = quote.High[i]
+		}
+		if i < len(quote.Low) {
+			d.Low = quote.Low[i]
+		}
+		if i < len(quote.Close) {
+			d.Close = quote.Close[i]
+		}
+		if i < len(quote.Volume) {
+			d.Volume = quote.Volume[i]
+		}
+		if i < len(adjClose) {
+			d.AdjClose = adjClose[i]
+		} else {
+			d.AdjClose = d.Close
+		}
+		data = append(data, d)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,230 @@
+// Package ratelimit provides a per-client, fixed-window request cap for
+// public HTTP handlers (the S&P 500 listing, historical-data fills,
+// backtests) so one caller hammering an endpoint can't starve everyone
+// else. Counting is abstracted behind the Store interface so the default
+// in-memory backend can later be swapped for a shared one (e.g. Redis)
+// without touching Middleware.
+package ratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/types"
+)
+
+// DefaultRPM is the per-client requests-per-minute cap used when
+// Config.RateLimitRPM isn't set.
+const DefaultRPM = 60
+
+// Store counts requests per key within the current fixed window. Window
+// is passed on every call (rather than fixed at construction) so one
+// Store can back multiple Limiters with different windows.
+type Store interface {
+	// Inc increments key's count for the window containing now and
+	// returns the updated count.
+	Inc(ctx context.Context, key string, window time.Duration) (int, error)
+	// Get returns key's count for the window containing now, without
+	// incrementing it.
+	Get(ctx context.Context, key string, window time.Duration) (int, error)
+}
+
+// windowCount is one key's count for the window it was last touched in;
+// a stale windowStart is overwritten rather than accumulated. window is
+// kept alongside it (rather than assumed fixed) so sweepLocked can tell a
+// merely-rolled-over entry from one whose client hasn't been seen again
+// since its window closed.
+type windowCount struct {
+	windowStart time.Time
+	window      time.Duration
+	count       int
+}
+
+// sweepInterval bounds how often Inc/Get opportunistically prune expired
+// entries from MemoryStore's map, so a long-running process doesn't
+// accumulate one entry per distinct client forever.
+const sweepInterval = 5 * time.Minute
+
+// MemoryStore is a process-local Store backed by a mutex-guarded map. It's
+// the default backend; a Redis-backed Store sharing counts across
+// instances can implement the same interface later without any Middleware
+// changes.
+type MemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]*windowCount
+	lastSweep time.Time
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]*windowCount)}
+}
+
+func (s *MemoryStore) Inc(_ context.Context, key string, window time.Duration) (int, error) {
+	now := time.Now()
+	start := windowStart(now, window)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweepLocked(now)
+
+	e, ok := s.entries[key]
+	if !ok || e.windowStart != start {
+		e = &windowCount{windowStart: start, window: window}
+		s.entries[key] = e
+	}
+	e.count++
+	return e.count, nil
+}
+
+func (s *MemoryStore) Get(_ context.Context, key string, window time.Duration) (int, error) {
+	now := time.Now()
+	start := windowStart(now, window)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweepLocked(now)
+
+	e, ok := s.entries[key]
+	if !ok || e.windowStart != start {
+		return 0, nil
+	}
+	return e.count, nil
+}
+
+// sweepLocked deletes entries whose window closed more than a window ago,
+// at most once per sweepInterval. The caller must hold s.mu.
+func (s *MemoryStore) sweepLocked(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, e := range s.entries {
+		if now.Sub(e.windowStart) > e.window {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// windowStart aligns t to the start of the window it falls in, so
+// MemoryStore.Inc/Get and Limiter.retryAfter agree on when a window (and
+// thus a client's count) resets.
+func windowStart(t time.Time, window time.Duration) time.Time {
+	return t.Truncate(window)
+}
+
+// Limiter enforces a per-client cap of Limit requests per Window, backed
+// by Store.
+type Limiter struct {
+	Store  Store
+	Limit  int
+	Window time.Duration
+
+	// TrustedProxies, if set, are the only addresses clientKey will take
+	// X-Forwarded-For from; a request whose RemoteAddr isn't in one of
+	// these ranges is keyed on RemoteAddr regardless of what XFF header it
+	// sends, since an untrusted caller can set XFF to anything (including
+	// a fresh value on every request) to dodge its own rate limit. Unset
+	// (the default) means RemoteAddr is always used. See ParseTrustedProxies.
+	TrustedProxies []*net.IPNet
+}
+
+// NewLimiter returns a Limiter capping each client to limit requests per
+// window, counted by store.
+func NewLimiter(store Store, limit int, window time.Duration) *Limiter {
+	return &Limiter{Store: store, Limit: limit, Window: window}
+}
+
+// Middleware wraps next so requests over l's cap get a 429 with a
+// Retry-After header instead of reaching next. Store errors fail open
+// (the request is let through) since a broken rate-limit backend
+// shouldn't take the whole endpoint down with it.
+func (l *Limiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := l.clientKey(r)
+
+		count, err := l.Store.Inc(r.Context(), key, l.Window)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if count > l.Limit {
+			retryAfter := windowStart(time.Now(), l.Window).Add(l.Window).Sub(time.Now())
+			w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			json.NewEncoder(w).Encode(types.HandlerResponse{
+				Success: false,
+				Message: "rate limit exceeded, try again later",
+			})
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clientKey identifies the caller a request should be rate-limited under:
+// r.RemoteAddr's host with any port stripped, unless RemoteAddr is itself
+// one of l.TrustedProxies, in which case the first X-Forwarded-For entry
+// is used instead (that's the real client IP a trusted reverse proxy
+// reports; an untrusted direct caller can't spoof its way into a fresh
+// bucket by setting the header itself).
+func (l *Limiter) clientKey(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	if len(l.TrustedProxies) == 0 {
+		return host
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !ipInAny(ip, l.TrustedProxies) {
+		return host
+	}
+
+	fwd := r.Header.Get("X-Forwarded-For")
+	if fwd == "" {
+		return host
+	}
+	if i := strings.IndexByte(fwd, ','); i >= 0 {
+		return strings.TrimSpace(fwd[:i])
+	}
+	return strings.TrimSpace(fwd)
+}
+
+// ipInAny reports whether ip falls within any of nets.
+func ipInAny(ip net.IP, nets []*net.IPNet) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTrustedProxies parses cidrs (e.g. "10.0.0.0/8") into the form
+// Limiter.TrustedProxies expects, for a caller wiring trusted reverse
+// proxy ranges in from config (e.g. Config.TrustedProxyCIDRs).
+func ParseTrustedProxies(cidrs []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, c := range cidrs {
+		_, ipNet, err := net.ParseCIDR(c)
+		if err != nil {
+			return nil, fmt.Errorf("ratelimit: invalid trusted proxy CIDR %q: %w", c, err)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
@@ -0,0 +1,146 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLimiterAllowsUpToLimit(t *testing.T) {
+	l := NewLimiter(NewMemoryStore(), 2, time.Minute)
+	handler := l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/sp500", nil)
+		req.RemoteAddr = "203.0.113.1:5555"
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("request %d: got status %d, want 200", i, rec.Code)
+		}
+	}
+}
+
+func TestLimiterBlocksOverLimit(t *testing.T) {
+	l := NewLimiter(NewMemoryStore(), 1, time.Minute)
+	handler := l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/sp500", nil)
+	req.RemoteAddr = "203.0.113.2:5555"
+
+	first := httptest.NewRecorder()
+	handler.ServeHTTP(first, req)
+	if first.Code != http.StatusOK {
+		t.Fatalf("first request got status %d, want 200", first.Code)
+	}
+
+	second := httptest.NewRecorder()
+	handler.ServeHTTP(second, req)
+	if second.Code != http.StatusTooManyRequests {
+		t.Fatalf("second request got status %d, want 429", second.Code)
+	}
+	if second.Header().Get("Retry-After") == "" {
+		t.Error("expected a Retry-After header on a 429 response")
+	}
+}
+
+func TestLimiterTracksClientsIndependently(t *testing.T) {
+	l := NewLimiter(NewMemoryStore(), 1, time.Minute)
+	handler := l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for _, addr := range []string{"203.0.113.3:1111", "203.0.113.4:2222"} {
+		req := httptest.NewRequest(http.MethodGet, "/sp500", nil)
+		req.RemoteAddr = addr
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Errorf("client %s: got status %d, want 200", addr, rec.Code)
+		}
+	}
+}
+
+func TestClientKeyIgnoresForwardedForByDefault(t *testing.T) {
+	l := &Limiter{}
+	req := httptest.NewRequest(http.MethodGet, "/sp500", nil)
+	req.RemoteAddr = "203.0.113.5:9999"
+	req.Header.Set("X-Forwarded-For", "198.51.100.7, 203.0.113.5")
+
+	if got, want := l.clientKey(req), "203.0.113.5"; got != want {
+		t.Errorf("clientKey() = %q, want %q (an untrusted caller can't override its key via XFF)", got, want)
+	}
+}
+
+func TestClientKeyUsesForwardedForFromTrustedProxy(t *testing.T) {
+	proxies, err := ParseTrustedProxies([]string{"203.0.113.0/24"})
+	if err != nil {
+		t.Fatalf("ParseTrustedProxies returned error: %v", err)
+	}
+	l := &Limiter{TrustedProxies: proxies}
+
+	req := httptest.NewRequest(http.MethodGet, "/sp500", nil)
+	req.RemoteAddr = "203.0.113.5:9999"
+	req.Header.Set("X-Forwarded-For", "198.51.100.7, 203.0.113.5")
+
+	if got, want := l.clientKey(req), "198.51.100.7"; got != want {
+		t.Errorf("clientKey() = %q, want %q", got, want)
+	}
+}
+
+func TestClientKeyFallsBackToRemoteAddr(t *testing.T) {
+	l := &Limiter{}
+	req := httptest.NewRequest(http.MethodGet, "/sp500", nil)
+	req.RemoteAddr = "203.0.113.6:9999"
+
+	if got, want := l.clientKey(req), "203.0.113.6"; got != want {
+		t.Errorf("clientKey() = %q, want %q", got, want)
+	}
+}
+
+func TestParseTrustedProxiesRejectsInvalidCIDR(t *testing.T) {
+	if _, err := ParseTrustedProxies([]string{"not-a-cidr"}); err == nil {
+		t.Error("expected an error for an invalid CIDR")
+	}
+}
+
+func TestMemoryStoreSweepsExpiredEntries(t *testing.T) {
+	s := NewMemoryStore()
+	if _, err := s.Inc(nil, "stale-client", time.Millisecond); err != nil {
+		t.Fatalf("Inc returned error: %v", err)
+	}
+
+	// Force an immediate sweep (rather than waiting out sweepInterval) by
+	// backdating lastSweep, then let the 1ms window actually elapse.
+	s.mu.Lock()
+	s.lastSweep = time.Time{}
+	s.mu.Unlock()
+	time.Sleep(2 * time.Millisecond)
+
+	if _, err := s.Inc(nil, "other-client", time.Minute); err != nil {
+		t.Fatalf("Inc returned error: %v", err)
+	}
+
+	s.mu.Lock()
+	_, stillPresent := s.entries["stale-client"]
+	s.mu.Unlock()
+	if stillPresent {
+		t.Error("expected the sweep to evict the expired stale-client entry")
+	}
+}
+
+func TestMemoryStoreGetWithoutIncIsZero(t *testing.T) {
+	s := NewMemoryStore()
+	count, err := s.Get(nil, "unseen", time.Minute)
+	if err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("Get() = %d, want 0", count)
+	}
+}
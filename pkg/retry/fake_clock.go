@@ -0,0 +1,41 @@
+package retry
+
+import (
+	"sync"
+	"time"
+)
+
+// FakeClock is a Clock a test can advance deterministically instead of
+// waiting on real delays: After fires immediately (on a buffered
+// channel), advancing the clock's notion of Now by d first.
+//
+// now is guarded by mu because Ticker.run calls After from its own
+// goroutine while a test calls Now concurrently from the test goroutine.
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFakeClock returns a FakeClock starting at start.
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start}
+}
+
+// Now returns the fake clock's current time.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After advances the fake clock by d and returns a channel that's already
+// ready to receive, so a retry loop under test doesn't actually wait.
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	now := c.now
+	c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	ch <- now
+	return ch
+}
@@ -0,0 +1,202 @@
+// Package retry implements exponential backoff with jitter for a fetcher
+// that needs to retry a transient failure (a network error, a 429, or a
+// 5xx) without giving up after the very first attempt, while still
+// failing fast on an error that no amount of retrying will fix (a 404,
+// say) via Permanent.
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Clock abstracts time so a test can drive a Ticker's delays without
+// waiting on the wall clock; see FakeClock.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// realClock is the default Clock, backed by the standard library.
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// Config parameterizes a Ticker's delay sequence and overall budget:
+// delay(n) = min(MaxInterval, InitialInterval * Multiplier^n), perturbed
+// by ±RandomizationFactor, until MaxElapsedTime has passed since the
+// Ticker started (0 means no limit).
+type Config struct {
+	InitialInterval     time.Duration
+	Multiplier          float64
+	RandomizationFactor float64
+	MaxInterval         time.Duration
+	MaxElapsedTime      time.Duration
+
+	// Clock is used for Now/After instead of the wall clock when set; a
+	// test uses this to make retries resolve instantly. Nil uses the
+	// real clock.
+	Clock Clock
+}
+
+// DefaultConfig is a reasonable profile for retrying a single upstream
+// HTTP fetch: start at 500ms, back off ×1.5 with ±50% jitter up to 30s
+// between attempts, and give up after 2 minutes total.
+func DefaultConfig() Config {
+	return Config{
+		InitialInterval:     500 * time.Millisecond,
+		Multiplier:          1.5,
+		RandomizationFactor: 0.5,
+		MaxInterval:         30 * time.Second,
+		MaxElapsedTime:      2 * time.Minute,
+	}
+}
+
+// clock returns cfg.Clock, or the real clock if unset.
+func (cfg Config) clock() Clock {
+	if cfg.Clock != nil {
+		return cfg.Clock
+	}
+	return realClock{}
+}
+
+// Delay returns the backoff delay for attempt (0-indexed): InitialInterval
+// * Multiplier^attempt, capped at MaxInterval and perturbed by
+// ±RandomizationFactor.
+func (cfg Config) Delay(attempt int) time.Duration {
+	d := float64(cfg.InitialInterval) * math.Pow(cfg.Multiplier, float64(attempt))
+	if cfg.MaxInterval > 0 && d > float64(cfg.MaxInterval) {
+		d = float64(cfg.MaxInterval)
+	}
+	if cfg.RandomizationFactor > 0 {
+		delta := cfg.RandomizationFactor * d
+		d = d - delta + rand.Float64()*2*delta
+	}
+	if d < 0 {
+		d = 0
+	}
+	return time.Duration(d)
+}
+
+// permanentError wraps an error that should stop retries immediately,
+// distinguishing it from a transient one worth retrying.
+type permanentError struct{ err error }
+
+func (p *permanentError) Error() string { return p.err.Error() }
+func (p *permanentError) Unwrap() error { return p.err }
+
+// Permanent wraps err so IsPermanent reports true for it, marking it (and
+// anything that wraps it) as not worth retrying — e.g. a 4xx other than
+// 429, which no amount of waiting will turn into a 200.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err (or anything it wraps) was marked via
+// Permanent.
+func IsPermanent(err error) bool {
+	var perm *permanentError
+	return errors.As(err, &perm)
+}
+
+// Ticker sends on C once per attempt: immediately on the first send, then
+// after each successive Delay, until cfg.MaxElapsedTime (if nonzero) has
+// elapsed since the Ticker started, at which point it closes C. Stop ends
+// the ticker early.
+type Ticker struct {
+	C <-chan time.Time
+
+	cfg     Config
+	clock   Clock
+	start   time.Time
+	attempt int
+	c       chan time.Time
+	stop    chan struct{}
+	once    sync.Once
+}
+
+// NewTicker starts a Ticker per cfg. The caller is responsible for
+// calling Stop once it's done reading C, even after C has been drained or
+// closed, to release the background goroutine.
+func NewTicker(cfg Config) *Ticker {
+	clock := cfg.clock()
+	t := &Ticker{
+		cfg:   cfg,
+		clock: clock,
+		start: clock.Now(),
+		c:     make(chan time.Time),
+		stop:  make(chan struct{}),
+	}
+	t.C = t.c
+	go t.run()
+	return t
+}
+
+func (t *Ticker) run() {
+	defer close(t.c)
+	for {
+		if t.cfg.MaxElapsedTime > 0 && t.clock.Now().Sub(t.start) > t.cfg.MaxElapsedTime {
+			return
+		}
+
+		select {
+		case t.c <- t.clock.Now():
+		case <-t.stop:
+			return
+		}
+
+		select {
+		case <-t.clock.After(t.cfg.Delay(t.attempt)):
+		case <-t.stop:
+			return
+		}
+		t.attempt++
+	}
+}
+
+// Stop ends the ticker, closing C once its goroutine notices.
+func (t *Ticker) Stop() {
+	t.once.Do(func() { close(t.stop) })
+}
+
+// Do calls fn, retrying per cfg's Ticker until fn returns nil, a
+// Permanent-wrapped error, ctx is canceled, or cfg.MaxElapsedTime elapses
+// — whichever comes first. It returns fn's last error in any failing
+// case.
+func Do(ctx context.Context, cfg Config, fn func() error) error {
+	ticker := NewTicker(cfg)
+	defer ticker.Stop()
+
+	var lastErr error
+	for {
+		select {
+		case _, ok := <-ticker.C:
+			if !ok {
+				if lastErr == nil {
+					lastErr = fmt.Errorf("retry: gave up after %v with no attempts", cfg.MaxElapsedTime)
+				}
+				return lastErr
+			}
+
+			err := fn()
+			if err == nil {
+				return nil
+			}
+			if IsPermanent(err) {
+				return err
+			}
+			lastErr = err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestConfigDelayClampsToMaxInterval(t *testing.T) {
+	cfg := Config{InitialInterval: time.Second, Multiplier: 2, MaxInterval: 5 * time.Second}
+	if d := cfg.Delay(10); d != 5*time.Second {
+		t.Errorf("Delay(10) = %v, want clamped to MaxInterval 5s", d)
+	}
+}
+
+func TestConfigDelayJitterStaysWithinBounds(t *testing.T) {
+	cfg := Config{InitialInterval: time.Second, Multiplier: 1, RandomizationFactor: 0.5, MaxInterval: time.Minute}
+	for i := 0; i < 50; i++ {
+		d := cfg.Delay(0)
+		if d < 500*time.Millisecond || d > 1500*time.Millisecond {
+			t.Fatalf("Delay(0) = %v, want within ±50%% of 1s", d)
+		}
+	}
+}
+
+func TestTickerFiresImmediatelyThenBacksOff(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	cfg := Config{InitialInterval: time.Second, Multiplier: 1, MaxInterval: time.Second, Clock: clock}
+	ticker := NewTicker(cfg)
+	defer ticker.Stop()
+
+	if _, ok := <-ticker.C; !ok {
+		t.Fatal("expected an immediate first tick")
+	}
+	if _, ok := <-ticker.C; !ok {
+		t.Fatal("expected a second tick after the delay")
+	}
+	if clock.Now().Sub(time.Unix(0, 0)) < time.Second {
+		t.Errorf("fake clock only advanced %v, want at least 1s between ticks", clock.Now().Sub(time.Unix(0, 0)))
+	}
+}
+
+func TestTickerClosesAfterMaxElapsedTime(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	cfg := Config{InitialInterval: time.Minute, Multiplier: 1, MaxElapsedTime: time.Second, Clock: clock}
+	ticker := NewTicker(cfg)
+	defer ticker.Stop()
+
+	<-ticker.C
+	if _, ok := <-ticker.C; ok {
+		t.Error("expected the ticker to close C once MaxElapsedTime elapsed")
+	}
+}
+
+func TestDoRetriesUntilSuccess(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	cfg := Config{InitialInterval: time.Millisecond, Multiplier: 1, Clock: clock}
+
+	attempts := 0
+	err := Do(context.Background(), cfg, func() error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("transient")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3", attempts)
+	}
+}
+
+func TestDoStopsOnPermanentError(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	cfg := Config{InitialInterval: time.Millisecond, Multiplier: 1, Clock: clock}
+
+	attempts := 0
+	permanent := Permanent(errors.New("not found"))
+	err := Do(context.Background(), cfg, func() error {
+		attempts++
+		return permanent
+	})
+	if !errors.Is(err, permanent) && err.Error() != permanent.Error() {
+		t.Errorf("Do returned %v, want the permanent error", err)
+	}
+	if attempts != 1 {
+		t.Errorf("attempts = %d, want 1 (no retry after a permanent error)", attempts)
+	}
+}
+
+func TestDoGivesUpAfterMaxElapsedTime(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	cfg := Config{InitialInterval: time.Minute, Multiplier: 1, MaxElapsedTime: time.Second, Clock: clock}
+
+	attempts := 0
+	err := Do(context.Background(), cfg, func() error {
+		attempts++
+		return errors.New("still failing")
+	})
+	if err == nil {
+		t.Fatal("expected Do to give up and return an error")
+	}
+	if attempts != 1 {
+		t.Errorf("attempts = %d, want 1 (MaxElapsedTime elapses before a second tick)", attempts)
+	}
+}
+
+func TestIsPermanentRoundTrips(t *testing.T) {
+	plain := errors.New("plain")
+	if IsPermanent(plain) {
+		t.Error("IsPermanent(plain) = true, want false")
+	}
+	if !IsPermanent(Permanent(plain)) {
+		t.Error("IsPermanent(Permanent(plain)) = false, want true")
+	}
+}
@@ -0,0 +1,270 @@
+// Package secretscan finds credential-shaped substrings (AWS keys, GCP
+// service-account JSON, Slack tokens, PEM private keys, OpenAI keys, and
+// generic high-entropy tokens) in arbitrary text, so callers that ingest
+// third-party content (e.g. saveEmailToDB) can flag and redact them before
+// persisting it.
+package secretscan
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Finding is one detector match: which Detector found it, where in the
+// scanned text it starts and how long it is, and a partially-redacted
+// Sample safe to store/display without leaking the secret itself.
+type Finding struct {
+	Detector string
+	Offset   int
+	Length   int
+	Sample   string
+}
+
+// Detector looks for one kind of secret in data and returns every match it
+// finds.
+type Detector interface {
+	Name() string
+	Find(data []byte) []Finding
+}
+
+// Verifier is implemented by detectors that can confirm a matched secret
+// is still live, e.g. by calling the issuing provider's own auth-check
+// endpoint (mirroring TruffleHog's --verify-only flag).
+type Verifier interface {
+	Verify(ctx context.Context, secret string) (bool, error)
+}
+
+// redactSample returns a preview of secret with its middle masked out, for
+// storing alongside a Finding without persisting the live secret.
+func redactSample(secret []byte) string {
+	if len(secret) <= 8 {
+		return strings.Repeat("*", len(secret))
+	}
+	return string(secret[:4]) + strings.Repeat("*", len(secret)-6) + string(secret[len(secret)-2:])
+}
+
+// regexDetector implements Detector (and, when verify is set, Verifier)
+// for a single fixed pattern.
+type regexDetector struct {
+	name    string
+	pattern *regexp.Regexp
+	verify  func(ctx context.Context, secret string) (bool, error)
+}
+
+func (d *regexDetector) Name() string { return d.name }
+
+func (d *regexDetector) Find(data []byte) []Finding {
+	var findings []Finding
+	for _, loc := range d.pattern.FindAllIndex(data, -1) {
+		findings = append(findings, Finding{
+			Detector: d.name,
+			Offset:   loc[0],
+			Length:   loc[1] - loc[0],
+			Sample:   redactSample(data[loc[0]:loc[1]]),
+		})
+	}
+	return findings
+}
+
+func (d *regexDetector) Verify(ctx context.Context, secret string) (bool, error) {
+	if d.verify == nil {
+		return false, fmt.Errorf("secretscan: %s has no verifier", d.name)
+	}
+	return d.verify(ctx, secret)
+}
+
+// shannonEntropy returns the Shannon entropy, in bits per character, of s.
+func shannonEntropy(s string) float64 {
+	if len(s) == 0 {
+		return 0
+	}
+
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+
+	n := float64(len(s))
+	var entropy float64
+	for _, c := range counts {
+		p := float64(c) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// base64ish matches runs of characters that look like base64 (no way to
+// tell from the alphabet alone whether they're actually base64-encoded
+// data or just a long token; EntropyDetector narrows that down further by
+// entropy).
+var base64ish = regexp.MustCompile(`[A-Za-z0-9+/]{20,}={0,2}`)
+
+// EntropyDetector flags base64-ish tokens whose Shannon entropy is at
+// least MinEntropy, catching generic high-entropy secrets (API keys,
+// encryption keys, ...) that don't match any named provider's format.
+type EntropyDetector struct {
+	MinEntropy float64
+}
+
+// NewEntropyDetector returns an EntropyDetector flagging tokens at or
+// above minEntropy bits/char; TruffleHog-style scanners typically use
+// something around 4.5 for base64 alphabets.
+func NewEntropyDetector(minEntropy float64) *EntropyDetector {
+	return &EntropyDetector{MinEntropy: minEntropy}
+}
+
+func (d *EntropyDetector) Name() string { return "high-entropy-string" }
+
+func (d *EntropyDetector) Find(data []byte) []Finding {
+	var findings []Finding
+	for _, loc := range base64ish.FindAllIndex(data, -1) {
+		token := data[loc[0]:loc[1]]
+		if shannonEntropy(string(token)) >= d.MinEntropy {
+			findings = append(findings, Finding{
+				Detector: d.Name(),
+				Offset:   loc[0],
+				Length:   loc[1] - loc[0],
+				Sample:   redactSample(token),
+			})
+		}
+	}
+	return findings
+}
+
+// AWSAccessKeyDetector matches AWS access key IDs (AKIA...). It can't
+// verify them: confirming an access key is live (e.g. via STS
+// GetCallerIdentity) requires the matching secret key to sign the
+// request, which never appears next to the access key id alone.
+func AWSAccessKeyDetector() Detector {
+	return &regexDetector{
+		name:    "aws-access-key",
+		pattern: regexp.MustCompile(`AKIA[0-9A-Z]{16}`),
+	}
+}
+
+// GCPServiceAccountDetector matches the "type": "service_account" field
+// that identifies a pasted GCP service-account JSON key file.
+func GCPServiceAccountDetector() Detector {
+	return &regexDetector{
+		name:    "gcp-service-account-json",
+		pattern: regexp.MustCompile(`"type"\s*:\s*"service_account"`),
+	}
+}
+
+// SlackTokenDetector matches Slack bot/user/app/legacy tokens and verifies
+// them against the auth.test endpoint.
+func SlackTokenDetector() Detector {
+	return &regexDetector{
+		name:    "slack-token",
+		pattern: regexp.MustCompile(`xox[baprs]-[0-9A-Za-z-]{10,72}`),
+		verify:  verifySlackToken,
+	}
+}
+
+// PEMPrivateKeyDetector matches the header line of a PEM-encoded private
+// key. There's no metadata endpoint to check a private key's liveness
+// against, so this detector can't verify.
+func PEMPrivateKeyDetector() Detector {
+	return &regexDetector{
+		name:    "pem-private-key",
+		pattern: regexp.MustCompile(`-----BEGIN ((RSA|EC|DSA|OPENSSH) )?PRIVATE KEY-----`),
+	}
+}
+
+// OpenAIKeyDetector matches OpenAI API keys and verifies them against the
+// /v1/models endpoint.
+func OpenAIKeyDetector() Detector {
+	return &regexDetector{
+		name:    "openai-api-key",
+		pattern: regexp.MustCompile(`sk-[A-Za-z0-9]{20,}`),
+		verify:  verifyOpenAIKey,
+	}
+}
+
+// DefaultDetectors returns the detector set Scanner runs with if none are
+// given explicitly: every named-provider detector plus a generic
+// high-entropy fallback at a threshold of 4.5 bits/char.
+func DefaultDetectors() []Detector {
+	return []Detector{
+		AWSAccessKeyDetector(),
+		GCPServiceAccountDetector(),
+		SlackTokenDetector(),
+		PEMPrivateKeyDetector(),
+		OpenAIKeyDetector(),
+		NewEntropyDetector(4.5),
+	}
+}
+
+// Scanner runs a fixed set of Detectors over text.
+type Scanner struct {
+	detectors []Detector
+}
+
+// NewScanner returns a Scanner running detectors, in the order given.
+func NewScanner(detectors ...Detector) *Scanner {
+	return &Scanner{detectors: detectors}
+}
+
+// DefaultScanner returns a Scanner running DefaultDetectors.
+func DefaultScanner() *Scanner {
+	return NewScanner(DefaultDetectors()...)
+}
+
+// Scan runs every detector over data and returns their combined findings,
+// ordered by Offset.
+func (s *Scanner) Scan(data []byte) []Finding {
+	var findings []Finding
+	for _, d := range s.detectors {
+		findings = append(findings, d.Find(data)...)
+	}
+	sort.Slice(findings, func(i, j int) bool { return findings[i].Offset < findings[j].Offset })
+	return findings
+}
+
+// mergeOverlapping collapses a set of findings that may overlap (e.g. a
+// named detector and EntropyDetector both matching the same token) into
+// non-overlapping spans, so Redact never replaces a byte range twice or
+// slices into the middle of a span it already cut out. Findings are
+// merged by union: an overlapping run of findings becomes one span
+// covering all of them, keeping the first (lowest-offset) finding's
+// Detector for the combined span.
+func mergeOverlapping(findings []Finding) []Finding {
+	ordered := append([]Finding(nil), findings...)
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].Offset < ordered[j].Offset })
+
+	var merged []Finding
+	for _, f := range ordered {
+		if n := len(merged); n > 0 {
+			last := &merged[n-1]
+			lastEnd := last.Offset + last.Length
+			if f.Offset < lastEnd {
+				if end := f.Offset + f.Length; end > lastEnd {
+					last.Length = end - last.Offset
+				}
+				continue
+			}
+		}
+		merged = append(merged, f)
+	}
+	return merged
+}
+
+// Redact returns a copy of data with every finding's matched substring
+// replaced by "[REDACTED:<detector>]". Overlapping findings (see
+// mergeOverlapping) are merged into one redacted span first, so a token
+// matched by more than one detector is only ever redacted once.
+func Redact(data []byte, findings []Finding) []byte {
+	ordered := mergeOverlapping(findings)
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].Offset > ordered[j].Offset })
+
+	out := append([]byte(nil), data...)
+	for _, f := range ordered {
+		replacement := []byte(fmt.Sprintf("[REDACTED:%s]", f.Detector))
+		out = append(out[:f.Offset], append(replacement, out[f.Offset+f.Length:]...)...)
+	}
+	return out
+}
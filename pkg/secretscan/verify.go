@@ -0,0 +1,50 @@
+package secretscan
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// verifySlackToken calls Slack's auth.test endpoint with secret as a
+// bearer token; Slack reports {"ok": true/false} regardless of HTTP
+// status, so a successful call's body is the source of truth.
+func verifySlackToken(ctx context.Context, secret string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://slack.com/api/auth.test", nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Authorization", "Bearer "+secret)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		OK bool `json:"ok"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return false, err
+	}
+	return result.OK, nil
+}
+
+// verifyOpenAIKey calls OpenAI's /v1/models endpoint with secret as a
+// bearer token; a 200 means the key is live.
+func verifyOpenAIKey(ctx context.Context, secret string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.openai.com/v1/models", nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Authorization", "Bearer "+secret)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode == http.StatusOK, nil
+}
@@ -0,0 +1,91 @@
+package sink
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/types"
+)
+
+// bigQueryRow is the BigQuery streaming-insert row shape for one historical
+// bar. It implements bigquery.ValueSaver's Save method structurally
+// (returning map[string]bigquery.Value would require importing the client
+// library here; Inserter below takes that conversion as a caller-supplied
+// function instead, so this package has no cloud.google.com/go dependency).
+type bigQueryRow struct {
+	Symbol   string
+	Date     int64
+	Open     float64
+	High     float64
+	Low      float64
+	Close    float64
+	AdjClose float64
+	Volume   int64
+}
+
+// Inserter is the subset of *bigquery.Inserter's Put method BigQuerySink
+// needs. The real *bigquery.Inserter satisfies this as-is; tests can swap
+// in a fake without touching BigQuery.
+type Inserter interface {
+	Put(ctx context.Context, rows interface{}) error
+}
+
+// BigQuerySink streams rows to a BigQuery table via an injected Inserter,
+// batching up to batchSize rows per Put call. It holds no GCP client
+// directly: callers construct their own *bigquery.Client/Inserter (using
+// Config.ProjectID and Config.Secrets().ServiceAccountJSON) and pass it in,
+// keeping this package's dependency footprint out of the BigQuery SDK.
+type BigQuerySink struct {
+	ins       Inserter
+	batchSize int
+
+	buf []bigQueryRow
+}
+
+// NewBigQuerySink returns a BigQuerySink that streams through ins,
+// buffering up to batchSize rows per Put call (a non-positive value falls
+// back to 500, matching pkg/store's batch size for bulk inserts).
+func NewBigQuerySink(ins Inserter, batchSize int) *BigQuerySink {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	return &BigQuerySink{ins: ins, batchSize: batchSize}
+}
+
+// Write buffers rows and streams them to BigQuery in batchSize chunks.
+func (s *BigQuerySink) Write(ctx context.Context, rows []types.HistoricalData) error {
+	for _, r := range rows {
+		s.buf = append(s.buf, bigQueryRow{
+			Symbol:   r.Symbol,
+			Date:     r.Date.Unix(),
+			Open:     r.Open,
+			High:     r.High,
+			Low:      r.Low,
+			Close:    r.Close,
+			AdjClose: r.AdjClose,
+			Volume:   r.Volume,
+		})
+		if len(s.buf) >= s.batchSize {
+			if err := s.flushBatch(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Flush streams any rows still buffered.
+func (s *BigQuerySink) Flush() error {
+	return s.flushBatch(context.Background())
+}
+
+func (s *BigQuerySink) flushBatch(ctx context.Context) error {
+	if len(s.buf) == 0 {
+		return nil
+	}
+	if err := s.ins.Put(ctx, s.buf); err != nil {
+		return fmt.Errorf("sink: bigquery: Put failed for %d rows: %w", len(s.buf), err)
+	}
+	s.buf = s.buf[:0]
+	return nil
+}
@@ -0,0 +1,57 @@
+// Package sink provides types.Sink implementations for the destinations a
+// backtest run can emit results to (BigQuery, Pub/Sub, local Parquet
+// files), plus MultiSink to fan a single run out to several of them at
+// once. Each implementation owns its own encoder (JSON, Arrow, Parquet) so
+// the backtest engine always produces the same []types.HistoricalData row
+// blocks and never encodes bytes itself.
+package sink
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/types"
+)
+
+// MultiSink fans Write/Flush out to every sink it wraps, isolating one
+// sink's failure from the others: a BigQuery outage shouldn't stop rows
+// from reaching Pub/Sub or disk.
+type MultiSink struct {
+	sinks []types.Sink
+}
+
+// NewMultiSink returns a MultiSink that fans out to every sink given.
+func NewMultiSink(sinks ...types.Sink) *MultiSink {
+	return &MultiSink{sinks: sinks}
+}
+
+// Write sends rows to every wrapped sink, continuing past individual
+// failures and returning a combined error naming which sinks failed.
+func (m *MultiSink) Write(ctx context.Context, rows []types.HistoricalData) error {
+	var errs []string
+	for i, s := range m.sinks {
+		if err := s.Write(ctx, rows); err != nil {
+			errs = append(errs, fmt.Sprintf("sink[%d]: %v", i, err))
+		}
+	}
+	return joinErrs(errs)
+}
+
+// Flush flushes every wrapped sink, continuing past individual failures.
+func (m *MultiSink) Flush() error {
+	var errs []string
+	for i, s := range m.sinks {
+		if err := s.Flush(); err != nil {
+			errs = append(errs, fmt.Sprintf("sink[%d]: %v", i, err))
+		}
+	}
+	return joinErrs(errs)
+}
+
+func joinErrs(errs []string) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("sink: %d of N sinks failed: %s", len(errs), strings.Join(errs, "; "))
+}
@@ -0,0 +1,106 @@
+package sink
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/types"
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/source"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// parquetRow is the on-disk row shape for ParquetSink, mirroring
+// historicalExportRow in the root package's export.go.
+type parquetRow struct {
+	Symbol   string  `parquet:"name=symbol, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Date     int64   `parquet:"name=date, type=INT64"`
+	Open     float64 `parquet:"name=open, type=DOUBLE"`
+	High     float64 `parquet:"name=high, type=DOUBLE"`
+	Low      float64 `parquet:"name=low, type=DOUBLE"`
+	Close    float64 `parquet:"name=close, type=DOUBLE"`
+	AdjClose float64 `parquet:"name=adj_close, type=DOUBLE"`
+	Volume   int64   `parquet:"name=volume, type=INT64"`
+}
+
+// ParquetSink writes rows to a single local Parquet file, flushing a new
+// row group every rowGroupSize rows so memory use stays bounded regardless
+// of how many rows a backtest run emits.
+type ParquetSink struct {
+	rowGroupSize int
+
+	mu      sync.Mutex
+	fw      source.ParquetFile
+	pw      *writer.ParquetWriter
+	written int
+}
+
+// NewParquetSink opens path for writing and returns a ParquetSink that
+// flushes a row group every rowGroupSize rows (a non-positive value falls
+// back to 50000, matching export.go's exportRowGroupSize).
+func NewParquetSink(path string, rowGroupSize int) (*ParquetSink, error) {
+	if rowGroupSize <= 0 {
+		rowGroupSize = 50000
+	}
+
+	fw, err := local.NewLocalFileWriter(path)
+	if err != nil {
+		return nil, fmt.Errorf("sink: parquet: failed to open %s: %w", path, err)
+	}
+
+	pw, err := writer.NewParquetWriter(fw, new(parquetRow), 1)
+	if err != nil {
+		fw.Close()
+		return nil, fmt.Errorf("sink: parquet: failed to create writer: %w", err)
+	}
+
+	return &ParquetSink{rowGroupSize: rowGroupSize, fw: fw, pw: pw}, nil
+}
+
+// Write appends rows to the Parquet file, flushing a row group whenever
+// rowGroupSize rows have been written.
+func (s *ParquetSink) Write(ctx context.Context, rows []types.HistoricalData) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, r := range rows {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := s.pw.Write(parquetRow{
+			Symbol:   r.Symbol,
+			Date:     r.Date.Unix(),
+			Open:     r.Open,
+			High:     r.High,
+			Low:      r.Low,
+			Close:    r.Close,
+			AdjClose: r.AdjClose,
+			Volume:   r.Volume,
+		}); err != nil {
+			return fmt.Errorf("sink: parquet: failed to write row: %w", err)
+		}
+
+		s.written++
+		if s.written%s.rowGroupSize == 0 {
+			if err := s.pw.Flush(true); err != nil {
+				return fmt.Errorf("sink: parquet: failed to flush row group: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// Flush finishes the Parquet file (writing its footer) and closes it.
+// ParquetSink isn't reusable after Flush.
+func (s *ParquetSink) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.pw.WriteStop(); err != nil {
+		s.fw.Close()
+		return fmt.Errorf("sink: parquet: failed to finalize file: %w", err)
+	}
+	return s.fw.Close()
+}
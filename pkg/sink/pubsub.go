@@ -0,0 +1,81 @@
+package sink
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/types"
+)
+
+// Message is a single outgoing message: Publisher implementations for the
+// real Pub/Sub client adapt this to *pubsub.Message (Data/Attributes map
+// onto it directly), so PubSubSink doesn't need to import
+// cloud.google.com/go/pubsub itself.
+type Message struct {
+	Data       []byte
+	Attributes map[string]string
+}
+
+// Publisher is the subset of *pubsub.Topic PubSubSink needs. The real
+// *pubsub.Topic satisfies this via a thin adapter (Publish wraps msg in a
+// *pubsub.Message and waits on the returned PublishResult); tests can swap
+// in a fake without touching Pub/Sub.
+type Publisher interface {
+	Publish(ctx context.Context, msg *Message) error
+}
+
+// PubSubSink batches rows by symbol and publishes one gzipped JSON message
+// per symbol per Flush, with the symbol carried as a message attribute so
+// subscribers can route without decompressing the body first.
+type PubSubSink struct {
+	pub Publisher
+
+	buf map[string][]types.HistoricalData
+}
+
+// NewPubSubSink returns a PubSubSink that publishes through pub.
+func NewPubSubSink(pub Publisher) *PubSubSink {
+	return &PubSubSink{pub: pub, buf: make(map[string][]types.HistoricalData)}
+}
+
+// Write appends rows to their per-symbol buffer; nothing is published
+// until Flush.
+func (s *PubSubSink) Write(ctx context.Context, rows []types.HistoricalData) error {
+	for _, r := range rows {
+		s.buf[r.Symbol] = append(s.buf[r.Symbol], r)
+	}
+	return nil
+}
+
+// Flush gzips and publishes each symbol's buffered rows as one JSON
+// message, then clears the buffer.
+func (s *PubSubSink) Flush() error {
+	ctx := context.Background()
+	for symbol, rows := range s.buf {
+		body, err := json.Marshal(rows)
+		if err != nil {
+			return fmt.Errorf("sink: pubsub: failed to encode %s: %w", symbol, err)
+		}
+
+		var gzBody bytes.Buffer
+		gw := gzip.NewWriter(&gzBody)
+		if _, err := gw.Write(body); err != nil {
+			return fmt.Errorf("sink: pubsub: failed to gzip %s: %w", symbol, err)
+		}
+		if err := gw.Close(); err != nil {
+			return fmt.Errorf("sink: pubsub: failed to gzip %s: %w", symbol, err)
+		}
+
+		if err := s.pub.Publish(ctx, &Message{
+			Data:       gzBody.Bytes(),
+			Attributes: map[string]string{"symbol": symbol, "content-encoding": "gzip"},
+		}); err != nil {
+			return fmt.Errorf("sink: pubsub: failed to publish %s: %w", symbol, err)
+		}
+		delete(s.buf, symbol)
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package sink
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/types"
+)
+
+func sampleRows() []types.HistoricalData {
+	return []types.HistoricalData{
+		{Symbol: "AAPL", Date: time.Unix(1700000000, 0).UTC(), Open: 1, High: 2, Low: 0.5, Close: 1.5, AdjClose: 1.5, Volume: 100},
+		{Symbol: "MSFT", Date: time.Unix(1700000000, 0).UTC(), Open: 2, High: 3, Low: 1.5, Close: 2.5, AdjClose: 2.5, Volume: 200},
+	}
+}
+
+type fakeSink struct {
+	writeErr error
+	flushErr error
+	wrote    int
+	flushed  bool
+}
+
+func (f *fakeSink) Write(ctx context.Context, rows []types.HistoricalData) error {
+	f.wrote += len(rows)
+	return f.writeErr
+}
+
+func (f *fakeSink) Flush() error {
+	f.flushed = true
+	return f.flushErr
+}
+
+func TestMultiSinkFansOutToEverySink(t *testing.T) {
+	a, b := &fakeSink{}, &fakeSink{}
+	m := NewMultiSink(a, b)
+
+	if err := m.Write(context.Background(), sampleRows()); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := m.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	if a.wrote != 2 || b.wrote != 2 {
+		t.Errorf("wrote = %d, %d; want 2, 2", a.wrote, b.wrote)
+	}
+	if !a.flushed || !b.flushed {
+		t.Error("expected both sinks to be flushed")
+	}
+}
+
+func TestMultiSinkIsolatesPerSinkErrors(t *testing.T) {
+	good := &fakeSink{}
+	bad := &fakeSink{writeErr: errors.New("boom")}
+	m := NewMultiSink(good, bad)
+
+	err := m.Write(context.Background(), sampleRows())
+	if err == nil {
+		t.Fatal("expected an error naming the failing sink")
+	}
+	if good.wrote != 2 {
+		t.Errorf("good sink got %d rows, want 2 (a failing sink shouldn't block the others)", good.wrote)
+	}
+}
+
+type fakeInserter struct {
+	rows [][]bigQueryRow
+	err  error
+}
+
+func (f *fakeInserter) Put(ctx context.Context, rows interface{}) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.rows = append(f.rows, rows.([]bigQueryRow))
+	return nil
+}
+
+func TestBigQuerySinkBatchesByBatchSize(t *testing.T) {
+	ins := &fakeInserter{}
+	s := NewBigQuerySink(ins, 1)
+
+	if err := s.Write(context.Background(), sampleRows()); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if len(ins.rows) != 2 {
+		t.Fatalf("got %d Put calls, want 2 (batchSize=1 should flush per row)", len(ins.rows))
+	}
+	if err := s.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+}
+
+type fakePublisher struct {
+	messages []*Message
+}
+
+func (f *fakePublisher) Publish(ctx context.Context, msg *Message) error {
+	f.messages = append(f.messages, msg)
+	return nil
+}
+
+func TestPubSubSinkGroupsAndGzipsBySymbol(t *testing.T) {
+	pub := &fakePublisher{}
+	s := NewPubSubSink(pub)
+
+	if err := s.Write(context.Background(), sampleRows()); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := s.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	if len(pub.messages) != 2 {
+		t.Fatalf("got %d messages, want one per symbol (2)", len(pub.messages))
+	}
+
+	bySymbol := make(map[string][]types.HistoricalData)
+	for _, msg := range pub.messages {
+		gr, err := gzip.NewReader(bytes.NewReader(msg.Data))
+		if err != nil {
+			t.Fatalf("gzip.NewReader: %v", err)
+		}
+		body, err := io.ReadAll(gr)
+		if err != nil {
+			t.Fatalf("read gzip body: %v", err)
+		}
+		var rows []types.HistoricalData
+		if err := json.Unmarshal(body, &rows); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+		bySymbol[msg.Attributes["symbol"]] = rows
+	}
+
+	if len(bySymbol["AAPL"]) != 1 || len(bySymbol["MSFT"]) != 1 {
+		t.Errorf("bySymbol = %+v, want one row each for AAPL and MSFT", bySymbol)
+	}
+}
+
+func TestParquetSinkWritesReadableFile(t *testing.T) {
+	path := t.TempDir() + "/out.parquet"
+	s, err := NewParquetSink(path, 1)
+	if err != nil {
+		t.Fatalf("NewParquetSink: %v", err)
+	}
+
+	if err := s.Write(context.Background(), sampleRows()); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := s.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat output file: %v", err)
+	}
+	if info.Size() == 0 {
+		t.Error("expected a non-empty parquet file")
+	}
+}
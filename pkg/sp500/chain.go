@@ -0,0 +1,36 @@
+package sp500
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/types"
+)
+
+// ChainSource tries each Source in order, returning the first one that
+// succeeds. It's how fetchSP500List prefers a live Wikipedia fetch but
+// keeps working when that fails: falling back to a locally cached HTML
+// snapshot, and finally to the CSV snapshot bundled into the binary.
+type ChainSource struct {
+	sources []Source
+}
+
+// NewChainSource returns a ChainSource trying sources in the given order.
+func NewChainSource(sources ...Source) *ChainSource {
+	return &ChainSource{sources: sources}
+}
+
+// Constituents returns the first source's result to succeed, or a combined
+// error if every source failed.
+func (c *ChainSource) Constituents(ctx context.Context) ([]types.SP500Stock, error) {
+	var errs []error
+	for _, source := range c.sources {
+		stocks, err := source.Constituents(ctx)
+		if err == nil {
+			return stocks, nil
+		}
+		errs = append(errs, err)
+	}
+	return nil, fmt.Errorf("sp500: every source failed: %w", errors.Join(errs...))
+}
@@ -0,0 +1,42 @@
+package sp500
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/types"
+)
+
+type erroringSource struct{ err error }
+
+func (s *erroringSource) Constituents(ctx context.Context) ([]types.SP500Stock, error) {
+	return nil, s.err
+}
+
+func TestChainSourceFallsBackToNextSourceOnError(t *testing.T) {
+	want := []types.SP500Stock{{Symbol: "AAPL", SecurityName: "Apple Inc."}}
+	chain := NewChainSource(
+		&erroringSource{err: errors.New("first source down")},
+		&countingSource{stocks: want},
+	)
+
+	got, err := chain.Constituents(context.Background())
+	if err != nil {
+		t.Fatalf("Constituents returned error: %v", err)
+	}
+	if len(got) != 1 || got[0] != want[0] {
+		t.Errorf("Constituents() = %+v, want %+v", got, want)
+	}
+}
+
+func TestChainSourceReturnsCombinedErrorWhenAllFail(t *testing.T) {
+	chain := NewChainSource(
+		&erroringSource{err: errors.New("first source down")},
+		&erroringSource{err: errors.New("second source down")},
+	)
+
+	if _, err := chain.Constituents(context.Background()); err == nil {
+		t.Error("expected Constituents to error when every source fails")
+	}
+}
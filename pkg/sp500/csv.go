@@ -0,0 +1,68 @@
+package sp500
+
+import (
+	"context"
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"strings"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/types"
+)
+
+// bundledSnapshot is a small, point-in-time S&P 500 snapshot baked into the
+// binary, so CSVSource still returns something usable on a fresh checkout
+// with no cached sp500.html and no network access to Wikipedia.
+//
+//go:embed snapshot.csv
+var bundledSnapshot string
+
+// CSVSource returns constituents from the bundled snapshot. It's the last
+// resort in the chain fetchSP500List tries, behind a live Wikipedia fetch
+// and a locally cached HTML copy.
+type CSVSource struct {
+	data string
+}
+
+// NewCSVSource returns a CSVSource reading the snapshot embedded at build
+// time.
+func NewCSVSource() *CSVSource {
+	return &CSVSource{data: bundledSnapshot}
+}
+
+// Constituents parses the bundled snapshot's rows.
+func (s *CSVSource) Constituents(ctx context.Context) ([]types.SP500Stock, error) {
+	records, err := csv.NewReader(strings.NewReader(s.data)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("sp500: failed to parse bundled snapshot: %w", err)
+	}
+	if len(records) < 2 {
+		return nil, fmt.Errorf("sp500: bundled snapshot has no data rows")
+	}
+
+	columns := make(map[string]int, len(records[0]))
+	for i, name := range records[0] {
+		columns[name] = i
+	}
+	field := func(row []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return row[i]
+	}
+
+	stocks := make([]types.SP500Stock, 0, len(records)-1)
+	for _, row := range records[1:] {
+		stocks = append(stocks, types.SP500Stock{
+			Symbol:       field(row, "symbol"),
+			SecurityName: field(row, "security_name"),
+			Sector:       field(row, "sector"),
+			SubIndustry:  field(row, "sub_industry"),
+			DateAdded:    field(row, "date_added"),
+			CIK:          field(row, "cik"),
+			Founded:      field(row, "founded"),
+		})
+	}
+	return stocks, nil
+}
@@ -0,0 +1,21 @@
+package sp500
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCSVSourceConstituentsParsesBundledSnapshot(t *testing.T) {
+	stocks, err := NewCSVSource().Constituents(context.Background())
+	if err != nil {
+		t.Fatalf("Constituents returned error: %v", err)
+	}
+	if len(stocks) == 0 {
+		t.Fatal("Constituents returned no stocks")
+	}
+	for _, s := range stocks {
+		if s.Symbol == "" || s.SecurityName == "" {
+			t.Errorf("stock missing Symbol/SecurityName: %+v", s)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package sp500
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	flightconfig "github.com/darianmavgo/backtest-sell-limit/pkg/config"
+	"github.com/darianmavgo/backtest-sell-limit/pkg/types"
+)
+
+// FileSource reads constituents from a locally cached copy of the
+// Wikipedia "List of S&P 500 companies" page (the same markup HTTPSource
+// fetches live). FileName is resolved via flightconfig.FindFile, so it's
+// found regardless of the working directory the binary was launched from.
+type FileSource struct {
+	FileName string
+}
+
+// NewFileSource returns a FileSource reading fileName (e.g. "sp500.html").
+func NewFileSource(fileName string) *FileSource {
+	return &FileSource{FileName: fileName}
+}
+
+// Constituents parses FileName off disk and returns its table rows.
+func (s *FileSource) Constituents(ctx context.Context) ([]types.SP500Stock, error) {
+	path := flightconfig.FindFile(s.FileName)
+	if path == "" {
+		return nil, fmt.Errorf("sp500: %s not found in %v", s.FileName, flightconfig.SearchDirs())
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("sp500: failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	return parseConstituents(f)
+}
+
+// columnHeaders maps each types.SP500Stock field to the header text of the
+// table column carrying it, so the same parseTable walker works across
+// indexes whose Wikipedia tables name (or omit) columns differently. A
+// blank header is simply never matched.
+type columnHeaders struct {
+	Symbol       string
+	SecurityName string
+	Sector       string
+	SubIndustry  string
+	DateAdded    string
+	CIK          string
+	Founded      string
+}
+
+// sp500Columns is the "List of S&P 500 companies" table#constituents
+// layout.
+var sp500Columns = columnHeaders{
+	Symbol:       "Symbol",
+	SecurityName: "Security",
+	Sector:       "GICS Sector",
+	SubIndustry:  "GICS Sub-Industry",
+	DateAdded:    "Date added",
+	CIK:          "CIK",
+	Founded:      "Founded",
+}
+
+// parseConstituents extracts the S&P 500's table#constituents rows from a
+// Wikipedia "List of S&P 500 companies" page.
+func parseConstituents(r io.Reader) ([]types.SP500Stock, error) {
+	return parseTable(r, sp500Columns)
+}
+
+// parseTable extracts table#constituents' rows, keyed by columns rather
+// than position, so Wikipedia reordering or adding columns doesn't
+// silently shift data into the wrong field. It's shared by every
+// Wikipedia-backed index Source; only the column layout differs.
+func parseTable(r io.Reader, headers columnHeaders) ([]types.SP500Stock, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("sp500: failed to parse HTML: %w", err)
+	}
+
+	table := doc.Find("table#constituents").First()
+	if table.Length() == 0 {
+		return nil, fmt.Errorf("sp500: no table#constituents found")
+	}
+
+	// The header row may live in an explicit <thead> or, as on Wikipedia's
+	// actual markup, just be the first <tr> of <tbody> with <th> cells
+	// instead of <td>: find whichever row has <th>s first, rather than
+	// assuming <thead>.
+	columns := make(map[string]int)
+	table.Find("tr").EachWithBreak(func(_ int, tr *goquery.Selection) bool {
+		ths := tr.Find("th")
+		if ths.Length() == 0 {
+			return true
+		}
+		ths.Each(func(i int, th *goquery.Selection) {
+			columns[strings.TrimSpace(th.Text())] = i
+		})
+		return false
+	})
+
+	cell := func(row *goquery.Selection, header string) string {
+		if header == "" {
+			return ""
+		}
+		i, ok := columns[header]
+		if !ok {
+			return ""
+		}
+		td := row.Find("td").Eq(i)
+		if text := strings.TrimSpace(td.Find("a").First().Text()); text != "" {
+			return text
+		}
+		return strings.TrimSpace(td.Text())
+	}
+
+	var stocks []types.SP500Stock
+	table.Find("tbody tr").Each(func(_ int, row *goquery.Selection) {
+		stock := types.SP500Stock{
+			Symbol:       cell(row, headers.Symbol),
+			SecurityName: cell(row, headers.SecurityName),
+			Sector:       cell(row, headers.Sector),
+			SubIndustry:  cell(row, headers.SubIndustry),
+			DateAdded:    cell(row, headers.DateAdded),
+			CIK:          cell(row, headers.CIK),
+			Founded:      cell(row, headers.Founded),
+		}
+		if stock.Symbol == "" || stock.SecurityName == "" {
+			return
+		}
+		stocks = append(stocks, stock)
+	})
+
+	if len(stocks) == 0 {
+		return nil, fmt.Errorf("sp500: no stocks found in table#constituents")
+	}
+
+	return stocks, nil
+}
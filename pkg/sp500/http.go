@@ -0,0 +1,121 @@
+package sp500
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/httpc"
+	"github.com/darianmavgo/backtest-sell-limit/pkg/retry"
+	"github.com/darianmavgo/backtest-sell-limit/pkg/types"
+)
+
+// DefaultWikipediaURL is the live page HTTPSource fetches by default.
+const DefaultWikipediaURL = "https://en.wikipedia.org/wiki/List_of_S%26P_500_companies"
+
+// HTTPSource fetches constituents live from a page with the same
+// table#constituents markup as Wikipedia's "List of S&P 500 companies".
+// It remembers the last response's ETag/Last-Modified and sends them back
+// as If-None-Match/If-Modified-Since, so an unchanged page only costs a
+// 304 round trip and reuses the previously parsed result.
+type HTTPSource struct {
+	URL    string
+	Client *http.Client
+
+	// columns is the table layout to parse the response with; it defaults
+	// to sp500Columns (see NewHTTPSource) but other indexes' Wikipedia
+	// tables name their columns differently (see NewNasdaq100Source and
+	// friends in index.go).
+	columns columnHeaders
+
+	mu           sync.Mutex
+	etag         string
+	lastModified string
+	cached       []types.SP500Stock
+}
+
+// NewHTTPSource returns an HTTPSource fetching url (DefaultWikipediaURL if
+// empty) with a 30s timeout client, parsed as the S&P 500's table layout.
+func NewHTTPSource(url string) *HTTPSource {
+	if url == "" {
+		url = DefaultWikipediaURL
+	}
+	return newHTTPSource(url, sp500Columns)
+}
+
+// newHTTPSource is NewHTTPSource generalized to a caller-supplied column
+// layout, for the other built-in indexes in index.go.
+func newHTTPSource(url string, columns columnHeaders) *HTTPSource {
+	return &HTTPSource{
+		URL:     url,
+		Client:  &http.Client{Timeout: 30 * time.Second},
+		columns: columns,
+	}
+}
+
+// Constituents fetches s.URL, reusing the previously parsed table when the
+// server reports it hasn't changed (HTTP 304).
+func (s *HTTPSource) Constituents(ctx context.Context) ([]types.SP500Stock, error) {
+	if err := httpc.WaitIfLimited(ctx); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("sp500: failed to create request: %w", err)
+	}
+
+	s.mu.Lock()
+	if s.etag != "" {
+		req.Header.Set("If-None-Match", s.etag)
+	}
+	if s.lastModified != "" {
+		req.Header.Set("If-Modified-Since", s.lastModified)
+	}
+	s.mu.Unlock()
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("sp500: request to %s failed: %w", s.URL, err)
+	}
+	defer resp.Body.Close()
+
+	httpc.RecordHeaders(resp)
+
+	if resp.StatusCode == http.StatusNotModified {
+		s.mu.Lock()
+		cached := s.cached
+		s.mu.Unlock()
+		if cached != nil {
+			return cached, nil
+		}
+		// No prior cache to fall back on despite the 304; treat as an error
+		// so the caller knows to retry rather than silently returning nil.
+		return nil, fmt.Errorf("sp500: got 304 Not Modified from %s with no cached result", s.URL)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		err := fmt.Errorf("sp500: %s returned status %d", s.URL, resp.StatusCode)
+		if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < http.StatusInternalServerError {
+			// A 4xx other than 429 (e.g. 404, 410) won't resolve itself on a
+			// retry; only rate limits and server errors are worth one.
+			return nil, retry.Permanent(err)
+		}
+		return nil, err
+	}
+
+	stocks, err := parseTable(resp.Body, s.columns)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.etag = resp.Header.Get("ETag")
+	s.lastModified = resp.Header.Get("Last-Modified")
+	s.cached = stocks
+	s.mu.Unlock()
+
+	return stocks, nil
+}
@@ -0,0 +1,94 @@
+package sp500
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/types"
+)
+
+func TestRegistryGetReturnsRegisteredProvider(t *testing.T) {
+	reg := NewRegistry()
+	reg.Register(NewCachedIndex("sp500", NewCache(&countingSource{}, 0)))
+
+	p, ok := reg.Get("sp500")
+	if !ok {
+		t.Fatal("Get(\"sp500\") not found")
+	}
+	if p.Name() != "sp500" {
+		t.Errorf("Name() = %q, want %q", p.Name(), "sp500")
+	}
+}
+
+func TestRegistryGetReportsUnknownName(t *testing.T) {
+	reg := NewRegistry()
+	if _, ok := reg.Get("nasdaq100"); ok {
+		t.Error("Get on an unregistered name should report ok=false")
+	}
+}
+
+func TestRegistryRegisterPanicsOnDuplicateName(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected Register to panic on a duplicate name")
+		}
+	}()
+
+	reg := NewRegistry()
+	reg.Register(NewCachedIndex("sp500", NewCache(&countingSource{}, 0)))
+	reg.Register(NewCachedIndex("sp500", NewCache(&countingSource{}, 0)))
+}
+
+func TestRegistryNamesIsSorted(t *testing.T) {
+	reg := NewRegistry()
+	reg.Register(NewCachedIndex("sp500", NewCache(&countingSource{}, 0)))
+	reg.Register(NewCachedIndex("dowjones", NewCache(&countingSource{}, 0)))
+
+	got := reg.Names()
+	want := []string{"dowjones", "sp500"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("Names() = %v, want %v", got, want)
+	}
+}
+
+func TestCachedIndexRefreshBypassesTTL(t *testing.T) {
+	src := &countingSource{stocks: []types.SP500Stock{{Symbol: "MSFT", SecurityName: "Microsoft Corp."}}}
+	idx := NewCachedIndex("nasdaq100", NewCache(src, 0))
+
+	if _, err := idx.Symbols(context.Background()); err != nil {
+		t.Fatalf("Symbols returned error: %v", err)
+	}
+	if _, err := idx.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh returned error: %v", err)
+	}
+	if src.calls != 2 {
+		t.Errorf("source called %d times, want 2 (one per Symbols/Refresh)", src.calls)
+	}
+}
+
+const nasdaq100ConstituentsHTML = `
+<table id="constituents">
+<tbody>
+<tr><th>Company</th><th>Ticker</th><th>GICS Sector</th><th>GICS Sub-Industry</th></tr>
+<tr><td>Apple Inc.</td><td>AAPL</td><td>Information Technology</td><td>Technology Hardware, Storage &amp; Peripherals</td></tr>
+</tbody>
+</table>
+`
+
+func TestParseTableSupportsNasdaq100Layout(t *testing.T) {
+	stocks, err := parseTable(strings.NewReader(nasdaq100ConstituentsHTML), nasdaq100Columns)
+	if err != nil {
+		t.Fatalf("parseTable returned error: %v", err)
+	}
+
+	want := types.SP500Stock{
+		Symbol:       "AAPL",
+		SecurityName: "Apple Inc.",
+		Sector:       "Information Technology",
+		SubIndustry:  "Technology Hardware, Storage & Peripherals",
+	}
+	if len(stocks) != 1 || stocks[0] != want {
+		t.Errorf("parseTable() = %+v, want [%+v]", stocks, want)
+	}
+}
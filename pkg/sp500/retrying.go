@@ -0,0 +1,42 @@
+package sp500
+
+import (
+	"context"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/retry"
+	"github.com/darianmavgo/backtest-sell-limit/pkg/types"
+)
+
+// RetryingSource wraps another Source with exponential backoff and
+// jitter, so a transient failure (a dropped connection, a 429, a 5xx)
+// doesn't fail a caller's request outright. Source.Constituents errors
+// wrapped with retry.Permanent (see HTTPSource) stop retrying
+// immediately.
+type RetryingSource struct {
+	Source Source
+	Config retry.Config
+}
+
+// NewRetryingSource wraps source, retrying per cfg. Unlike NewCache,
+// there's no zero-value default for cfg: a Config left unset would retry
+// with a 0s InitialInterval and no MaxElapsedTime, hammering source
+// forever, so the caller must supply one explicitly (see
+// retry.DefaultConfig).
+func NewRetryingSource(source Source, cfg retry.Config) *RetryingSource {
+	return &RetryingSource{Source: source, Config: cfg}
+}
+
+// Constituents calls through to r.Source, retrying on a transient error
+// per r.Config.
+func (r *RetryingSource) Constituents(ctx context.Context) ([]types.SP500Stock, error) {
+	var stocks []types.SP500Stock
+	err := retry.Do(ctx, r.Config, func() error {
+		s, err := r.Source.Constituents(ctx)
+		if err != nil {
+			return err
+		}
+		stocks = s
+		return nil
+	})
+	return stocks, err
+}
@@ -0,0 +1,59 @@
+package sp500
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/retry"
+	"github.com/darianmavgo/backtest-sell-limit/pkg/types"
+)
+
+// flakySource fails the first failures calls then succeeds.
+type flakySource struct {
+	failures int
+	err      error
+	calls    int
+	stocks   []types.SP500Stock
+}
+
+func (f *flakySource) Constituents(ctx context.Context) ([]types.SP500Stock, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return nil, f.err
+	}
+	return f.stocks, nil
+}
+
+func retryTestConfig() retry.Config {
+	return retry.Config{InitialInterval: time.Millisecond, Multiplier: 1, Clock: retry.NewFakeClock(time.Unix(0, 0))}
+}
+
+func TestRetryingSourceRetriesTransientErrors(t *testing.T) {
+	src := &flakySource{failures: 2, err: errors.New("transient"), stocks: []types.SP500Stock{{Symbol: "MSFT"}}}
+	r := NewRetryingSource(src, retryTestConfig())
+
+	stocks, err := r.Constituents(context.Background())
+	if err != nil {
+		t.Fatalf("Constituents returned error: %v", err)
+	}
+	if src.calls != 3 {
+		t.Errorf("calls = %d, want 3 (2 failures + 1 success)", src.calls)
+	}
+	if len(stocks) != 1 || stocks[0].Symbol != "MSFT" {
+		t.Errorf("stocks = %+v, want [{Symbol: MSFT}]", stocks)
+	}
+}
+
+func TestRetryingSourceStopsOnPermanentError(t *testing.T) {
+	src := &flakySource{failures: 5, err: retry.Permanent(errors.New("not found"))}
+	r := NewRetryingSource(src, retryTestConfig())
+
+	if _, err := r.Constituents(context.Background()); err == nil {
+		t.Fatal("expected Constituents to return an error")
+	}
+	if src.calls != 1 {
+		t.Errorf("calls = %d, want 1 (no retry after a permanent error)", src.calls)
+	}
+}
@@ -0,0 +1,77 @@
+// Package sp500 resolves index-constituent lists (S&P 500 and friends)
+// from a pluggable Source, with an in-process TTL cache so repeated
+// requests don't re-parse or re-fetch on every hit.
+package sp500
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/types"
+)
+
+// DefaultCacheTTL is how long a Cache serves its last result when
+// constructed with ttl <= 0 — the constituent list changes only a
+// handful of times a year, so a day-long cache is conservative rather
+// than aggressive.
+const DefaultCacheTTL = 24 * time.Hour
+
+// Source resolves the current constituents of an index. Implementations
+// include a local HTML file, a live HTTP fetch (e.g. Wikipedia), and a
+// database snapshot; any of them can be dropped in to back Get for the
+// S&P 500 or another index (Nasdaq 100, Russell 2000, ...).
+type Source interface {
+	Constituents(ctx context.Context) ([]types.SP500Stock, error)
+}
+
+// Cache memoizes a Source's result for TTL, so bursts of requests (e.g.
+// every hit to /api/sp500) only trigger one underlying fetch.
+type Cache struct {
+	Source Source
+	TTL    time.Duration
+
+	mu        sync.Mutex
+	stocks    []types.SP500Stock
+	fetchedAt time.Time
+}
+
+// NewCache wraps source with a TTL-bounded memoization layer. ttl <= 0
+// uses DefaultCacheTTL.
+func NewCache(source Source, ttl time.Duration) *Cache {
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	return &Cache{Source: source, TTL: ttl}
+}
+
+// Get returns the cached constituents if they're still within TTL,
+// otherwise calls through to the underlying Source and caches the result.
+func (c *Cache) Get(ctx context.Context) ([]types.SP500Stock, error) {
+	c.mu.Lock()
+	if c.stocks != nil && time.Since(c.fetchedAt) < c.TTL {
+		stocks := c.stocks
+		c.mu.Unlock()
+		return stocks, nil
+	}
+	c.mu.Unlock()
+
+	return c.Force(ctx)
+}
+
+// Force calls through to the underlying Source regardless of TTL and
+// caches the result, for a caller that needs to bypass a stale cache (see
+// listSP500Handler's force=true query param).
+func (c *Cache) Force(ctx context.Context) ([]types.SP500Stock, error) {
+	stocks, err := c.Source.Constituents(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.stocks = stocks
+	c.fetchedAt = time.Now()
+	c.mu.Unlock()
+
+	return stocks, nil
+}
@@ -0,0 +1,142 @@
+package sp500
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/types"
+)
+
+const sampleConstituentsHTML = `<html><body>
+<table id="constituents">
+<tbody>
+<tr><th>Symbol</th><th>Security</th></tr>
+<tr><td><a href="/wiki/AAPL">AAPL</a></td><td><a href="/wiki/Apple">Apple Inc.</a></td></tr>
+<tr><td><a href="/wiki/MSFT">MSFT</a></td><td><a href="/wiki/Microsoft">Microsoft Corporation</a></td></tr>
+</tbody>
+</table>
+</body></html>`
+
+func writeSampleFile(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sp500.html")
+	if err := os.WriteFile(path, []byte(sampleConstituentsHTML), 0o644); err != nil {
+		t.Fatalf("failed to write fixture: %v", err)
+	}
+
+	// flightconfig.FindFile resolves relative to the working directory, so
+	// FileSource needs cwd to be the fixture's directory.
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get working directory: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("failed to chdir: %v", err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	return "sp500.html"
+}
+
+func TestFileSourceConstituents(t *testing.T) {
+	fileName := writeSampleFile(t)
+
+	src := &FileSource{FileName: fileName}
+	stocks, err := src.Constituents(context.Background())
+	if err != nil {
+		t.Fatalf("Constituents returned error: %v", err)
+	}
+
+	want := []types.SP500Stock{
+		{Symbol: "AAPL", SecurityName: "Apple Inc."},
+		{Symbol: "MSFT", SecurityName: "Microsoft Corporation"},
+	}
+	if len(stocks) != len(want) {
+		t.Fatalf("Constituents() = %+v, want %+v", stocks, want)
+	}
+	for i, s := range stocks {
+		if s != want[i] {
+			t.Errorf("stocks[%d] = %+v, want %+v", i, s, want[i])
+		}
+	}
+}
+
+const reorderedConstituentsHTML = `<html><body>
+<table id="constituents">
+<tbody>
+<tr><th>Security</th><th>GICS Sector</th><th>Symbol</th><th>Date added</th></tr>
+<tr><td><a>Apple Inc.</a></td><td>Information Technology</td><td><a>AAPL</a></td><td>1982-11-30</td></tr>
+</tbody>
+</table>
+</body></html>`
+
+func TestParseConstituentsIsRobustToColumnReordering(t *testing.T) {
+	stocks, err := parseConstituents(strings.NewReader(reorderedConstituentsHTML))
+	if err != nil {
+		t.Fatalf("parseConstituents returned error: %v", err)
+	}
+
+	want := types.SP500Stock{
+		Symbol:       "AAPL",
+		SecurityName: "Apple Inc.",
+		Sector:       "Information Technology",
+		DateAdded:    "1982-11-30",
+	}
+	if len(stocks) != 1 || stocks[0] != want {
+		t.Errorf("parseConstituents() = %+v, want [%+v]", stocks, want)
+	}
+}
+
+func TestFileSourceMissingFile(t *testing.T) {
+	src := &FileSource{FileName: "does-not-exist-anywhere.html"}
+	if _, err := src.Constituents(context.Background()); err == nil {
+		t.Error("expected Constituents to error for a missing file")
+	}
+}
+
+type countingSource struct {
+	calls  int
+	stocks []types.SP500Stock
+}
+
+func (s *countingSource) Constituents(ctx context.Context) ([]types.SP500Stock, error) {
+	s.calls++
+	return s.stocks, nil
+}
+
+func TestCacheMemoizesWithinTTL(t *testing.T) {
+	src := &countingSource{stocks: []types.SP500Stock{{Symbol: "AAPL", SecurityName: "Apple Inc."}}}
+	cache := NewCache(src, time.Hour)
+
+	for i := 0; i < 3; i++ {
+		if _, err := cache.Get(context.Background()); err != nil {
+			t.Fatalf("Get returned error: %v", err)
+		}
+	}
+
+	if src.calls != 1 {
+		t.Errorf("Source.Constituents called %d times, want 1", src.calls)
+	}
+}
+
+func TestCacheRefetchesAfterTTL(t *testing.T) {
+	src := &countingSource{stocks: []types.SP500Stock{{Symbol: "AAPL", SecurityName: "Apple Inc."}}}
+	cache := NewCache(src, time.Millisecond)
+
+	if _, err := cache.Get(context.Background()); err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if _, err := cache.Get(context.Background()); err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+
+	if src.calls != 2 {
+		t.Errorf("Source.Constituents called %d times, want 2", src.calls)
+	}
+}
@@ -0,0 +1,72 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/types/metrics"
+)
+
+// InstrumentedStore decorates a Store, recording
+// metrics.DBQueryDurationSeconds around every Query/Exec call (and their
+// context-aware counterparts) so DB latency shows up in Grafana instead of
+// only in logs.
+type InstrumentedStore struct {
+	Store
+}
+
+// Instrument wraps s so its Query/QueryRow/Exec calls are timed into
+// metrics.DBQueryDurationSeconds, labeled by the query's leading SQL
+// keyword (e.g. "SELECT") rather than its full text, to keep the metric's
+// cardinality bounded.
+func Instrument(s Store) Store {
+	return &InstrumentedStore{Store: s}
+}
+
+func (s *InstrumentedStore) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	defer observeQuery(query, time.Now())
+	return s.Store.Query(query, args...)
+}
+
+func (s *InstrumentedStore) QueryRow(query string, args ...interface{}) *sql.Row {
+	defer observeQuery(query, time.Now())
+	return s.Store.QueryRow(query, args...)
+}
+
+func (s *InstrumentedStore) Exec(query string, args ...interface{}) (sql.Result, error) {
+	defer observeQuery(query, time.Now())
+	return s.Store.Exec(query, args...)
+}
+
+func (s *InstrumentedStore) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	defer observeQuery(query, time.Now())
+	return s.Store.QueryContext(ctx, query, args...)
+}
+
+func (s *InstrumentedStore) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	defer observeQuery(query, time.Now())
+	return s.Store.QueryRowContext(ctx, query, args...)
+}
+
+func (s *InstrumentedStore) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	defer observeQuery(query, time.Now())
+	return s.Store.ExecContext(ctx, query, args...)
+}
+
+// observeQuery records how long a call starting at start took, labeled by
+// queryVerb(query).
+func observeQuery(query string, start time.Time) {
+	metrics.DBQueryDurationSeconds.Observe(time.Since(start).Seconds(), queryVerb(query))
+}
+
+// queryVerb returns query's leading SQL keyword, upper-cased, as a
+// low-cardinality label value (e.g. "SELECT", "INSERT").
+func queryVerb(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return "unknown"
+	}
+	return strings.ToUpper(fields[0])
+}
@@ -0,0 +1,225 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/types"
+)
+
+// PostgresStore is an optional Store backend for deployments that have
+// outgrown SQLite. Table/column shapes match SQLiteStore's so the same
+// queries in routes.go work against either.
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore opens dsn (a postgres connection string) as a
+// PostgresStore.
+func NewPostgresStore(dsn string) (*PostgresStore, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("store: failed to open postgres database: %w", err)
+	}
+	return &PostgresStore{db: db}, nil
+}
+
+func (s *PostgresStore) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return s.db.Query(query, args...)
+}
+
+func (s *PostgresStore) QueryRow(query string, args ...interface{}) *sql.Row {
+	return s.db.QueryRow(query, args...)
+}
+
+func (s *PostgresStore) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return s.db.Exec(query, args...)
+}
+
+func (s *PostgresStore) Begin() (*sql.Tx, error) {
+	return s.db.Begin()
+}
+
+func (s *PostgresStore) DB() *sql.DB {
+	return s.db
+}
+
+func (s *PostgresStore) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return s.db.QueryContext(ctx, query, args...)
+}
+
+func (s *PostgresStore) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return s.db.QueryRowContext(ctx, query, args...)
+}
+
+func (s *PostgresStore) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return s.db.ExecContext(ctx, query, args...)
+}
+
+func (s *PostgresStore) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	return s.db.BeginTx(ctx, opts)
+}
+
+// BulkInsertHistorical loads data through pq.CopyIn into a temporary
+// staging table, then merges it into stock_historical_data with a single
+// `INSERT ... ON CONFLICT DO UPDATE` sourced from the staging table —
+// Postgres's equivalent of the batched multi-row upsert SQLiteStore does
+// directly, since COPY can't target ON CONFLICT itself. It aborts as soon
+// as ctx is done, rolling back whatever was copied so far.
+func (s *PostgresStore) BulkInsertHistorical(ctx context.Context, symbol string, data []types.StockData) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("store: failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		CREATE TEMP TABLE stock_historical_data_staging (
+			symbol TEXT, date BIGINT, open DOUBLE PRECISION, high DOUBLE PRECISION,
+			low DOUBLE PRECISION, close DOUBLE PRECISION, adj_close DOUBLE PRECISION, volume BIGINT,
+			source TEXT
+		) ON COMMIT DROP
+	`); err != nil {
+		return fmt.Errorf("store: failed to create staging table: %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("stock_historical_data_staging",
+		"symbol", "date", "open", "high", "low", "close", "adj_close", "volume", "source"))
+	if err != nil {
+		return fmt.Errorf("store: failed to prepare COPY: %w", err)
+	}
+
+	for _, d := range data {
+		if err := ctx.Err(); err != nil {
+			stmt.Close()
+			return err
+		}
+		if _, err := stmt.ExecContext(ctx, symbol, d.Date.Unix(), d.Open, d.High, d.Low, d.Close, d.AdjClose, d.Volume, d.Source); err != nil {
+			stmt.Close()
+			return fmt.Errorf("store: COPY failed for %s: %w", symbol, err)
+		}
+	}
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		stmt.Close()
+		return fmt.Errorf("store: COPY flush failed for %s: %w", symbol, err)
+	}
+	if err := stmt.Close(); err != nil {
+		return fmt.Errorf("store: COPY close failed for %s: %w", symbol, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO stock_historical_data (symbol, date, open, high, low, close, adj_close, volume, source)
+		SELECT symbol, date, open, high, low, close, adj_close, volume, source FROM stock_historical_data_staging
+		ON CONFLICT (symbol, date) DO UPDATE SET
+			open = excluded.open,
+			high = excluded.high,
+			low = excluded.low,
+			close = excluded.close,
+			adj_close = excluded.adj_close,
+			volume = excluded.volume,
+			source = excluded.source
+	`); err != nil {
+		return fmt.Errorf("store: failed to merge staging table: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("store: failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// EnsureHistoricalSchema creates stock_historical_data if it doesn't
+// already exist, then best-effort converts it to a TimescaleDB hypertable
+// partitioned on date. A deployment without the timescaledb extension
+// installed just keeps the plain table — that's logged, not fatal, since
+// the extension is an optimization, not a correctness requirement.
+func (s *PostgresStore) EnsureHistoricalSchema(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS stock_historical_data (
+			symbol TEXT NOT NULL,
+			date BIGINT NOT NULL,
+			open DOUBLE PRECISION,
+			high DOUBLE PRECISION,
+			low DOUBLE PRECISION,
+			close DOUBLE PRECISION,
+			adj_close DOUBLE PRECISION,
+			volume BIGINT,
+			source TEXT,
+			PRIMARY KEY (symbol, date)
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("store: failed to create stock_historical_data table: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, `ALTER TABLE stock_historical_data ADD COLUMN IF NOT EXISTS source TEXT`); err != nil {
+		return fmt.Errorf("store: failed to add source column to stock_historical_data: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx,
+		`SELECT create_hypertable('stock_historical_data', 'date', chunk_time_interval => 86400, if_not_exists => true, migrate_data => true)`,
+	); err != nil {
+		log.Printf("store: stock_historical_data is a plain table, not a TimescaleDB hypertable (create_hypertable failed: %v)", err)
+	}
+
+	return nil
+}
+
+// Symbols returns the distinct symbols listed in table, which must be a
+// real table in the current schema — callers don't get to interpolate an
+// arbitrary name into SQL themselves.
+func (s *PostgresStore) Symbols(ctx context.Context, table string) ([]string, error) {
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM information_schema.tables
+			WHERE table_schema = 'public' AND table_name = $1
+		)
+	`, table).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("store: failed to validate table %q: %w", table, err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("store: table %q is not a known table", table)
+	}
+
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf("SELECT DISTINCT symbol FROM %s ORDER BY symbol", pq.QuoteIdentifier(table)))
+	if err != nil {
+		return nil, fmt.Errorf("store: failed to query symbols from %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var symbols []string
+	for rows.Next() {
+		var symbol string
+		if err := rows.Scan(&symbol); err != nil {
+			return nil, fmt.Errorf("store: failed to scan symbol: %w", err)
+		}
+		symbols = append(symbols, symbol)
+	}
+	return symbols, rows.Err()
+}
+
+// Quote returns symbol's closing price on the most recent trading day at
+// or before t.
+func (s *PostgresStore) Quote(ctx context.Context, symbol string, t time.Time) (float64, error) {
+	var closePrice float64
+	err := s.db.QueryRowContext(ctx, `
+		SELECT close FROM stock_historical_data
+		WHERE symbol = $1 AND date <= $2
+		ORDER BY date DESC
+		LIMIT 1
+	`, symbol, t.Unix()).Scan(&closePrice)
+	if err != nil {
+		return 0, fmt.Errorf("store: failed to fetch quote for %s: %w", symbol, err)
+	}
+	return closePrice, nil
+}
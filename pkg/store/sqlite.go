@@ -0,0 +1,207 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/dbadmin"
+	"github.com/darianmavgo/backtest-sell-limit/pkg/types"
+)
+
+// SQLiteStore is a Store backed by a SQLite database file.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore opens dsn (a sqlite3 DSN, typically a file path with
+// query params like "?_journal_mode=WAL") as a SQLiteStore.
+func NewSQLiteStore(dsn string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("store: failed to open sqlite database: %w", err)
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+// NewSQLiteStoreFromDB wraps an already-open *sql.DB as a SQLiteStore.
+func NewSQLiteStoreFromDB(db *sql.DB) *SQLiteStore {
+	return &SQLiteStore{db: db}
+}
+
+func (s *SQLiteStore) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return s.db.Query(query, args...)
+}
+
+func (s *SQLiteStore) QueryRow(query string, args ...interface{}) *sql.Row {
+	return s.db.QueryRow(query, args...)
+}
+
+func (s *SQLiteStore) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return s.db.Exec(query, args...)
+}
+
+func (s *SQLiteStore) Begin() (*sql.Tx, error) {
+	return s.db.Begin()
+}
+
+func (s *SQLiteStore) DB() *sql.DB {
+	return s.db
+}
+
+func (s *SQLiteStore) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return s.db.QueryContext(ctx, query, args...)
+}
+
+func (s *SQLiteStore) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return s.db.QueryRowContext(ctx, query, args...)
+}
+
+func (s *SQLiteStore) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return s.db.ExecContext(ctx, query, args...)
+}
+
+func (s *SQLiteStore) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	return s.db.BeginTx(ctx, opts)
+}
+
+// BulkInsertHistorical upserts data in batches of batchSize rows, each
+// batch a single multi-row `INSERT ... ON CONFLICT DO UPDATE` statement,
+// instead of one round trip per row. It checks ctx between batches so a
+// canceled request stops before queuing more work.
+func (s *SQLiteStore) BulkInsertHistorical(ctx context.Context, symbol string, data []types.StockData) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("store: failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for start := 0; start < len(data); start += batchSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		end := start + batchSize
+		if end > len(data) {
+			end = len(data)
+		}
+		if err := insertBatch(ctx, tx, symbol, data[start:end]); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("store: failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// EnsureHistoricalSchema creates stock_historical_data if it doesn't
+// already exist.
+func (s *SQLiteStore) EnsureHistoricalSchema(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS stock_historical_data (
+			symbol TEXT,
+			date INTEGER,
+			open REAL,
+			high REAL,
+			low REAL,
+			close REAL,
+			adj_close REAL,
+			volume INTEGER,
+			source TEXT,
+			PRIMARY KEY (symbol, date)
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("store: failed to create stock_historical_data table: %w", err)
+	}
+
+	// source was added after this table shipped, so databases created
+	// before this change won't have it; SQLite has no ADD COLUMN IF NOT
+	// EXISTS, so just ignore the "duplicate column" error once it's there.
+	if _, err := s.db.ExecContext(ctx, `ALTER TABLE stock_historical_data ADD COLUMN source TEXT`); err != nil &&
+		!strings.Contains(err.Error(), "duplicate column") {
+		return fmt.Errorf("store: failed to add source column to stock_historical_data: %w", err)
+	}
+	return nil
+}
+
+// Symbols returns the distinct symbols listed in table.
+func (s *SQLiteStore) Symbols(ctx context.Context, table string) ([]string, error) {
+	allowed, err := dbadmin.IsAllowed(ctx, s.db, "sqlite", table)
+	if err != nil {
+		return nil, fmt.Errorf("store: failed to validate table %q: %w", table, err)
+	}
+	if !allowed {
+		return nil, fmt.Errorf("store: table %q is not a known table", table)
+	}
+
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf("SELECT DISTINCT symbol FROM %s ORDER BY symbol", table))
+	if err != nil {
+		return nil, fmt.Errorf("store: failed to query symbols from %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var symbols []string
+	for rows.Next() {
+		var symbol string
+		if err := rows.Scan(&symbol); err != nil {
+			return nil, fmt.Errorf("store: failed to scan symbol: %w", err)
+		}
+		symbols = append(symbols, symbol)
+	}
+	return symbols, rows.Err()
+}
+
+// Quote returns symbol's closing price on the most recent trading day at
+// or before t.
+func (s *SQLiteStore) Quote(ctx context.Context, symbol string, t time.Time) (float64, error) {
+	var closePrice float64
+	err := s.db.QueryRowContext(ctx, `
+		SELECT close FROM stock_historical_data
+		WHERE symbol = ? AND date <= ?
+		ORDER BY date DESC
+		LIMIT 1
+	`, symbol, t.Unix()).Scan(&closePrice)
+	if err != nil {
+		return 0, fmt.Errorf("store: failed to fetch quote for %s: %w", symbol, err)
+	}
+	return closePrice, nil
+}
+
+func insertBatch(ctx context.Context, tx *sql.Tx, symbol string, batch []types.StockData) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	var b strings.Builder
+	b.WriteString(`INSERT INTO stock_historical_data (symbol, date, open, high, low, close, adj_close, volume, source) VALUES `)
+
+	args := make([]interface{}, 0, len(batch)*9)
+	for i, d := range batch {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString("(?, ?, ?, ?, ?, ?, ?, ?, ?)")
+		args = append(args, symbol, d.Date.Unix(), d.Open, d.High, d.Low, d.Close, d.AdjClose, d.Volume, d.Source)
+	}
+
+	b.WriteString(` ON CONFLICT(symbol, date) DO UPDATE SET
+		open = excluded.open,
+		high = excluded.high,
+		low = excluded.low,
+		close = excluded.close,
+		adj_close = excluded.adj_close,
+		volume = excluded.volume,
+		source = excluded.source`)
+
+	if _, err := tx.ExecContext(ctx, b.String(), args...); err != nil {
+		return fmt.Errorf("store: failed to insert batch for %s: %w", symbol, err)
+	}
+	return nil
+}
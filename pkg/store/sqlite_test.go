@@ -0,0 +1,183 @@
+package store
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/types"
+)
+
+func newTestSQLiteStore(t *testing.T) *SQLiteStore {
+	t.Helper()
+	dsn := filepath.Join(t.TempDir(), "test.db")
+
+	s, err := NewSQLiteStore(dsn)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore returned error: %v", err)
+	}
+	t.Cleanup(func() { s.DB().Close() })
+
+	if _, err := s.Exec(`
+		CREATE TABLE stock_historical_data (
+			symbol TEXT, date INTEGER, open REAL, high REAL, low REAL,
+			close REAL, adj_close REAL, volume INTEGER, source TEXT,
+			PRIMARY KEY (symbol, date)
+		)
+	`); err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+
+	return s
+}
+
+func TestSQLiteStoreBulkInsertHistoricalInsertsAndUpserts(t *testing.T) {
+	s := newTestSQLiteStore(t)
+
+	day := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	data := []types.StockData{
+		{Date: day, Open: 1, High: 2, Low: 0.5, Close: 1.5, AdjClose: 1.5, Volume: 100},
+	}
+
+	if err := s.BulkInsertHistorical(context.Background(), "AAPL", data); err != nil {
+		t.Fatalf("BulkInsertHistorical returned error: %v", err)
+	}
+
+	// Re-inserting the same date with a different close should update, not duplicate.
+	data[0].Close = 9.99
+	if err := s.BulkInsertHistorical(context.Background(), "AAPL", data); err != nil {
+		t.Fatalf("BulkInsertHistorical (upsert) returned error: %v", err)
+	}
+
+	var count int
+	var close float64
+	row := s.QueryRow(`SELECT COUNT(*), close FROM stock_historical_data WHERE symbol = 'AAPL'`)
+	if err := row.Scan(&count, &close); err != nil {
+		t.Fatalf("failed to query result: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("row count = %d, want 1 (upsert should not duplicate)", count)
+	}
+	if close != 9.99 {
+		t.Errorf("close = %v, want 9.99", close)
+	}
+}
+
+func TestSQLiteStoreBulkInsertHistoricalPersistsSource(t *testing.T) {
+	s := newTestSQLiteStore(t)
+
+	day := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	data := []types.StockData{{Date: day, Close: 1.5, Source: "yahoo"}}
+
+	if err := s.BulkInsertHistorical(context.Background(), "AAPL", data); err != nil {
+		t.Fatalf("BulkInsertHistorical returned error: %v", err)
+	}
+
+	var source string
+	row := s.QueryRow(`SELECT source FROM stock_historical_data WHERE symbol = 'AAPL'`)
+	if err := row.Scan(&source); err != nil {
+		t.Fatalf("failed to query result: %v", err)
+	}
+	if source != "yahoo" {
+		t.Errorf("source = %q, want %q", source, "yahoo")
+	}
+}
+
+func TestSQLiteStoreBulkInsertHistoricalBatchesLargeInputs(t *testing.T) {
+	s := newTestSQLiteStore(t)
+
+	data := make([]types.StockData, batchSize+10)
+	for i := range data {
+		data[i] = types.StockData{
+			Date:  time.Unix(int64(i)*86400, 0),
+			Close: float64(i),
+		}
+	}
+
+	if err := s.BulkInsertHistorical(context.Background(), "MSFT", data); err != nil {
+		t.Fatalf("BulkInsertHistorical returned error: %v", err)
+	}
+
+	var count int
+	if err := s.QueryRow(`SELECT COUNT(*) FROM stock_historical_data WHERE symbol = 'MSFT'`).Scan(&count); err != nil {
+		t.Fatalf("failed to query result: %v", err)
+	}
+	if count != len(data) {
+		t.Errorf("row count = %d, want %d", count, len(data))
+	}
+}
+
+func TestSQLiteStoreEnsureHistoricalSchemaIsIdempotent(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "test.db")
+	s, err := NewSQLiteStore(dsn)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore returned error: %v", err)
+	}
+	t.Cleanup(func() { s.DB().Close() })
+
+	if err := s.EnsureHistoricalSchema(context.Background()); err != nil {
+		t.Fatalf("EnsureHistoricalSchema returned error: %v", err)
+	}
+	if err := s.EnsureHistoricalSchema(context.Background()); err != nil {
+		t.Fatalf("second EnsureHistoricalSchema call returned error: %v", err)
+	}
+
+	if err := s.BulkInsertHistorical(context.Background(), "AAPL", []types.StockData{{Date: time.Unix(0, 0)}}); err != nil {
+		t.Fatalf("BulkInsertHistorical returned error after EnsureHistoricalSchema: %v", err)
+	}
+}
+
+func TestSQLiteStoreSymbolsRejectsUnknownTable(t *testing.T) {
+	s := newTestSQLiteStore(t)
+
+	if _, err := s.Symbols(context.Background(), "stock_historical_data; DROP TABLE stock_historical_data"); err == nil {
+		t.Error("expected Symbols to reject a table name that isn't a real table")
+	}
+}
+
+func TestSQLiteStoreSymbolsReturnsDistinctSymbols(t *testing.T) {
+	s := newTestSQLiteStore(t)
+
+	data := []types.StockData{{Date: time.Unix(0, 0)}, {Date: time.Unix(86400, 0)}}
+	if err := s.BulkInsertHistorical(context.Background(), "AAPL", data); err != nil {
+		t.Fatalf("BulkInsertHistorical returned error: %v", err)
+	}
+	if err := s.BulkInsertHistorical(context.Background(), "MSFT", data); err != nil {
+		t.Fatalf("BulkInsertHistorical returned error: %v", err)
+	}
+
+	symbols, err := s.Symbols(context.Background(), "stock_historical_data")
+	if err != nil {
+		t.Fatalf("Symbols returned error: %v", err)
+	}
+	if len(symbols) != 2 {
+		t.Errorf("len(symbols) = %d, want 2 (got %v)", len(symbols), symbols)
+	}
+}
+
+func TestSQLiteStoreQuoteReturnsMostRecentCloseAtOrBeforeT(t *testing.T) {
+	s := newTestSQLiteStore(t)
+
+	data := []types.StockData{
+		{Date: time.Unix(0, 0), Close: 1},
+		{Date: time.Unix(86400, 0), Close: 2},
+	}
+	if err := s.BulkInsertHistorical(context.Background(), "AAPL", data); err != nil {
+		t.Fatalf("BulkInsertHistorical returned error: %v", err)
+	}
+
+	got, err := s.Quote(context.Background(), "AAPL", time.Unix(90000, 0))
+	if err != nil {
+		t.Fatalf("Quote returned error: %v", err)
+	}
+	if got != 2 {
+		t.Errorf("Quote = %v, want 2", got)
+	}
+
+	if _, err := s.Quote(context.Background(), "AAPL", time.Unix(-1, 0)); err == nil {
+		t.Error("expected Quote to error when no row is at or before t")
+	}
+}
@@ -0,0 +1,73 @@
+// Package store abstracts the historical-data database behind a Store
+// interface, so callers aren't tied to a specific *sql.DB driver and can
+// get a bulk, batched ingest path (BulkInsertHistorical) instead of
+// inserting one row at a time.
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/types"
+)
+
+// Store is the database handle threaded through handlers that touch
+// historical stock data. It mirrors the *sql.DB methods those handlers
+// already use (Query/QueryRow/Exec/Begin and their context-aware
+// counterparts) plus BulkInsertHistorical, the batched upsert path for
+// filling hundreds of tickers at once.
+type Store interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Begin() (*sql.Tx, error)
+
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+
+	// BulkInsertHistorical upserts data into stock_historical_data for
+	// symbol, batching rows instead of one INSERT per row. It aborts as
+	// soon as ctx is done, leaving the transaction rolled back.
+	BulkInsertHistorical(ctx context.Context, symbol string, data []types.StockData) error
+
+	// EnsureHistoricalSchema creates stock_historical_data if it doesn't
+	// already exist, so callers don't each hand-roll their own CREATE
+	// TABLE. Implementations may also set up backend-specific extras
+	// (e.g. a TimescaleDB hypertable) on top of the shared schema.
+	EnsureHistoricalSchema(ctx context.Context) error
+
+	// Symbols returns the distinct symbols listed in table, which must
+	// pass dbadmin.IsAllowed — callers don't get to interpolate an
+	// arbitrary table name into SQL themselves.
+	Symbols(ctx context.Context, table string) ([]string, error)
+
+	// Quote returns symbol's closing price on the most recent trading day
+	// at or before t.
+	Quote(ctx context.Context, symbol string, t time.Time) (float64, error)
+
+	// DB returns the underlying *sql.DB for callers (migrations, ad-hoc
+	// table browsing) that need it directly.
+	DB() *sql.DB
+}
+
+// Open constructs a Store for driver ("sqlite" or "postgres"; empty
+// defaults to "sqlite") connected to dsn. It's how C.StorageDriver picks
+// the backend without callers branching on driver name themselves.
+func Open(driver, dsn string) (Store, error) {
+	switch driver {
+	case "", "sqlite":
+		return NewSQLiteStore(dsn)
+	case "postgres":
+		return NewPostgresStore(dsn)
+	default:
+		return nil, fmt.Errorf("store: unknown driver %q (want \"sqlite\" or \"postgres\")", driver)
+	}
+}
+
+// batchSize bounds how many rows go into a single multi-row INSERT/COPY
+// batch, keeping statement size and lock duration reasonable.
+const batchSize = 500
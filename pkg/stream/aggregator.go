@@ -0,0 +1,71 @@
+package stream
+
+import (
+	"time"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/types"
+)
+
+// BarAggregator rolls trades up into 1-minute OHLCV bars per symbol. It's
+// not safe for concurrent use; Client serializes calls to Add from its own
+// read loop.
+type BarAggregator struct {
+	open map[string]*types.HistoricalData
+}
+
+// NewBarAggregator returns an empty BarAggregator.
+func NewBarAggregator() *BarAggregator {
+	return &BarAggregator{open: make(map[string]*types.HistoricalData)}
+}
+
+// Add folds t into its symbol's in-progress minute bar. It returns the
+// just-closed bar and true when t falls in a later minute than the one
+// currently open, meaning the prior minute is done and safe to persist.
+func (a *BarAggregator) Add(t Trade) (types.HistoricalData, bool) {
+	minute := t.Timestamp.Truncate(time.Minute)
+
+	bar, ok := a.open[t.Symbol]
+	if !ok {
+		a.open[t.Symbol] = newBar(t, minute)
+		return types.HistoricalData{}, false
+	}
+
+	if minute.After(bar.Date) {
+		closed := *bar
+		a.open[t.Symbol] = newBar(t, minute)
+		return closed, true
+	}
+
+	bar.High = max(bar.High, t.Price)
+	bar.Low = min(bar.Low, t.Price)
+	bar.Close = t.Price
+	bar.AdjClose = t.Price
+	bar.Volume += t.Size
+
+	return types.HistoricalData{}, false
+}
+
+// Flush returns every currently in-progress bar, keyed by symbol, without
+// waiting for a trade in the next minute to close it out. Call it when
+// stopping a stream so the last partial minute isn't lost.
+func (a *BarAggregator) Flush() map[string]types.HistoricalData {
+	out := make(map[string]types.HistoricalData, len(a.open))
+	for symbol, bar := range a.open {
+		out[symbol] = *bar
+	}
+	a.open = make(map[string]*types.HistoricalData)
+	return out
+}
+
+func newBar(t Trade, minute time.Time) *types.HistoricalData {
+	return &types.HistoricalData{
+		Symbol:   t.Symbol,
+		Date:     minute,
+		Open:     t.Price,
+		High:     t.Price,
+		Low:      t.Price,
+		Close:    t.Price,
+		AdjClose: t.Price,
+		Volume:   t.Size,
+	}
+}
@@ -0,0 +1,72 @@
+package stream
+
+import (
+	"testing"
+	"time"
+)
+
+func trade(symbol string, price float64, size int64, t time.Time) Trade {
+	return Trade{Symbol: symbol, Price: price, Size: size, Timestamp: t}
+}
+
+func TestBarAggregatorAccumulatesWithinAMinute(t *testing.T) {
+	a := NewBarAggregator()
+	base := time.Date(2026, 1, 2, 9, 30, 0, 0, time.UTC)
+
+	if _, ok := a.Add(trade("AAPL", 100, 10, base)); ok {
+		t.Fatal("expected no bar closed on the first trade")
+	}
+	if _, ok := a.Add(trade("AAPL", 105, 5, base.Add(10*time.Second))); ok {
+		t.Fatal("expected no bar closed on a trade within the same minute")
+	}
+
+	bars := a.Flush()
+	bar, ok := bars["AAPL"]
+	if !ok {
+		t.Fatal("expected a flushed AAPL bar")
+	}
+	if bar.Open != 100 || bar.High != 105 || bar.Low != 100 || bar.Close != 105 || bar.Volume != 15 {
+		t.Errorf("bar = %+v, want Open=100 High=105 Low=100 Close=105 Volume=15", bar)
+	}
+}
+
+func TestBarAggregatorClosesOnMinuteRollover(t *testing.T) {
+	a := NewBarAggregator()
+	base := time.Date(2026, 1, 2, 9, 30, 0, 0, time.UTC)
+
+	a.Add(trade("AAPL", 100, 10, base))
+	a.Add(trade("AAPL", 110, 5, base.Add(30*time.Second)))
+
+	closed, ok := a.Add(trade("AAPL", 120, 1, base.Add(time.Minute)))
+	if !ok {
+		t.Fatal("expected the first minute's bar to close on a next-minute trade")
+	}
+	if closed.Open != 100 || closed.High != 110 || closed.Close != 110 || closed.Volume != 15 {
+		t.Errorf("closed bar = %+v, want Open=100 High=110 Close=110 Volume=15", closed)
+	}
+
+	bars := a.Flush()
+	bar, ok := bars["AAPL"]
+	if !ok {
+		t.Fatal("expected a flushed bar for the new minute")
+	}
+	if bar.Open != 120 || bar.Volume != 1 {
+		t.Errorf("new bar = %+v, want Open=120 Volume=1", bar)
+	}
+}
+
+func TestBarAggregatorTracksSymbolsIndependently(t *testing.T) {
+	a := NewBarAggregator()
+	now := time.Date(2026, 1, 2, 9, 30, 0, 0, time.UTC)
+
+	a.Add(trade("AAPL", 100, 1, now))
+	a.Add(trade("MSFT", 200, 1, now))
+
+	bars := a.Flush()
+	if len(bars) != 2 {
+		t.Fatalf("len(bars) = %d, want 2", len(bars))
+	}
+	if bars["AAPL"].Open != 100 || bars["MSFT"].Open != 200 {
+		t.Errorf("bars = %+v, want AAPL Open=100, MSFT Open=200", bars)
+	}
+}
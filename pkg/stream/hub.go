@@ -0,0 +1,116 @@
+package stream
+
+import "sync"
+
+// tickBuffer bounds how many trades a Subscribe channel can queue before
+// Hub.dispatch starts dropping them for that subscriber, so one slow
+// consumer (e.g. a browser tab with a stalled WebSocket write) can't block
+// delivery to every other subscriber.
+const tickBuffer = 16
+
+// Hub fans out live ticks from a single upstream Client to any number of
+// subscribers, deduping their symbol subscriptions against the one
+// upstream connection and buffering the latest tick per symbol so a
+// newly-registered subscriber can be caught up immediately instead of
+// waiting for the next trade. It's the piece that lets multiple browser
+// dashboard tabs share one Client (see cmd/web/routes.go's /ws/quotes
+// handler) instead of each opening its own upstream subscription.
+type Hub struct {
+	client *Client
+
+	mu          sync.Mutex
+	refs        map[string]int
+	subscribers map[string]map[chan Trade]struct{}
+	latest      map[string]Trade
+}
+
+// NewHub wraps client, registering itself as client's trade handler. A
+// Client keeps only its most recently registered OnTrade handler, so a
+// Client should back at most one Hub; callers that also persist bars (see
+// streamStartHandler's OnBar) can still do so independently, since Hub
+// only touches OnTrade.
+func NewHub(client *Client) *Hub {
+	h := &Hub{
+		client:      client,
+		refs:        make(map[string]int),
+		subscribers: make(map[string]map[chan Trade]struct{}),
+		latest:      make(map[string]Trade),
+	}
+	client.OnTrade(h.dispatch)
+	return h
+}
+
+// Subscribe registers a new subscriber for symbol, issuing an upstream
+// Subscribe only if this is the symbol's first subscriber, and returns a
+// channel of trades plus a cancel func to unregister. If symbol already
+// has a buffered latest tick, it's sent on the channel immediately so the
+// caller doesn't have to wait for the next trade to catch up. cancel
+// unsubscribes from upstream once the symbol's last subscriber is gone.
+func (h *Hub) Subscribe(symbol string) (<-chan Trade, func()) {
+	ch := make(chan Trade, tickBuffer)
+
+	h.mu.Lock()
+	if h.subscribers[symbol] == nil {
+		h.subscribers[symbol] = make(map[chan Trade]struct{})
+	}
+	h.subscribers[symbol][ch] = struct{}{}
+	h.refs[symbol]++
+	isFirst := h.refs[symbol] == 1
+	last, buffered := h.latest[symbol]
+	h.mu.Unlock()
+
+	if isFirst {
+		h.client.Subscribe([]string{symbol})
+	}
+	if buffered {
+		ch <- last
+	}
+
+	cancel := func() {
+		h.mu.Lock()
+		delete(h.subscribers[symbol], ch)
+		if len(h.subscribers[symbol]) == 0 {
+			delete(h.subscribers, symbol)
+		}
+		h.refs[symbol]--
+		isLast := h.refs[symbol] <= 0
+		if isLast {
+			delete(h.refs, symbol)
+		}
+		h.mu.Unlock()
+
+		if isLast {
+			h.client.Unsubscribe([]string{symbol})
+		}
+	}
+	return ch, cancel
+}
+
+// Latest returns the most recent trade seen for symbol, if any.
+func (h *Hub) Latest(symbol string) (Trade, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	t, ok := h.latest[symbol]
+	return t, ok
+}
+
+// dispatch is registered as the Client's OnTrade handler; it buffers t as
+// symbol's latest tick and fans it out to every current subscriber of that
+// symbol, dropping the tick for any subscriber whose channel is full
+// rather than blocking the shared upstream read loop.
+func (h *Hub) dispatch(t Trade) {
+	h.mu.Lock()
+	h.latest[t.Symbol] = t
+	subs := make([]chan Trade, 0, len(h.subscribers[t.Symbol]))
+	for ch := range h.subscribers[t.Symbol] {
+		subs = append(subs, ch)
+	}
+	h.mu.Unlock()
+
+	for _, ch := range subs {
+		select {
+		case ch <- t:
+		default:
+		}
+	}
+}
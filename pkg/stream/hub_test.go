@@ -0,0 +1,105 @@
+package stream
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestHub(t *testing.T) *Hub {
+	t.Helper()
+	client, err := NewClient("alpaca", "key", "secret")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	return NewHub(client)
+}
+
+func TestHubFansOutToAllSubscribersOfASymbol(t *testing.T) {
+	h := newTestHub(t)
+
+	ch1, cancel1 := h.Subscribe("AAPL")
+	defer cancel1()
+	ch2, cancel2 := h.Subscribe("AAPL")
+	defer cancel2()
+
+	h.dispatch(Trade{Symbol: "AAPL", Price: 190.5})
+
+	for _, ch := range []<-chan Trade{ch1, ch2} {
+		select {
+		case got := <-ch:
+			if got.Price != 190.5 {
+				t.Errorf("got.Price = %v, want 190.5", got.Price)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for fanned-out trade")
+		}
+	}
+}
+
+func TestHubSubscribeSendsBufferedLatestTickImmediately(t *testing.T) {
+	h := newTestHub(t)
+	h.dispatch(Trade{Symbol: "MSFT", Price: 420})
+
+	ch, cancel := h.Subscribe("MSFT")
+	defer cancel()
+
+	select {
+	case got := <-ch:
+		if got.Price != 420 {
+			t.Errorf("got.Price = %v, want 420", got.Price)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for buffered latest tick")
+	}
+}
+
+func TestHubDoesNotDeliverOtherSymbolsTrades(t *testing.T) {
+	h := newTestHub(t)
+	ch, cancel := h.Subscribe("AAPL")
+	defer cancel()
+
+	h.dispatch(Trade{Symbol: "MSFT", Price: 420})
+
+	select {
+	case got := <-ch:
+		t.Fatalf("unexpected delivery for unsubscribed symbol: %+v", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestHubUnsubscribeStopsDeliveryAndClearsRefs(t *testing.T) {
+	h := newTestHub(t)
+	ch, cancel := h.Subscribe("AAPL")
+	cancel()
+
+	h.dispatch(Trade{Symbol: "AAPL", Price: 1})
+
+	select {
+	case <-ch:
+		t.Fatal("unexpected delivery after unsubscribe")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	h.mu.Lock()
+	_, stillTracked := h.refs["AAPL"]
+	h.mu.Unlock()
+	if stillTracked {
+		t.Error("expected refs to be cleared once the last subscriber unsubscribed")
+	}
+}
+
+func TestHubLatestReturnsMostRecentTrade(t *testing.T) {
+	h := newTestHub(t)
+
+	if _, ok := h.Latest("AAPL"); ok {
+		t.Fatal("expected no latest trade before any dispatch")
+	}
+
+	h.dispatch(Trade{Symbol: "AAPL", Price: 100})
+	h.dispatch(Trade{Symbol: "AAPL", Price: 101})
+
+	got, ok := h.Latest("AAPL")
+	if !ok || got.Price != 101 {
+		t.Errorf("Latest(AAPL) = %+v, %v, want Price=101, true", got, ok)
+	}
+}
@@ -0,0 +1,212 @@
+package stream
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Source knows how to dial, authenticate, subscribe to, and decode frames
+// from one upstream streaming API. It mirrors pkg/providers.MarketDataProvider's
+// registry-of-factories shape: one small adapter per upstream, picked by
+// name.
+type Source interface {
+	// Name identifies the source, e.g. for log lines.
+	Name() string
+
+	// URL returns the WebSocket endpoint to dial.
+	URL() string
+
+	// Authenticate sends whatever handshake message conn's upstream
+	// expects immediately after the connection opens.
+	Authenticate(conn *websocket.Conn) error
+
+	// WriteSubscribe and WriteUnsubscribe add/remove symbols from the
+	// feed on an already-authenticated connection.
+	WriteSubscribe(conn *websocket.Conn, symbols []string) error
+	WriteUnsubscribe(conn *websocket.Conn, symbols []string) error
+
+	// ParseTrades decodes one inbound frame into zero or more trades,
+	// ignoring any other message types the upstream multiplexes onto the
+	// same stream (acks, quotes, bars it computes itself, ...).
+	ParseTrades(raw []byte) ([]Trade, error)
+}
+
+// getSource builds the named Source ("alpaca", "polygon", or "finnhub").
+func getSource(name, key, secret string) (Source, error) {
+	switch name {
+	case "alpaca":
+		return &alpacaSource{key: key, secret: secret}, nil
+	case "polygon":
+		return &polygonSource{key: key}, nil
+	case "finnhub":
+		return &finnhubSource{key: key}, nil
+	default:
+		return nil, fmt.Errorf("stream: unknown source %q (want one of alpaca, polygon, finnhub)", name)
+	}
+}
+
+// alpacaSource streams IEX-feed trades from Alpaca's market-data API.
+type alpacaSource struct {
+	key    string
+	secret string
+}
+
+func (s *alpacaSource) Name() string { return "alpaca" }
+func (s *alpacaSource) URL() string  { return "wss://stream.data.alpaca.markets/v2/iex" }
+
+func (s *alpacaSource) Authenticate(conn *websocket.Conn) error {
+	return conn.WriteJSON(map[string]string{
+		"action": "auth",
+		"key":    s.key,
+		"secret": s.secret,
+	})
+}
+
+func (s *alpacaSource) WriteSubscribe(conn *websocket.Conn, symbols []string) error {
+	return conn.WriteJSON(map[string]interface{}{"action": "subscribe", "trades": symbols})
+}
+
+func (s *alpacaSource) WriteUnsubscribe(conn *websocket.Conn, symbols []string) error {
+	return conn.WriteJSON(map[string]interface{}{"action": "unsubscribe", "trades": symbols})
+}
+
+func (s *alpacaSource) ParseTrades(raw []byte) ([]Trade, error) {
+	var msgs []struct {
+		Type   string  `json:"T"`
+		Symbol string  `json:"S"`
+		Price  float64 `json:"p"`
+		Size   int64   `json:"s"`
+		Time   string  `json:"t"`
+	}
+	if err := json.Unmarshal(raw, &msgs); err != nil {
+		return nil, err
+	}
+
+	var trades []Trade
+	for _, m := range msgs {
+		if m.Type != "t" {
+			continue
+		}
+		ts, err := time.Parse(time.RFC3339Nano, m.Time)
+		if err != nil {
+			ts = time.Now()
+		}
+		trades = append(trades, Trade{Symbol: m.Symbol, Price: m.Price, Size: m.Size, Timestamp: ts})
+	}
+	return trades, nil
+}
+
+// polygonSource streams trades from Polygon.io's market-data API.
+type polygonSource struct {
+	key string
+}
+
+func (s *polygonSource) Name() string { return "polygon" }
+func (s *polygonSource) URL() string  { return "wss://socket.polygon.io/stocks" }
+
+func (s *polygonSource) Authenticate(conn *websocket.Conn) error {
+	return conn.WriteJSON(map[string]string{"action": "auth", "params": s.key})
+}
+
+func (s *polygonSource) WriteSubscribe(conn *websocket.Conn, symbols []string) error {
+	return conn.WriteJSON(map[string]string{"action": "subscribe", "params": joinPrefixed("T.", symbols)})
+}
+
+func (s *polygonSource) WriteUnsubscribe(conn *websocket.Conn, symbols []string) error {
+	return conn.WriteJSON(map[string]string{"action": "unsubscribe", "params": joinPrefixed("T.", symbols)})
+}
+
+func (s *polygonSource) ParseTrades(raw []byte) ([]Trade, error) {
+	var msgs []struct {
+		Type   string  `json:"ev"`
+		Symbol string  `json:"sym"`
+		Price  float64 `json:"p"`
+		Size   int64   `json:"s"`
+		Time   int64   `json:"t"` // epoch millis
+	}
+	if err := json.Unmarshal(raw, &msgs); err != nil {
+		return nil, err
+	}
+
+	var trades []Trade
+	for _, m := range msgs {
+		if m.Type != "T" {
+			continue
+		}
+		trades = append(trades, Trade{
+			Symbol:    m.Symbol,
+			Price:     m.Price,
+			Size:      m.Size,
+			Timestamp: time.UnixMilli(m.Time),
+		})
+	}
+	return trades, nil
+}
+
+// finnhubSource streams trades from Finnhub's WebSocket API.
+type finnhubSource struct {
+	key string
+}
+
+func (s *finnhubSource) Name() string { return "finnhub" }
+func (s *finnhubSource) URL() string  { return "wss://ws.finnhub.io?token=" + s.key }
+
+// Finnhub authenticates via the token in the connection URL, so there's no
+// separate handshake message to send.
+func (s *finnhubSource) Authenticate(conn *websocket.Conn) error { return nil }
+
+func (s *finnhubSource) WriteSubscribe(conn *websocket.Conn, symbols []string) error {
+	for _, sym := range symbols {
+		if err := conn.WriteJSON(map[string]string{"type": "subscribe", "symbol": sym}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *finnhubSource) WriteUnsubscribe(conn *websocket.Conn, symbols []string) error {
+	for _, sym := range symbols {
+		if err := conn.WriteJSON(map[string]string{"type": "unsubscribe", "symbol": sym}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *finnhubSource) ParseTrades(raw []byte) ([]Trade, error) {
+	var msg struct {
+		Type string `json:"type"`
+		Data []struct {
+			Symbol string  `json:"s"`
+			Price  float64 `json:"p"`
+			Size   int64   `json:"v"`
+			Time   int64   `json:"t"` // epoch millis
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		return nil, err
+	}
+	if msg.Type != "trade" {
+		return nil, nil
+	}
+
+	trades := make([]Trade, len(msg.Data))
+	for i, d := range msg.Data {
+		trades[i] = Trade{Symbol: d.Symbol, Price: d.Price, Size: d.Size, Timestamp: time.UnixMilli(d.Time)}
+	}
+	return trades, nil
+}
+
+func joinPrefixed(prefix string, symbols []string) string {
+	out := ""
+	for i, s := range symbols {
+		if i > 0 {
+			out += ","
+		}
+		out += prefix + s
+	}
+	return out
+}
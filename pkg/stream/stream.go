@@ -0,0 +1,241 @@
+// Package stream connects to a real-time market-data WebSocket feed
+// (Alpaca, Polygon, or Finnhub), aggregates incoming trades into 1-minute
+// bars, and hands them off to a caller-supplied sink — typically one that
+// upserts into stock_historical_data the same way a historical fill does.
+// It's modeled on Alpaca's marketdata/stream client: authenticate,
+// subscribe, reconnect with backoff on any drop.
+package stream
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/types"
+	"github.com/gorilla/websocket"
+)
+
+// Trade is a single executed trade as reported by the upstream feed.
+type Trade struct {
+	Symbol    string
+	Price     float64
+	Size      int64
+	Timestamp time.Time
+}
+
+// minBackoff and maxBackoff bound Client.Run's reconnect delay; it doubles
+// from minBackoff towards maxBackoff on each consecutive failed attempt and
+// resets once a connection stays up long enough to receive a message.
+const (
+	minBackoff = 1 * time.Second
+	maxBackoff = 30 * time.Second
+)
+
+// Client streams trades for a set of symbols from one Source, aggregates
+// them into 1-minute bars via BarAggregator, and dispatches both to any
+// registered handlers. A Client is safe for concurrent use.
+type Client struct {
+	source Source
+
+	mu       sync.Mutex
+	symbols  map[string]bool
+	conn     *websocket.Conn
+	onTrade  func(Trade)
+	onBar    func(symbol string, bar types.HistoricalData)
+	agg      *BarAggregator
+}
+
+// NewClient returns a Client for the named source ("alpaca", "polygon", or
+// "finnhub"), authenticating with key/secret (secret is ignored by sources
+// that only need a single token, e.g. Finnhub and Polygon).
+func NewClient(sourceName, key, secret string) (*Client, error) {
+	source, err := getSource(sourceName, key, secret)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		source:  source,
+		symbols: make(map[string]bool),
+		agg:     NewBarAggregator(),
+	}, nil
+}
+
+// OnTrade registers fn to be called with every trade the feed delivers, in
+// addition to bar aggregation. fn is called from Run's read loop, so it
+// must not block.
+func (c *Client) OnTrade(fn func(Trade)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onTrade = fn
+}
+
+// OnBar registers fn to be called whenever BarAggregator closes out a
+// 1-minute bar for symbol.
+func (c *Client) OnBar(fn func(symbol string, bar types.HistoricalData)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onBar = fn
+}
+
+// Subscribe adds symbols to the feed. If the client is already connected,
+// it sends the subscribe message immediately; otherwise the symbols are
+// sent as soon as Run establishes a connection.
+func (c *Client) Subscribe(symbols []string) error {
+	c.mu.Lock()
+	for _, s := range symbols {
+		c.symbols[s] = true
+	}
+	conn := c.conn
+	c.mu.Unlock()
+
+	if conn == nil {
+		return nil
+	}
+	return c.source.WriteSubscribe(conn, symbols)
+}
+
+// Unsubscribe removes symbols from the feed, sending an unsubscribe
+// message immediately if connected.
+func (c *Client) Unsubscribe(symbols []string) error {
+	c.mu.Lock()
+	for _, s := range symbols {
+		delete(c.symbols, s)
+	}
+	conn := c.conn
+	c.mu.Unlock()
+
+	if conn == nil {
+		return nil
+	}
+	return c.source.WriteUnsubscribe(conn, symbols)
+}
+
+// Run connects to the source and processes trades until ctx is canceled,
+// reconnecting with exponential backoff on any read/dial error. It returns
+// nil when ctx is canceled and a non-nil error only if it gives up for a
+// reason unrelated to ctx (none currently — it retries forever).
+func (c *Client) Run(ctx context.Context) error {
+	backoff := minBackoff
+	for {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		conn, err := c.connect(ctx)
+		if err != nil {
+			log.Printf("stream: %s: connect failed: %v (retrying in %s)", c.source.Name(), err, backoff)
+			if !sleepOrDone(ctx, backoff) {
+				return nil
+			}
+			backoff = nextBackoff(backoff)
+			continue
+		}
+
+		backoff = minBackoff
+		c.readLoop(ctx, conn)
+
+		c.mu.Lock()
+		c.conn = nil
+		c.mu.Unlock()
+	}
+}
+
+// Flush returns every bar the aggregator currently has in progress,
+// without waiting for the next minute's trade to close it out, clearing
+// them so they aren't double-reported. Call it when stopping a stream so
+// the last partial minute isn't lost.
+func (c *Client) Flush() map[string]types.HistoricalData {
+	return c.agg.Flush()
+}
+
+func (c *Client) connect(ctx context.Context) (*websocket.Conn, error) {
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, c.source.URL(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("stream: dial %s: %w", c.source.Name(), err)
+	}
+
+	if err := c.source.Authenticate(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("stream: authenticate with %s: %w", c.source.Name(), err)
+	}
+
+	c.mu.Lock()
+	c.conn = conn
+	symbols := make([]string, 0, len(c.symbols))
+	for s := range c.symbols {
+		symbols = append(symbols, s)
+	}
+	c.mu.Unlock()
+
+	if len(symbols) > 0 {
+		if err := c.source.WriteSubscribe(conn, symbols); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("stream: subscribe with %s: %w", c.source.Name(), err)
+		}
+	}
+
+	return conn, nil
+}
+
+// readLoop decodes frames until the connection drops or ctx is canceled,
+// flushing any bar the aggregator closes out as trades arrive.
+func (c *Client) readLoop(ctx context.Context, conn *websocket.Conn) {
+	defer conn.Close()
+
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	for {
+		_, raw, err := conn.ReadMessage()
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("stream: %s: read error: %v", c.source.Name(), err)
+			}
+			return
+		}
+
+		trades, err := c.source.ParseTrades(raw)
+		if err != nil {
+			log.Printf("stream: %s: failed to parse frame: %v", c.source.Name(), err)
+			continue
+		}
+
+		for _, t := range trades {
+			c.mu.Lock()
+			onTrade := c.onTrade
+			onBar := c.onBar
+			c.mu.Unlock()
+
+			if onTrade != nil {
+				onTrade(t)
+			}
+
+			if closed, ok := c.agg.Add(t); ok && onBar != nil {
+				onBar(t.Symbol, closed)
+			}
+		}
+	}
+}
+
+func sleepOrDone(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func nextBackoff(d time.Duration) time.Duration {
+	d *= 2
+	if d > maxBackoff {
+		d = maxBackoff
+	}
+	return d
+}
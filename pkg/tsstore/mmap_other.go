@@ -0,0 +1,25 @@
+//go:build !unix
+
+package tsstore
+
+import (
+	"fmt"
+	"os"
+)
+
+// mmapFile is the non-unix fallback: syscall.Mmap isn't available, so it
+// just reads the requested range into a regular Go slice. Callers only
+// ever read it, so this is behaviorally equivalent to the mapped version,
+// just not backed by the page cache directly.
+func mmapFile(f *os.File, offset, size int) ([]byte, func() error, error) {
+	if size == 0 {
+		return nil, func() error { return nil }, nil
+	}
+
+	data := make([]byte, size)
+	if _, err := f.ReadAt(data, int64(offset)); err != nil {
+		return nil, nil, fmt.Errorf("tsstore: read failed: %w", err)
+	}
+
+	return data, func() error { return nil }, nil
+}
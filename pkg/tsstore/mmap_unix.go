@@ -0,0 +1,25 @@
+//go:build unix
+
+package tsstore
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// mmapFile maps size bytes of f starting at offset into memory read-only,
+// returning the mapped slice and a func to unmap it once the caller is
+// done scanning.
+func mmapFile(f *os.File, offset, size int) ([]byte, func() error, error) {
+	if size == 0 {
+		return nil, func() error { return nil }, nil
+	}
+
+	data, err := syscall.Mmap(int(f.Fd()), int64(offset), size, syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, fmt.Errorf("tsstore: mmap failed: %w", err)
+	}
+
+	return data, func() error { return syscall.Munmap(data) }, nil
+}
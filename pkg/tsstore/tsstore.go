@@ -0,0 +1,366 @@
+// Package tsstore is a columnar, append-only time-series store for
+// types.HistoricalData, inspired by MarketStore's DataFrame-oriented
+// layout: one fixed-width binary file per symbol per year, with an
+// in-memory index of which years exist for each symbol. Query
+// memory-maps the files it needs (see mmapFile) rather than issuing
+// per-row SQL, so a backtest can scan a symbol's whole history as a
+// single contiguous read.
+package tsstore
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/memory"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/types"
+)
+
+// columns is the fixed on-disk column layout every data file uses, in
+// storage order. Query's cols argument selects a subset of these.
+var columns = []string{"date", "open", "high", "low", "close", "adj_close", "volume"}
+
+// rowSize is one row's on-disk width: one 8-byte value per column (int64
+// or float64, the latter reinterpreted via math.Float64bits/Float64frombits).
+const rowSize = 7 * 8
+
+// fileMagic identifies a tsstore data file; headerVersion bumps if the
+// on-disk layout changes in a way old readers can't handle.
+var fileMagic = [8]byte{'T', 'S', 'S', 'T', 'O', 'R', 'E', '1'}
+
+const headerVersion uint32 = 1
+
+// headerSize is the fixed header written once at the start of every data
+// file: magic + version + column count. The column layout itself isn't
+// stored per-file since every file in a FileStore shares the same columns
+// slice; headerSize exists so a reader can skip straight to row data.
+const headerSize = len(fileMagic) + 4 + 4
+
+// yearFile is one entry in FileStore's in-memory index: where a symbol's
+// year of bars lives on disk and how many rows it holds, so Query knows
+// how many bytes past the header to read without re-stat'ing the file.
+type yearFile struct {
+	year int
+	path string
+	rows int64
+}
+
+// FileStore is a TimeSeriesStore backed by one fixed-width file per symbol
+// per year under baseDir (baseDir/SYMBOL/YEAR.tsb). It keeps an in-memory
+// index of which year-files exist per symbol so Query and Symbols don't
+// have to walk the filesystem on every call.
+type FileStore struct {
+	baseDir string
+
+	mu    sync.RWMutex
+	index map[string][]*yearFile // sorted by year ascending
+}
+
+// Open returns a FileStore rooted at baseDir, creating it if it doesn't
+// exist yet and rebuilding the in-memory index from whatever data files
+// are already there.
+func Open(baseDir string) (*FileStore, error) {
+	if err := os.MkdirAll(baseDir, 0o755); err != nil {
+		return nil, fmt.Errorf("tsstore: failed to create base dir %s: %w", baseDir, err)
+	}
+
+	fs := &FileStore{baseDir: baseDir, index: make(map[string][]*yearFile)}
+	if err := fs.loadIndex(); err != nil {
+		return nil, err
+	}
+	return fs, nil
+}
+
+// loadIndex walks baseDir's symbol subdirectories and registers every
+// YEAR.tsb file it finds, deriving each one's row count from its size.
+func (fs *FileStore) loadIndex() error {
+	entries, err := os.ReadDir(fs.baseDir)
+	if err != nil {
+		return fmt.Errorf("tsstore: failed to list base dir: %w", err)
+	}
+
+	for _, symDir := range entries {
+		if !symDir.IsDir() {
+			continue
+		}
+		symbol := symDir.Name()
+
+		yearFiles, err := os.ReadDir(filepath.Join(fs.baseDir, symbol))
+		if err != nil {
+			return fmt.Errorf("tsstore: failed to list %s: %w", symbol, err)
+		}
+
+		for _, yf := range yearFiles {
+			year, ok := parseYearFilename(yf.Name())
+			if !ok {
+				continue
+			}
+
+			path := filepath.Join(fs.baseDir, symbol, yf.Name())
+			info, err := yf.Info()
+			if err != nil {
+				return fmt.Errorf("tsstore: failed to stat %s: %w", path, err)
+			}
+
+			rows := (info.Size() - int64(headerSize)) / rowSize
+			if rows < 0 {
+				rows = 0
+			}
+			fs.index[symbol] = append(fs.index[symbol], &yearFile{year: year, path: path, rows: rows})
+		}
+
+		sort.Slice(fs.index[symbol], func(i, j int) bool {
+			return fs.index[symbol][i].year < fs.index[symbol][j].year
+		})
+	}
+
+	return nil
+}
+
+func parseYearFilename(name string) (int, bool) {
+	if !strings.HasSuffix(name, ".tsb") {
+		return 0, false
+	}
+	year, err := strconv.Atoi(strings.TrimSuffix(name, ".tsb"))
+	if err != nil {
+		return 0, false
+	}
+	return year, true
+}
+
+// Append adds bars to symbol's series, grouping them by calendar year and
+// writing each year's group to its own file (creating it, with a fresh
+// header, the first time that symbol/year combination is seen). Bars
+// don't need to already be sorted; Append sorts each year's group by date
+// before writing.
+func (fs *FileStore) Append(symbol string, bars []types.HistoricalData) error {
+	if len(bars) == 0 {
+		return nil
+	}
+
+	byYear := make(map[int][]types.HistoricalData)
+	for _, b := range bars {
+		byYear[b.Date.Year()] = append(byYear[b.Date.Year()], b)
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	for year, group := range byYear {
+		sort.Slice(group, func(i, j int) bool { return group[i].Date.Before(group[j].Date) })
+
+		yf := fs.findOrCreateYearFile(symbol, year)
+		n, err := appendRows(yf.path, group)
+		if err != nil {
+			return fmt.Errorf("tsstore: failed to append %s %d: %w", symbol, year, err)
+		}
+		yf.rows += int64(n)
+	}
+
+	return nil
+}
+
+// findOrCreateYearFile returns symbol's index entry for year, registering
+// a new file (and writing its header) the first time it's needed.
+func (fs *FileStore) findOrCreateYearFile(symbol string, year int) *yearFile {
+	for _, yf := range fs.index[symbol] {
+		if yf.year == year {
+			return yf
+		}
+	}
+
+	symDir := filepath.Join(fs.baseDir, symbol)
+	path := filepath.Join(symDir, fmt.Sprintf("%d.tsb", year))
+
+	yf := &yearFile{year: year, path: path}
+	fs.index[symbol] = append(fs.index[symbol], yf)
+	sort.Slice(fs.index[symbol], func(i, j int) bool {
+		return fs.index[symbol][i].year < fs.index[symbol][j].year
+	})
+	return yf
+}
+
+// appendRows opens (creating if needed) the data file at path, writing a
+// header first if the file is new, and appends rows as fixed-width
+// records. It returns how many rows were written.
+func appendRows(path string, rows []types.HistoricalData) (int, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return 0, err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	if info.Size() == 0 {
+		if err := writeHeader(f); err != nil {
+			return 0, err
+		}
+	}
+
+	buf := make([]byte, rowSize*len(rows))
+	for i, r := range rows {
+		row := buf[i*rowSize : (i+1)*rowSize]
+		binary.LittleEndian.PutUint64(row[0:8], uint64(r.Date.Unix()))
+		binary.LittleEndian.PutUint64(row[8:16], math.Float64bits(r.Open))
+		binary.LittleEndian.PutUint64(row[16:24], math.Float64bits(r.High))
+		binary.LittleEndian.PutUint64(row[24:32], math.Float64bits(r.Low))
+		binary.LittleEndian.PutUint64(row[32:40], math.Float64bits(r.Close))
+		binary.LittleEndian.PutUint64(row[40:48], math.Float64bits(r.AdjClose))
+		binary.LittleEndian.PutUint64(row[48:56], uint64(r.Volume))
+	}
+
+	if _, err := f.Write(buf); err != nil {
+		return 0, err
+	}
+	return len(rows), nil
+}
+
+// writeHeader writes the fixed tsstore header to a brand-new file.
+func writeHeader(f *os.File) error {
+	buf := make([]byte, headerSize)
+	copy(buf, fileMagic[:])
+	binary.LittleEndian.PutUint32(buf[8:12], headerVersion)
+	binary.LittleEndian.PutUint32(buf[12:16], uint32(len(columns)))
+	_, err := f.Write(buf)
+	return err
+}
+
+// Query returns symbol's bars in [start, end] (inclusive) as an
+// arrow.Record with one column per name in cols (order preserved); an
+// empty cols returns every column in columns. It memory-maps each
+// relevant year-file rather than copying the whole thing into Go memory.
+func (fs *FileStore) Query(symbol string, start, end time.Time, cols []string) (array.Record, error) {
+	if len(cols) == 0 {
+		cols = columns
+	}
+	fields, colIdx, err := resolveColumns(cols)
+	if err != nil {
+		return nil, err
+	}
+
+	fs.mu.RLock()
+	yearFiles := append([]*yearFile(nil), fs.index[symbol]...)
+	fs.mu.RUnlock()
+
+	mem := memory.NewGoAllocator()
+	schema := arrow.NewSchema(fields, nil)
+	b := array.NewRecordBuilder(mem, schema)
+	defer b.Release()
+
+	for _, yf := range yearFiles {
+		if yf.year < start.Year() || yf.year > end.Year() || yf.rows == 0 {
+			continue
+		}
+
+		if err := scanYearFile(yf, start, end, colIdx, b); err != nil {
+			return nil, fmt.Errorf("tsstore: failed to scan %s: %w", yf.path, err)
+		}
+	}
+
+	rec := b.NewRecord()
+	return rec, nil
+}
+
+// resolveColumns validates cols against the fixed column layout and
+// returns the arrow.Field for each plus its index into a raw row.
+func resolveColumns(cols []string) ([]arrow.Field, []int, error) {
+	dtype := func(name string) arrow.DataType {
+		if name == "date" || name == "volume" {
+			return arrow.PrimitiveTypes.Int64
+		}
+		return arrow.PrimitiveTypes.Float64
+	}
+
+	fields := make([]arrow.Field, len(cols))
+	idx := make([]int, len(cols))
+	for i, name := range cols {
+		pos := indexOf(columns, name)
+		if pos < 0 {
+			return nil, nil, fmt.Errorf("tsstore: unknown column %q", name)
+		}
+		fields[i] = arrow.Field{Name: name, Type: dtype(name)}
+		idx[i] = pos
+	}
+	return fields, idx, nil
+}
+
+func indexOf(haystack []string, needle string) int {
+	for i, s := range haystack {
+		if s == needle {
+			return i
+		}
+	}
+	return -1
+}
+
+// scanYearFile memory-maps yf's row data and appends every row within
+// [start, end] to b, projecting only the columns in colIdx.
+func scanYearFile(yf *yearFile, start, end time.Time, colIdx []int, b *array.RecordBuilder) error {
+	f, err := os.Open(yf.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// mmap requires a page-aligned offset, so the whole file (header
+	// included) is mapped from 0 and the header is sliced off afterward.
+	mapped, unmap, err := mmapFile(f, 0, headerSize+int(yf.rows*rowSize))
+	if err != nil {
+		return err
+	}
+	defer unmap()
+	data := mapped[headerSize:]
+
+	startUnix, endUnix := start.Unix(), end.Unix()
+	for i := 0; i < int(yf.rows); i++ {
+		row := data[i*rowSize : (i+1)*rowSize]
+		date := int64(binary.LittleEndian.Uint64(row[0:8]))
+		if date < startUnix || date > endUnix {
+			continue
+		}
+
+		for fieldPos, col := range colIdx {
+			switch col {
+			case 0: // date
+				b.Field(fieldPos).(*array.Int64Builder).Append(date)
+			case 6: // volume
+				b.Field(fieldPos).(*array.Int64Builder).Append(int64(binary.LittleEndian.Uint64(row[48:56])))
+			default: // open, high, low, close, adj_close
+				offset := 8 + (col-1)*8
+				b.Field(fieldPos).(*array.Float64Builder).Append(math.Float64frombits(binary.LittleEndian.Uint64(row[offset : offset+8])))
+			}
+		}
+	}
+
+	return nil
+}
+
+// Symbols returns every symbol the store has data for, sorted.
+func (fs *FileStore) Symbols() []string {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	symbols := make([]string, 0, len(fs.index))
+	for symbol := range fs.index {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
@@ -0,0 +1,84 @@
+package tsstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/arrow/array"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/types"
+)
+
+func TestAppendAndQueryRoundTrip(t *testing.T) {
+	fs, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	bars := []types.HistoricalData{
+		{Symbol: "AAPL", Date: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Open: 10, High: 11, Low: 9, Close: 10.5, AdjClose: 10.5, Volume: 100},
+		{Symbol: "AAPL", Date: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), Open: 20, High: 21, Low: 19, Close: 20.5, AdjClose: 20.5, Volume: 200},
+		{Symbol: "AAPL", Date: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), Open: 30, High: 31, Low: 29, Close: 30.5, AdjClose: 30.5, Volume: 300},
+	}
+	if err := fs.Append("AAPL", bars); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+
+	rec, err := fs.Query("AAPL", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC), []string{"date", "close", "volume"})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	defer rec.Release()
+
+	if got, want := rec.NumRows(), int64(2); got != want {
+		t.Fatalf("NumRows() = %d, want %d (2024 bars only)", got, want)
+	}
+
+	closes := rec.Column(1).(*array.Float64).Float64Values()
+	if closes[0] != 10.5 || closes[1] != 20.5 {
+		t.Errorf("close column = %v, want [10.5 20.5]", closes)
+	}
+
+	volumes := rec.Column(2).(*array.Int64).Int64Values()
+	if volumes[0] != 100 || volumes[1] != 200 {
+		t.Errorf("volume column = %v, want [100 200]", volumes)
+	}
+}
+
+func TestSymbolsReturnsEveryAppendedSymbol(t *testing.T) {
+	fs, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	bar := types.HistoricalData{Date: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}
+	if err := fs.Append("MSFT", []types.HistoricalData{bar}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if err := fs.Append("AAPL", []types.HistoricalData{bar}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+
+	got := fs.Symbols()
+	want := []string{"AAPL", "MSFT"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("Symbols() = %v, want %v", got, want)
+	}
+}
+
+func TestQueryReturnsEmptyRecordForUnknownSymbol(t *testing.T) {
+	fs, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	rec, err := fs.Query("NOPE", time.Now().AddDate(-1, 0, 0), time.Now(), nil)
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	defer rec.Release()
+
+	if rec.NumRows() != 0 {
+		t.Errorf("NumRows() = %d, want 0", rec.NumRows())
+	}
+}
@@ -0,0 +1,259 @@
+// Package metrics is a small, dependency-free Prometheus exposition-format
+// metrics registry: Counters and Histograms that WriteTo a io.Writer in the
+// text format Prometheus scrapes, without pulling in the full
+// prometheus/client_golang dependency tree. It exists so fetch error rates
+// and DB latency are visible in Grafana instead of only in logs.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// metric is anything Registry can scrape into the exposition format.
+type metric interface {
+	writeTo(w io.Writer) error
+}
+
+// Registry holds every metric a process exposes. DefaultRegistry is what
+// Handler serves; most callers never need their own.
+type Registry struct {
+	mu      sync.Mutex
+	metrics []metric
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) register(m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics = append(r.metrics, m)
+}
+
+// Render writes every registered metric to w in Prometheus text exposition
+// format (version 0.0.4). It isn't named WriteTo: that name is reserved by
+// io.WriterTo's (int64, error) signature, which this doesn't match.
+func (r *Registry) Render(w io.Writer) error {
+	r.mu.Lock()
+	metrics := append([]metric(nil), r.metrics...)
+	r.mu.Unlock()
+
+	for _, m := range metrics {
+		if err := m.writeTo(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// labelKey joins label values into a map key; label values themselves
+// aren't allowed to contain this separator (metric label values here are
+// always our own enum-like strings: source names, "ok"/"error", etc.).
+const labelKeySep = "\x1f"
+
+func labelKey(values []string) string {
+	return strings.Join(values, labelKeySep)
+}
+
+// Counter is a monotonically increasing value, optionally partitioned by a
+// fixed set of label names (e.g. "source", "symbol", "result").
+type Counter struct {
+	name       string
+	help       string
+	labelNames []string
+
+	mu     sync.Mutex
+	values map[string]*labeledValue
+}
+
+type labeledValue struct {
+	labelValues []string
+	value       float64
+}
+
+// NewCounter creates, registers, and returns a Counter named name with the
+// given labelNames (in the order callers must pass them to Inc/Add).
+func NewCounter(reg *Registry, name, help string, labelNames ...string) *Counter {
+	c := &Counter{name: name, help: help, labelNames: labelNames, values: make(map[string]*labeledValue)}
+	reg.register(c)
+	return c
+}
+
+// Inc increments the counter for the given label values (in labelNames
+// order) by 1.
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add increments the counter for the given label values by n.
+func (c *Counter) Add(n float64, labelValues ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := labelKey(labelValues)
+	lv, ok := c.values[key]
+	if !ok {
+		lv = &labeledValue{labelValues: append([]string(nil), labelValues...)}
+		c.values[key] = lv
+	}
+	lv.value += n
+}
+
+func (c *Counter) writeTo(w io.Writer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name); err != nil {
+		return err
+	}
+	for _, lv := range sortedValues(c.values) {
+		if _, err := fmt.Fprintf(w, "%s%s %s\n", c.name, formatLabels(c.labelNames, lv.labelValues), formatFloat(lv.value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DefaultBuckets are the histogram bucket upper bounds (in seconds) used
+// for every Histogram in this package unless overridden: sub-millisecond
+// through 10s, which comfortably spans both a DB query and a retried
+// upstream HTTP fetch.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Histogram observes a float64 value (seconds, for every metric this
+// package currently defines) into a fixed set of cumulative buckets,
+// optionally partitioned by label names.
+type Histogram struct {
+	name       string
+	help       string
+	labelNames []string
+	buckets    []float64
+
+	mu   sync.Mutex
+	data map[string]*histogramValue
+}
+
+type histogramValue struct {
+	labelValues []string
+	bucketCount []int64
+	sum         float64
+	count       int64
+}
+
+// NewHistogram creates, registers, and returns a Histogram named name,
+// bucketed at buckets (upper bounds, ascending), with the given labelNames.
+func NewHistogram(reg *Registry, name, help string, buckets []float64, labelNames ...string) *Histogram {
+	h := &Histogram{
+		name:       name,
+		help:       help,
+		labelNames: labelNames,
+		buckets:    buckets,
+		data:       make(map[string]*histogramValue),
+	}
+	reg.register(h)
+	return h
+}
+
+// Observe records value (seconds) for the given label values. bucketCount[i]
+// is kept as the cumulative count of observations <= buckets[i] (Prometheus
+// bucket semantics), so writeTo can emit it directly without re-accumulating.
+func (h *Histogram) Observe(value float64, labelValues ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	key := labelKey(labelValues)
+	hv, ok := h.data[key]
+	if !ok {
+		hv = &histogramValue{
+			labelValues: append([]string(nil), labelValues...),
+			bucketCount: make([]int64, len(h.buckets)),
+		}
+		h.data[key] = hv
+	}
+
+	for i, upperBound := range h.buckets {
+		if value <= upperBound {
+			hv.bucketCount[i]++
+		}
+	}
+	hv.sum += value
+	hv.count++
+}
+
+func (h *Histogram) writeTo(w io.Writer) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name); err != nil {
+		return err
+	}
+
+	type sortable struct {
+		labelValues []string
+		*histogramValue
+	}
+	values := make([]sortable, 0, len(h.data))
+	for _, hv := range h.data {
+		values = append(values, sortable{hv.labelValues, hv})
+	}
+	sort.Slice(values, func(i, j int) bool {
+		return labelKey(values[i].labelValues) < labelKey(values[j].labelValues)
+	})
+
+	for _, v := range values {
+		for i, upperBound := range h.buckets {
+			bucketLabels := append(append([]string(nil), v.labelValues...), formatFloat(upperBound))
+			bucketLabelNames := append(append([]string(nil), h.labelNames...), "le")
+			if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(bucketLabelNames, bucketLabels), v.bucketCount[i]); err != nil {
+				return err
+			}
+		}
+		infLabels := append(append([]string(nil), v.labelValues...), "+Inf")
+		infLabelNames := append(append([]string(nil), h.labelNames...), "le")
+		if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(infLabelNames, infLabels), v.count); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%s_sum%s %s\n", h.name, formatLabels(h.labelNames, v.labelValues), formatFloat(v.sum)); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%s_count%s %d\n", h.name, formatLabels(h.labelNames, v.labelValues), v.count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sortedValues(values map[string]*labeledValue) []*labeledValue {
+	out := make([]*labeledValue, 0, len(values))
+	for _, v := range values {
+		out = append(out, v)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return labelKey(out[i].labelValues) < labelKey(out[j].labelValues)
+	})
+	return out
+}
+
+// formatLabels renders {name="value",...} for a metric line, or "" if
+// names is empty.
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s=%q", name, values[i])
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCounterRender(t *testing.T) {
+	reg := NewRegistry()
+	c := NewCounter(reg, "test_counter_total", "A test counter.", "result")
+
+	c.Inc("ok")
+	c.Inc("ok")
+	c.Add(3, "error")
+
+	var buf strings.Builder
+	if err := reg.Render(&buf); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	out := buf.String()
+
+	wantLines := []string{
+		"# HELP test_counter_total A test counter.",
+		"# TYPE test_counter_total counter",
+		`test_counter_total{result="error"} 3`,
+		`test_counter_total{result="ok"} 2`,
+	}
+	for _, want := range wantLines {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing line %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestHistogramRender(t *testing.T) {
+	reg := NewRegistry()
+	h := NewHistogram(reg, "test_duration_seconds", "A test histogram.", []float64{0.1, 1}, "source")
+
+	h.Observe(0.05, "yahoo")
+	h.Observe(0.5, "yahoo")
+	h.Observe(5, "yahoo")
+
+	var buf strings.Builder
+	if err := reg.Render(&buf); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	out := buf.String()
+
+	wantLines := []string{
+		"# HELP test_duration_seconds A test histogram.",
+		"# TYPE test_duration_seconds histogram",
+		`test_duration_seconds_bucket{source="yahoo",le="0.1"} 1`,
+		`test_duration_seconds_bucket{source="yahoo",le="1"} 2`,
+		`test_duration_seconds_bucket{source="yahoo",le="+Inf"} 3`,
+		`test_duration_seconds_sum{source="yahoo"} 5.55`,
+		`test_duration_seconds_count{source="yahoo"} 3`,
+	}
+	for _, want := range wantLines {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing line %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestCounterUnlabeled(t *testing.T) {
+	reg := NewRegistry()
+	c := NewCounter(reg, "test_unlabeled_total", "An unlabeled counter.")
+	c.Add(7)
+
+	var buf strings.Builder
+	if err := reg.Render(&buf); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if want := "test_unlabeled_total 7\n"; !strings.Contains(buf.String(), want) {
+		t.Errorf("output missing line %q, got:\n%s", want, buf.String())
+	}
+}
@@ -0,0 +1,46 @@
+package metrics
+
+import "net/http"
+
+// DefaultRegistry is the registry Handler serves; fetchers, the DB
+// decorator, and anything else instrumented in-process register against
+// this instead of threading a *Registry through every call.
+var DefaultRegistry = NewRegistry()
+
+var (
+	// StockFetchTotal counts StockData/HistoricalData fetches by upstream
+	// source, symbol, and outcome ("ok" or "error").
+	StockFetchTotal = NewCounter(DefaultRegistry, "stock_fetch_total",
+		"Count of stock data fetches by source, symbol, and result.",
+		"source", "symbol", "result")
+
+	// StockFetchDuration observes how long a fetch took, per source.
+	StockFetchDuration = NewHistogram(DefaultRegistry, "stock_fetch_duration_seconds",
+		"Latency of stock data fetches, in seconds, by source.",
+		DefaultBuckets, "source")
+
+	// HistoricalRowsIngestedTotal counts rows written to historical
+	// storage (SQL or pkg/tsstore).
+	HistoricalRowsIngestedTotal = NewCounter(DefaultRegistry, "historical_rows_ingested_total",
+		"Count of historical rows ingested.")
+
+	// DBQueryDurationSeconds observes DB.Query/Exec latency, labeled by a
+	// short query name (not the raw SQL, to keep cardinality bounded).
+	DBQueryDurationSeconds = NewHistogram(DefaultRegistry, "db_query_duration_seconds",
+		"Latency of DB queries, in seconds, by query name.",
+		DefaultBuckets, "query")
+
+	// BacktestTradesTotal counts trades a backtest run executed, by side
+	// ("buy" or "sell").
+	BacktestTradesTotal = NewCounter(DefaultRegistry, "backtest_trades_total",
+		"Count of backtest trades executed, by side.",
+		"side")
+)
+
+// Handler serves DefaultRegistry in Prometheus text exposition format.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		DefaultRegistry.Render(w)
+	})
+}
@@ -0,0 +1,45 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Sentinel errors a MarketDataProvider's FetchDaily/FetchIntraday can wrap
+// (via fmt.Errorf's %w) so the provider fallback chain and retry logic can
+// branch on error class with errors.Is instead of matching status codes or
+// substrings in an error's text.
+var (
+	// ErrRateLimited means the upstream rejected the request with a 429;
+	// RetryableClient already retries these internally, so seeing this
+	// means every retry was also rate-limited.
+	ErrRateLimited = errors.New("provider: rate limited")
+
+	// ErrNoData means the request succeeded but returned nothing usable
+	// for the requested symbol/range.
+	ErrNoData = errors.New("provider: no data")
+
+	// ErrProviderDown means the upstream itself is unavailable (a 5xx
+	// after exhausting retries, or a connection failure).
+	ErrProviderDown = errors.New("provider: unavailable")
+
+	// ErrParse means a response was received but couldn't be decoded into
+	// the shape a provider expects.
+	ErrParse = errors.New("provider: failed to parse response")
+)
+
+// ClassifyHTTPStatus maps a non-200 HTTP status from an upstream
+// market-data API to one of the sentinel errors above, so callers can wrap
+// it (fmt.Errorf("yahoo: %w", ClassifyHTTPStatus(status))) instead of
+// returning a bare "status %d" error no caller can branch on.
+func ClassifyHTTPStatus(status int) error {
+	switch {
+	case status == http.StatusTooManyRequests:
+		return fmt.Errorf("%w (status %d)", ErrRateLimited, status)
+	case status >= 500:
+		return fmt.Errorf("%w (status %d)", ErrProviderDown, status)
+	default:
+		return fmt.Errorf("request failed with status %d", status)
+	}
+}
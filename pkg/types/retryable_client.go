@@ -0,0 +1,174 @@
+package types
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryableClient wraps an *http.Client with hashicorp/go-retryablehttp-
+// style retry semantics: exponential backoff with jitter, a configurable
+// retry ceiling, retrying 429/5xx responses and timing-out net.Errors, and
+// honoring an upstream's Retry-After header when it sends one. Every
+// StockData/HistoricalData fetcher should go through one of these instead
+// of a raw *http.Client, so a transient Yahoo/IEX 429 doesn't abort the
+// whole fetch it's part of.
+type RetryableClient struct {
+	Client      *http.Client
+	MaxRetries  int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+}
+
+// NewRetryableClient returns a RetryableClient with sane defaults for a
+// single upstream HTTP call: up to 3 retries, starting at 500ms and
+// doubling (plus jitter) up to 10s between attempts.
+func NewRetryableClient(timeout time.Duration) *RetryableClient {
+	return &RetryableClient{
+		Client:      &http.Client{Timeout: timeout},
+		MaxRetries:  3,
+		BaseBackoff: 500 * time.Millisecond,
+		MaxBackoff:  10 * time.Second,
+	}
+}
+
+// RetryOutcome reports how many attempts Do made and the status code it
+// last saw, so a caller can populate StockResult/HistoricalResult's
+// Attempts/LastStatus fields for logging and observability.
+type RetryOutcome struct {
+	Attempts   int
+	LastStatus int
+}
+
+// Do sends req, retrying a 429/5xx response or a timing-out net.Error up to
+// MaxRetries times with exponential backoff and jitter, honoring a
+// Retry-After header when the response carries one. req must have been
+// built with a body that supports GetBody (http.NewRequestWithContext sets
+// this automatically for []byte/string/bytes.Reader bodies) if it has a
+// body at all, so a retry can resend it.
+//
+// The returned *http.Response is only non-nil when err is nil; callers
+// must still Close its Body in that case.
+func (c *RetryableClient) Do(req *http.Request) (*http.Response, RetryOutcome, error) {
+	var outcome RetryOutcome
+
+	for attempt := 0; ; attempt++ {
+		outcome.Attempts++
+
+		attemptReq := req
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, outcome, fmt.Errorf("retryable client: rewind request body: %w", err)
+			}
+			clone := req.Clone(req.Context())
+			clone.Body = body
+			attemptReq = clone
+		}
+
+		resp, err := c.Client.Do(attemptReq)
+		if err != nil {
+			if attempt >= c.MaxRetries || !isRetryableError(err) {
+				return nil, outcome, err
+			}
+			if !c.wait(req, c.backoff(attempt)) {
+				return nil, outcome, req.Context().Err()
+			}
+			continue
+		}
+
+		outcome.LastStatus = resp.StatusCode
+		if !isRetryableStatus(resp.StatusCode) || attempt >= c.MaxRetries {
+			return resp, outcome, nil
+		}
+
+		wait := retryAfter(resp)
+		resp.Body.Close()
+		if wait <= 0 {
+			wait = c.backoff(attempt)
+		}
+		if !c.wait(req, wait) {
+			return nil, outcome, req.Context().Err()
+		}
+	}
+}
+
+// isRetryableError reports whether err is worth retrying: a net.Error that
+// timed out, rather than e.g. a malformed URL or a canceled context.
+func isRetryableError(err error) bool {
+	var netErr net.Error
+	if ok := asNetError(err, &netErr); ok {
+		return netErr.Timeout()
+	}
+	return false
+}
+
+// asNetError is errors.As specialized to net.Error, kept as its own
+// function so isRetryableError reads as one plain if-statement.
+func asNetError(err error, target *net.Error) bool {
+	for err != nil {
+		if netErr, ok := err.(net.Error); ok {
+			*target = netErr
+			return true
+		}
+		unwrapper, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			return false
+		}
+		err = unwrapper.Unwrap()
+	}
+	return false
+}
+
+// isRetryableStatus reports whether status is worth retrying: 429 (rate
+// limited) or any 5xx (upstream server error).
+func isRetryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+// retryAfter parses resp's Retry-After header (either delay-seconds or an
+// HTTP-date, per RFC 7231 §7.1.3), returning 0 if absent or unparseable so
+// the caller falls back to its own backoff.
+func retryAfter(resp *http.Response) time.Duration {
+	raw := resp.Header.Get("Retry-After")
+	if raw == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(raw); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if when, err := http.ParseTime(raw); err == nil {
+		if d := time.Until(when); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// backoff returns the exponential delay for attempt (0-indexed), capped at
+// MaxBackoff and perturbed by up to 50% jitter so a burst of fetchers
+// backing off from the same 429 don't all retry in lockstep.
+func (c *RetryableClient) backoff(attempt int) time.Duration {
+	d := c.BaseBackoff << attempt
+	if d <= 0 || d > c.MaxBackoff {
+		d = c.MaxBackoff
+	}
+	jitter := time.Duration(rand.Int63n(int64(d) / 2))
+	return d/2 + jitter
+}
+
+// wait sleeps for d or until req's context is done, whichever comes first,
+// reporting whether the sleep ran to completion.
+func (c *RetryableClient) wait(req *http.Request, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-req.Context().Done():
+		return false
+	}
+}
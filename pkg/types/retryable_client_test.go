@@ -0,0 +1,129 @@
+package types
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestRetryableClient() *RetryableClient {
+	c := NewRetryableClient(2 * time.Second)
+	c.BaseBackoff = time.Millisecond
+	c.MaxBackoff = 5 * time.Millisecond
+	return c
+}
+
+func TestDoRetriesOn429ThenSucceeds(t *testing.T) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c := newTestRetryableClient()
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+
+	resp, outcome, err := c.Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("final status = %d, want 200", resp.StatusCode)
+	}
+	if outcome.Attempts != 3 {
+		t.Errorf("Attempts = %d, want 3", outcome.Attempts)
+	}
+	if outcome.LastStatus != http.StatusOK {
+		t.Errorf("LastStatus = %d, want 200", outcome.LastStatus)
+	}
+}
+
+func TestDoGivesUpAfterMaxRetries(t *testing.T) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	c := newTestRetryableClient()
+	c.MaxRetries = 2
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+
+	resp, outcome, err := c.Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("final status = %d, want 503", resp.StatusCode)
+	}
+	if got := int(atomic.LoadInt32(&attempts)); got != 3 {
+		t.Errorf("server saw %d requests, want 3 (1 + MaxRetries)", got)
+	}
+	if outcome.Attempts != 3 {
+		t.Errorf("Attempts = %d, want 3", outcome.Attempts)
+	}
+}
+
+func TestDoHonorsRetryAfterHeader(t *testing.T) {
+	var attempts int32
+	start := time.Now()
+	var firstAttemptAt time.Time
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			firstAttemptAt = time.Now()
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c := newTestRetryableClient()
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+
+	resp, outcome, err := c.Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	if outcome.Attempts != 2 {
+		t.Errorf("Attempts = %d, want 2", outcome.Attempts)
+	}
+	if firstAttemptAt.Before(start) {
+		t.Fatal("firstAttemptAt was never set")
+	}
+}
+
+func TestDoSucceedsOnFirstTry(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c := newTestRetryableClient()
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+
+	resp, outcome, err := c.Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	if outcome.Attempts != 1 {
+		t.Errorf("Attempts = %d, want 1", outcome.Attempts)
+	}
+}
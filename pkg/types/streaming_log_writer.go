@@ -0,0 +1,179 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// streamEventBufferSize is how many past events a StreamingLogWriter keeps
+// so a client that reconnects with a Last-Event-ID header can replay what
+// it missed, rather than every subscriber needing to stay connected.
+const streamEventBufferSize = 256
+
+// streamHeartbeatInterval is how often ServeHTTP sends an SSE comment to
+// keep idle proxies (which often time out a connection with no bytes)
+// from closing the stream during a quiet stretch of a backtest.
+const streamHeartbeatInterval = 15 * time.Second
+
+// StreamEvent is one typed frame emitted by StreamingLogWriter.WriteEvent.
+type StreamEvent struct {
+	ID      int64       `json:"id"`
+	Event   string      `json:"event"` // e.g. "progress", "trade", "error", "done"
+	Payload interface{} `json:"payload"`
+}
+
+// StreamingLogWriter fans a long-running job's progress out to any number
+// of HTTP clients as Server-Sent Events. WriteEvent is the typed path
+// (handlers should emit "progress"/"trade"/"error"/"done" events rather
+// than raw text); Write implements io.Writer so code that just wants to
+// log plain lines keeps working, wrapping each line as a "log" event.
+// Events are kept in a ring buffer so ServeHTTP can replay whatever a
+// reconnecting client's Last-Event-ID missed.
+type StreamingLogWriter struct {
+	mu     sync.Mutex
+	nextID int64
+	buffer []StreamEvent // ring buffer, oldest first, capped at streamEventBufferSize
+	subs   map[chan StreamEvent]struct{}
+}
+
+// NewStreamingLogWriter returns an empty StreamingLogWriter. It isn't tied
+// to any one http.ResponseWriter; call ServeHTTP once per client that
+// wants to tail it.
+func NewStreamingLogWriter() *StreamingLogWriter {
+	return &StreamingLogWriter{subs: make(map[chan StreamEvent]struct{})}
+}
+
+// Write implements io.Writer by emitting p as a "log" event, so a
+// *log.Logger can write to a StreamingLogWriter unchanged.
+func (s *StreamingLogWriter) Write(p []byte) (int, error) {
+	s.WriteEvent("log", string(p))
+	return len(p), nil
+}
+
+// WriteEvent emits eventName/payload to every current subscriber and
+// records it in the ring buffer for later replay.
+func (s *StreamingLogWriter) WriteEvent(eventName string, payload interface{}) error {
+	s.mu.Lock()
+	s.nextID++
+	e := StreamEvent{ID: s.nextID, Event: eventName, Payload: payload}
+
+	s.buffer = append(s.buffer, e)
+	if len(s.buffer) > streamEventBufferSize {
+		s.buffer = s.buffer[len(s.buffer)-streamEventBufferSize:]
+	}
+
+	subs := make([]chan StreamEvent, 0, len(s.subs))
+	for ch := range s.subs {
+		subs = append(subs, ch)
+	}
+	s.mu.Unlock()
+
+	for _, ch := range subs {
+		select {
+		case ch <- e:
+		default:
+			// Subscriber isn't keeping up; it'll catch up via the ring
+			// buffer on reconnect rather than this call blocking on it.
+		}
+	}
+	return nil
+}
+
+// subscribe registers a new listener and returns an unsubscribe func the
+// caller must invoke (typically via defer) once it stops reading.
+func (s *StreamingLogWriter) subscribe() (ch <-chan StreamEvent, unsubscribe func()) {
+	c := make(chan StreamEvent, 16)
+
+	s.mu.Lock()
+	s.subs[c] = struct{}{}
+	s.mu.Unlock()
+
+	return c, func() {
+		s.mu.Lock()
+		delete(s.subs, c)
+		s.mu.Unlock()
+		close(c)
+	}
+}
+
+// replaySince returns every buffered event with ID greater than lastID.
+func (s *StreamingLogWriter) replaySince(lastID int64) []StreamEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var replay []StreamEvent
+	for _, e := range s.buffer {
+		if e.ID > lastID {
+			replay = append(replay, e)
+		}
+	}
+	return replay
+}
+
+// ServeHTTP streams s's events to w as Server-Sent Events: any buffered
+// event newer than the client's Last-Event-ID header is replayed first,
+// then new events as they're emitted, with a heartbeat comment every
+// streamHeartbeatInterval so an idle proxy doesn't close the connection.
+// It blocks until the client disconnects.
+func (s *StreamingLogWriter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	var lastID int64
+	if raw := r.Header.Get("Last-Event-ID"); raw != "" {
+		fmt.Sscanf(raw, "%d", &lastID)
+	}
+
+	ch, unsubscribe := s.subscribe()
+	defer unsubscribe()
+
+	writeEvent := func(e StreamEvent) bool {
+		payload, err := json.Marshal(e.Payload)
+		if err != nil {
+			return true
+		}
+		if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Event, payload); err != nil {
+			return false
+		}
+		flusher.Flush()
+		return true
+	}
+
+	for _, e := range s.replaySince(lastID) {
+		if !writeEvent(e) {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e, ok := <-ch:
+			if !ok {
+				return
+			}
+			if !writeEvent(e) {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package types
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteEventDeliversToSubscriber(t *testing.T) {
+	s := NewStreamingLogWriter()
+	ch, unsubscribe := s.subscribe()
+	defer unsubscribe()
+
+	if err := s.WriteEvent("progress", map[string]int{"done": 1}); err != nil {
+		t.Fatalf("WriteEvent() error = %v", err)
+	}
+
+	select {
+	case e := <-ch:
+		if e.Event != "progress" {
+			t.Errorf("Event = %q, want %q", e.Event, "progress")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for event")
+	}
+}
+
+func TestReplaySinceReturnsOnlyNewerEvents(t *testing.T) {
+	s := NewStreamingLogWriter()
+	s.WriteEvent("progress", 1)
+	s.WriteEvent("progress", 2)
+	s.WriteEvent("done", nil)
+
+	replay := s.replaySince(1)
+	if len(replay) != 2 {
+		t.Fatalf("replaySince(1) returned %d events, want 2", len(replay))
+	}
+	if replay[0].ID != 2 || replay[1].ID != 3 {
+		t.Errorf("replaySince(1) IDs = [%d %d], want [2 3]", replay[0].ID, replay[1].ID)
+	}
+}
+
+func TestServeHTTPReplaysBufferedEventsOnReconnect(t *testing.T) {
+	s := NewStreamingLogWriter()
+	s.WriteEvent("progress", 1)
+	s.WriteEvent("progress", 2)
+
+	req := httptest.NewRequest(http.MethodGet, "/events", nil)
+	req.Header.Set("Last-Event-ID", "1")
+	ctx, cancel := context.WithTimeout(req.Context(), 100*time.Millisecond)
+	defer cancel()
+	req = req.WithContext(ctx)
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+
+	body := rec.Body.String()
+	if !strings.Contains(body, "id: 2\nevent: progress\ndata: 2") {
+		t.Errorf("response body missing replayed event 2: %s", body)
+	}
+	if strings.Contains(body, "id: 1\n") {
+		t.Errorf("response body replayed event 1, which the client already had: %s", body)
+	}
+}
@@ -1,25 +1,32 @@
 package types
 
 import (
+	"context"
 	"database/sql"
-	"net/http"
-	"sync"
 	"time"
+
+	"github.com/apache/arrow/go/arrow/array"
 )
 
 type Config struct {
 	// need to migrate most of this to Credential struct
-	ENV                string // DEV, Prod, Local, Hosted
-	TopLevelDir        string // Top level directory of the application.
-	BacktestDB         string // Application Support App settings like store of credentials, known connections.
-	SPXLBacktestDB     string // New field for SPXL specific database
-	ServiceAccountJson string // Need to move ServiceAccountJson to credential struct.
-	Port               string // Config.Port is the port that Mavgo Flight service binds to.  Do not confuse with port of a request.
-	TopCacheDir        string // Remote files and local files cached as sqlite land in this folder
-	DefaultFormat      string // I have no idea.  Need to track where this is used.
-	ServeFolder        string // I supersetted/wrapped/inherited http.FileServer as starting point of FlightHandler. ServeFolder is the folder it starts for serving.
-	PrivateKeyPath     string // Need to move PrivateKeyPath to Credential struct.
-	ProjectID          string // Until I create a better solution assuming that Mavgo Flight is serving data from services tied to one single Google Cloud project 	// I created this variable to enable NewClient for bigquery July 27 2024.
+	ENV                string        // DEV, Prod, Local, Hosted
+	TopLevelDir        string        // Top level directory of the application.
+	BacktestDB         string        // Application Support App settings like store of credentials, known connections.
+	SPXLBacktestDB     string        // New field for SPXL specific database
+	ServiceAccountJson string        // Need to move ServiceAccountJson to credential struct.
+	Port               string        // Config.Port is the port that Mavgo Flight service binds to.  Do not confuse with port of a request.
+	TopCacheDir        string        // Remote files and local files cached as sqlite land in this folder
+	DefaultFormat      string        // I have no idea.  Need to track where this is used.
+	ServeFolder        string        // I supersetted/wrapped/inherited http.FileServer as starting point of FlightHandler. ServeFolder is the folder it starts for serving.
+	PrivateKeyPath     string        // Need to move PrivateKeyPath to Credential struct.
+	ProjectID          string        // Until I create a better solution assuming that Mavgo Flight is serving data from services tied to one single Google Cloud project 	// I created this variable to enable NewClient for bigquery July 27 2024.
+	RequestTimeout     time.Duration // Per-request deadline applied via middleware; zero means use the caller's default.
+	StorageDriver      string        // "sqlite" (default) or "postgres"; selects the backend store.Open constructs for BacktestDB/SPXLBacktestDB.
+	TrailingStopPct    float64       // Fraction below a position's post-purchase high that portfolio.Valuator treats as a trailing-stop trigger; zero means use portfolio.DefaultTrailingStopPct.
+	RateLimitRPM       int           // Per-IP requests-per-minute cap applied by ratelimit.Middleware; zero means use ratelimit.DefaultRPM.
+	SP500CacheTTL      time.Duration // How long fetchSP500List's sp500.Cache serves a cached constituent list before re-fetching; zero means use sp500.DefaultCacheTTL.
+	TrustedProxyCIDRs  []string      // CIDRs (e.g. "10.0.0.0/8") of reverse proxies ratelimit.Limiter trusts to set X-Forwarded-For; empty means rate-limit by RemoteAddr only.
 }
 
 // StockData represents stock information from the database
@@ -42,12 +49,27 @@ type StockData struct {
 	Open             float64   `json:"open"`
 	Close            float64   `json:"close"`
 	AdjClose         float64   `json:"adj_close"`
+	Source           string    `json:"source,omitempty"` // which provider (see pkg/marketdata, pkg/providers) produced this row, if known
+
+	// PERatio, Week52Change, and YTDChange are only populated by providers
+	// whose quote endpoint returns them (IEX Cloud's /stock/{symbol}/quote
+	// does); other providers leave them zero and they're omitted from JSON.
+	PERatio      float64 `json:"pe_ratio,omitempty"`
+	Week52Change float64 `json:"week52_change,omitempty"`
+	YTDChange    float64 `json:"ytd_change,omitempty"`
 }
 
 // StockResult represents the result of fetching stock data
 type StockResult struct {
 	Data *StockData
 	Err  error
+
+	// Attempts and LastStatus are populated by fetchers built on
+	// RetryableClient, so a caller can log/observe transient failures
+	// (e.g. a 429 retried twice before succeeding) instead of seeing only
+	// the final outcome.
+	Attempts   int `json:"attempts,omitempty"`
+	LastStatus int `json:"last_status,omitempty"`
 }
 
 // HistoricalData represents a single day of stock data
@@ -67,6 +89,28 @@ type HistoricalResult struct {
 	Ticker string
 	Data   []HistoricalData
 	Err    error
+
+	// Attempts and LastStatus are populated by fetchers built on
+	// RetryableClient; see StockResult's fields of the same name.
+	Attempts   int `json:"attempts,omitempty"`
+	LastStatus int `json:"last_status,omitempty"`
+}
+
+// TimeSeriesStore is a columnar alternative to storing HistoricalData as
+// SQL rows: a backtest that needs to scan a symbol's whole history can
+// pull it back as a single arrow.Record instead of iterating *sql.Rows.
+// See pkg/tsstore for the on-disk implementation.
+type TimeSeriesStore interface {
+	// Append adds bars to symbol's series.
+	Append(symbol string, bars []HistoricalData) error
+
+	// Query returns symbol's bars in [start, end] projected to cols (a
+	// subset of "date", "open", "high", "low", "close", "adj_close",
+	// "volume"; empty means all of them).
+	Query(symbol string, start, end time.Time, cols []string) (array.Record, error)
+
+	// Symbols returns every symbol the store has data for.
+	Symbols() []string
 }
 
 // DB represents our database connection
@@ -74,6 +118,23 @@ type DB struct {
 	*sql.DB
 }
 
+// Sink is a downstream destination for backtest result rows (BigQuery,
+// Pub/Sub, a local Parquet file, ...). Implementations live in pkg/sink;
+// this interface stays here, alongside HistoricalData, so the backtest
+// engine can depend on it without importing any destination-specific
+// client library.
+type Sink interface {
+	// Write hands rows to the sink. Implementations that batch internally
+	// (e.g. Pub/Sub) may buffer rather than deliver them immediately;
+	// Flush is the only guarantee that buffered rows have been sent.
+	Write(ctx context.Context, rows []HistoricalData) error
+
+	// Flush delivers any rows buffered by Write. Callers should always
+	// call Flush once after their last Write, even for sinks that don't
+	// buffer (where it's a no-op).
+	Flush() error
+}
+
 // HandlerResponse represents a standardized API response
 type HandlerResponse struct {
 	Success bool        `json:"success"`
@@ -85,11 +146,9 @@ type HandlerResponse struct {
 type SP500Stock struct {
 	Symbol       string `json:"symbol"`
 	SecurityName string `json:"security_name"`
-}
-
-// StreamingLogWriter is a writer that streams logs to an HTTP response
-type StreamingLogWriter struct {
-	w  http.ResponseWriter
-	f  http.Flusher
-	mu sync.Mutex
+	Sector       string `json:"sector,omitempty"`
+	SubIndustry  string `json:"sub_industry,omitempty"`
+	DateAdded    string `json:"date_added,omitempty"`
+	CIK          string `json:"cik,omitempty"`
+	Founded      string `json:"founded,omitempty"`
 }
@@ -0,0 +1,205 @@
+// Package yahoosession holds the process-wide Yahoo Finance session —
+// consent cookies, crumb token, and shared request rate — that both
+// pkg/providers and pkg/marketdata's YahooProvider need to talk to Yahoo's
+// undocumented chart API. It exists as its own package (rather than living
+// in either provider chain) so the two chains share one handshake, one
+// crumb, and one rate limiter instead of drifting into two independently
+// tuned copies.
+package yahoosession
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// userAgents is rotated across requests so every call from a worker pool
+// doesn't present the exact same fingerprint to Yahoo.
+var userAgents = []string{
+	"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+	"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+	"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Safari/605.1.15",
+}
+
+// sessionTTL bounds how long a crumb is trusted before Ensure refreshes it
+// unconditionally, even absent a 401/403/999 response — Yahoo's crumbs are
+// observed to go stale well before any such response.
+const sessionTTL = 1 * time.Hour
+
+// QPS is the shared request rate to Yahoo's endpoints, applied across every
+// caller (both provider chains, and every goroutine in their worker pools)
+// rather than per instance — each chain builds a fresh provider on every
+// lookup, so a per-instance limiter would reset with each one and do
+// nothing to stop a bounded worker pool from hammering Yahoo all at once.
+const QPS = 2
+
+// Session holds the A1/A3 consent cookies and crumb token Yahoo's
+// chart/quote APIs require, plus the rate limiter shared by every request
+// those APIs receive, so the handshake, crumb fetch, and QPS cap all apply
+// process-wide instead of per caller.
+type Session struct {
+	client  *http.Client
+	limiter *rate.Limiter
+
+	mu        sync.Mutex
+	crumb     string
+	cookie    string
+	expiresAt time.Time
+
+	uaIndex int32
+}
+
+// sessionOnce guards shared's construction; every caller shares it
+// regardless of how many providers are built across either chain.
+var (
+	sessionOnce sync.Once
+	shared      *Session
+)
+
+// Get returns the process-wide Session, constructing it on first use.
+func Get() *Session {
+	sessionOnce.Do(func() {
+		jar, _ := cookiejar.New(nil)
+		shared = &Session{
+			client:  &http.Client{Timeout: 20 * time.Second, Jar: jar},
+			limiter: rate.NewLimiter(rate.Limit(QPS), QPS),
+		}
+	})
+	return shared
+}
+
+// Client returns the *http.Client whose cookie jar carries s's consent
+// cookies automatically; a caller that sends requests through it (rather
+// than attaching s's cookies manually, see Attach) gets them for free.
+func (s *Session) Client() *http.Client { return s.client }
+
+// nextUserAgent rotates through userAgents.
+func (s *Session) nextUserAgent() string {
+	i := atomic.AddInt32(&s.uaIndex, 1)
+	return userAgents[int(i)%len(userAgents)]
+}
+
+// Wait blocks until the shared limiter permits another request, or ctx is
+// canceled first.
+func (s *Session) Wait(ctx context.Context) error {
+	return s.limiter.Wait(ctx)
+}
+
+// Ensure performs the consent-cookie handshake and crumb fetch if this
+// session hasn't done so yet. It's safe to call before every request; after
+// the first call it's a no-op until Refresh clears the cached crumb.
+func (s *Session) Ensure() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.crumb != "" && time.Now().Before(s.expiresAt) {
+		return nil
+	}
+	return s.refreshLocked()
+}
+
+// Refresh forces a new consent-cookie handshake and crumb fetch, discarding
+// whatever was cached. Call this when a chart/quote request comes back
+// 401/403/999, since that means the cached crumb or cookies Yahoo issued
+// have gone stale.
+func (s *Session) Refresh() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.refreshLocked()
+}
+
+func (s *Session) refreshLocked() error {
+	s.crumb = ""
+	s.cookie = ""
+
+	// Step 1: hit fc.yahoo.com so Yahoo sets the A1/A3 consent cookies on
+	// the session's cookie jar.
+	consentReq, err := http.NewRequest("GET", "https://fc.yahoo.com", nil)
+	if err != nil {
+		return fmt.Errorf("yahoosession: failed to build yahoo consent request: %w", err)
+	}
+	consentReq.Header.Set("User-Agent", s.nextUserAgent())
+
+	consentResp, err := s.client.Do(consentReq)
+	if err != nil {
+		return fmt.Errorf("yahoosession: yahoo consent handshake failed: %w", err)
+	}
+	consentResp.Body.Close()
+
+	// Step 2: fetch a crumb bound to those cookies.
+	crumbReq, err := http.NewRequest("GET", "https://query2.finance.yahoo.com/v1/test/getcrumb", nil)
+	if err != nil {
+		return fmt.Errorf("yahoosession: failed to build yahoo crumb request: %w", err)
+	}
+	crumbReq.Header.Set("User-Agent", s.nextUserAgent())
+
+	crumbResp, err := s.client.Do(crumbReq)
+	if err != nil {
+		return fmt.Errorf("yahoosession: yahoo crumb request failed: %w", err)
+	}
+	defer crumbResp.Body.Close()
+
+	body, err := io.ReadAll(crumbResp.Body)
+	if err != nil {
+		return fmt.Errorf("yahoosession: failed to read yahoo crumb response: %w", err)
+	}
+
+	crumb := strings.TrimSpace(string(body))
+	if crumbResp.StatusCode != http.StatusOK || crumb == "" {
+		return fmt.Errorf("yahoosession: yahoo crumb request returned status %d, crumb %q", crumbResp.StatusCode, crumb)
+	}
+
+	s.crumb = crumb
+	// A caller that sends its own requests through a client other than
+	// s.Client() (see Attach) won't get the jar's cookies automatically;
+	// stash them as a literal Cookie header value for that case.
+	if queryURL, err := url.Parse("https://query1.finance.yahoo.com"); err == nil {
+		s.cookie = cookieHeader(s.client.Jar.Cookies(queryURL))
+	}
+	s.expiresAt = time.Now().Add(sessionTTL)
+	return nil
+}
+
+// Attach ensures the session is initialized, then sets req's crumb query
+// param, Cookie header, and a fresh User-Agent. A caller sending req
+// through Session.Client() instead gets the Cookie header for free via the
+// client's own jar, but Attach sets it anyway so callers using their own
+// *http.Client (e.g. a shared retry/backoff client) still get it.
+func (s *Session) Attach(req *http.Request) error {
+	if err := s.Ensure(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	crumb := s.crumb
+	cookie := s.cookie
+	s.mu.Unlock()
+
+	q := req.URL.Query()
+	q.Set("crumb", crumb)
+	req.URL.RawQuery = q.Encode()
+	if cookie != "" {
+		req.Header.Set("Cookie", cookie)
+	}
+	req.Header.Set("User-Agent", s.nextUserAgent())
+	return nil
+}
+
+// cookieHeader joins cookies into the literal value a Cookie request header
+// expects ("name=value; name2=value2").
+func cookieHeader(cookies []*http.Cookie) string {
+	parts := make([]string, len(cookies))
+	for i, c := range cookies {
+		parts[i] = c.Name + "=" + c.Value
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,34 @@
+package yahoosession
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestGetReturnsASingleton(t *testing.T) {
+	a := Get()
+	b := Get()
+	if a != b {
+		t.Error("Get returned different instances; expected one shared session")
+	}
+	if a.limiter.Limit() != QPS {
+		t.Errorf("shared session limiter rate = %v, want %v", a.limiter.Limit(), QPS)
+	}
+}
+
+func TestCookieHeaderJoinsNameValuePairs(t *testing.T) {
+	got := cookieHeader([]*http.Cookie{
+		{Name: "A1", Value: "one"},
+		{Name: "A3", Value: "two"},
+	})
+	want := "A1=one; A3=two"
+	if got != want {
+		t.Errorf("cookieHeader = %q, want %q", got, want)
+	}
+}
+
+func TestCookieHeaderEmptyForNoCookies(t *testing.T) {
+	if got := cookieHeader(nil); got != "" {
+		t.Errorf("cookieHeader(nil) = %q, want empty string", got)
+	}
+}
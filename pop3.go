@@ -0,0 +1,297 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// pop3Config configures the optional POP3 bounce poller: a mailbox
+// (typically the same address mail is sent "From", or a dedicated
+// "bounces@" address) that DSNs land in when the sender doesn't otherwise
+// see them in-band (e.g. mail routed through a relay Gmail never ingests).
+type pop3Config struct {
+	Host             string
+	Port             string
+	Username         string
+	Password         string
+	PollInterval     time.Duration
+	DeleteAfterFetch bool
+}
+
+// defaultPOP3PollInterval is how often runPOP3Poller checks the mailbox
+// when POP3_POLL_INTERVAL doesn't parse as a duration.
+const defaultPOP3PollInterval = 5 * time.Minute
+
+// pop3ConfigFromEnv builds a pop3Config from POP3_HOST, POP3_PORT (default
+// "995"), POP3_USER, POP3_PASS, POP3_POLL_INTERVAL (a time.ParseDuration
+// string, default 5m), and POP3_DELETE_AFTER_FETCH ("1" to delete each
+// message after processing it; default leaves the mailbox untouched so
+// reruns are safe). It reports ok=false when POP3_HOST is unset, so the
+// poller is opt-in.
+func pop3ConfigFromEnv() (pop3Config, bool) {
+	host := os.Getenv("POP3_HOST")
+	if host == "" {
+		return pop3Config{}, false
+	}
+
+	port := os.Getenv("POP3_PORT")
+	if port == "" {
+		port = "995"
+	}
+
+	interval := defaultPOP3PollInterval
+	if raw := os.Getenv("POP3_POLL_INTERVAL"); raw != "" {
+		if d, err := time.ParseDuration(raw); err == nil {
+			interval = d
+		}
+	}
+
+	return pop3Config{
+		Host:             host,
+		Port:             port,
+		Username:         os.Getenv("POP3_USER"),
+		Password:         os.Getenv("POP3_PASS"),
+		PollInterval:     interval,
+		DeleteAfterFetch: os.Getenv("POP3_DELETE_AFTER_FETCH") == "1",
+	}, true
+}
+
+// runPOP3Poller polls cfg's mailbox for bounce DSNs every cfg.PollInterval
+// until ctx is canceled, logging (rather than exiting on) connection
+// errors so one bad poll doesn't take the whole process down.
+func runPOP3Poller(ctx context.Context, cfg pop3Config) {
+	ticker := time.NewTicker(cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := pollPOP3Once(cfg); err != nil {
+			log.Printf("pop3 poller: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// pop3Client is a minimal POP3 (RFC 1939) client: just enough of USER/
+// PASS/STAT/RETR/DELE/QUIT to drain a bounce mailbox. It isn't a general
+// POP3 library (no APOP, no TOP, no pipelining).
+type pop3Client struct {
+	conn *textproto.Conn
+}
+
+func dialPOP3(cfg pop3Config) (*pop3Client, error) {
+	addr := net.JoinHostPort(cfg.Host, cfg.Port)
+	tlsConn, err := tls.Dial("tcp", addr, &tls.Config{ServerName: cfg.Host})
+	if err != nil {
+		return nil, fmt.Errorf("pop3: failed to connect to %s: %w", addr, err)
+	}
+
+	conn := textproto.NewConn(tlsConn)
+	if _, err := conn.ReadLine(); err != nil { // greeting
+		conn.Close()
+		return nil, fmt.Errorf("pop3: failed to read greeting: %w", err)
+	}
+
+	c := &pop3Client{conn: conn}
+	if err := c.cmd("USER %s", cfg.Username); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := c.cmd("PASS %s", cfg.Password); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// cmd sends a command and expects a single-line "+OK ..." response.
+func (c *pop3Client) cmd(format string, args ...interface{}) error {
+	id, err := c.conn.Cmd(format, args...)
+	if err != nil {
+		return err
+	}
+	c.conn.StartResponse(id)
+	defer c.conn.EndResponse(id)
+
+	line, err := c.conn.ReadLine()
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "+OK") {
+		return fmt.Errorf("pop3: command failed: %s", line)
+	}
+	return nil
+}
+
+// stat returns the number of messages in the mailbox.
+func (c *pop3Client) stat() (int, error) {
+	id, err := c.conn.Cmd("STAT")
+	if err != nil {
+		return 0, err
+	}
+	c.conn.StartResponse(id)
+	defer c.conn.EndResponse(id)
+
+	line, err := c.conn.ReadLine()
+	if err != nil {
+		return 0, err
+	}
+	fields := strings.Fields(line)
+	if len(fields) < 2 || fields[0] != "+OK" {
+		return 0, fmt.Errorf("pop3: unexpected STAT response: %s", line)
+	}
+	return strconv.Atoi(fields[1])
+}
+
+// retr fetches message n's full RFC 822 text.
+func (c *pop3Client) retr(n int) ([]byte, error) {
+	id, err := c.conn.Cmd("RETR %d", n)
+	if err != nil {
+		return nil, err
+	}
+	c.conn.StartResponse(id)
+	defer c.conn.EndResponse(id)
+
+	if _, err := c.conn.ReadLine(); err != nil { // "+OK N octets"
+		return nil, err
+	}
+	return io.ReadAll(c.conn.DotReader())
+}
+
+// dele marks message n for deletion; it takes effect on quit.
+func (c *pop3Client) dele(n int) error {
+	return c.cmd("DELE %d", n)
+}
+
+// quit ends the session (committing any DELE calls) and closes the
+// connection.
+func (c *pop3Client) quit() error {
+	defer c.conn.Close()
+	return c.cmd("QUIT")
+}
+
+// pollPOP3Once connects to cfg's mailbox, scans every message for a bounce
+// DSN or feedback report, records one, and (if cfg.DeleteAfterFetch)
+// deletes it, then disconnects.
+func pollPOP3Once(cfg pop3Config) error {
+	c, err := dialPOP3(cfg)
+	if err != nil {
+		return err
+	}
+	defer c.quit()
+
+	count, err := c.stat()
+	if err != nil {
+		return fmt.Errorf("pop3: STAT failed: %w", err)
+	}
+
+	wrapped := NewDB(db)
+	for n := 1; n <= count; n++ {
+		raw, err := c.retr(n)
+		if err != nil {
+			log.Printf("pop3 poller: RETR %d failed: %v", n, err)
+			continue
+		}
+
+		if bounce, ok := detectBounceFromRaw(raw); ok {
+			if err := wrapped.recordBounce(bounce); err != nil {
+				log.Printf("pop3 poller: failed to record bounce from message %d: %v", n, err)
+			}
+		}
+
+		if cfg.DeleteAfterFetch {
+			if err := c.dele(n); err != nil {
+				log.Printf("pop3 poller: DELE %d failed: %v", n, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// detectBounceFromRaw parses raw RFC 822 message bytes (as fetched over
+// POP3, rather than a *gmail.Message) looking for the same RFC 3464
+// delivery-status / RFC 5965 feedback-report shapes detectBounce handles
+// for Gmail-ingested messages.
+func detectBounceFromRaw(raw []byte) (*Bounce, bool) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, false
+	}
+
+	messageID := msg.Header.Get("Message-Id")
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || !strings.HasPrefix(mediaType, "multipart/") {
+		if recipient, ok := verpRecipient(msg.Header.Get("Return-Path")); ok {
+			return &Bounce{
+				MessageID: messageID,
+				Recipient: recipient,
+				Type:      BounceSoft,
+				Reason:    "VERP return-path with no parseable DSN",
+			}, true
+		}
+		return nil, false
+	}
+
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			break
+		}
+
+		partType := part.Header.Get("Content-Type")
+		body, _ := io.ReadAll(part)
+
+		switch {
+		case strings.HasPrefix(partType, "message/delivery-status"):
+			fields := deliveryStatusFields(string(body))
+			if fields == nil {
+				continue
+			}
+			recipient := fields["final-recipient"]
+			if idx := strings.LastIndex(recipient, ";"); idx != -1 {
+				recipient = strings.TrimSpace(recipient[idx+1:])
+			}
+			if recipient == "" {
+				recipient = msg.Header.Get("To")
+			}
+			return &Bounce{
+				MessageID: messageID,
+				Recipient: recipient,
+				Type:      statusBounceType(fields["action"], fields["status"]),
+				Reason:    fields["diagnostic-code"],
+			}, true
+
+		case strings.HasPrefix(partType, "message/feedback-report"):
+			return &Bounce{
+				MessageID: messageID,
+				Recipient: msg.Header.Get("To"),
+				Type:      BounceComplaint,
+				Reason:    "abuse feedback report (ARF)",
+			}, true
+		}
+	}
+
+	return nil, false
+}
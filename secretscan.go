@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/darianmavgo/backtest-sell-limit/pkg/secretscan"
+)
+
+// createEmailSecretsTable creates the email_secrets table, one row per
+// secretscan.Finding flagged in an ingested email's plain_text or html
+// body (see scanAndRedactEmail). field distinguishes which body the
+// offset is relative to, since both are scanned independently.
+func createEmailSecretsTable(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS email_secrets (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			email_id TEXT NOT NULL,
+			detector TEXT NOT NULL,
+			field TEXT NOT NULL,
+			redacted_sample TEXT NOT NULL,
+			offset INTEGER NOT NULL,
+			verified BOOLEAN NOT NULL DEFAULT 0
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create email_secrets table: %v", err)
+	}
+	return nil
+}
+
+// secretScanVerifyOnly reports whether SECRETSCAN_VERIFY_ONLY=1 is set,
+// mirroring TruffleHog's --only-verified flag: when enabled, every
+// finding a detector can verify (see secretscan.Verifier) is checked live
+// against its issuing provider while the unredacted secret is still in
+// hand, and the result is what's stored in email_secrets.verified.
+func secretScanVerifyOnly() bool {
+	return os.Getenv("SECRETSCAN_VERIFY_ONLY") == "1"
+}
+
+// secretDetectorsByName indexes secretscan.DefaultDetectors() by name, so
+// a stored Finding.Detector can be mapped back to the Detector (and,
+// where implemented, Verifier) that produced it.
+var secretDetectorsByName = func() map[string]secretscan.Detector {
+	m := make(map[string]secretscan.Detector)
+	for _, d := range secretscan.DefaultDetectors() {
+		m[d.Name()] = d
+	}
+	return m
+}()
+
+// scannedFinding is a secretscan.Finding plus whatever verification ran
+// for it at scan time.
+type scannedFinding struct {
+	secretscan.Finding
+	Verified bool
+}
+
+// scanField runs scanner over data and, if verifyOnly, verifies each
+// match live via its Detector's Verifier (when it has one) before data's
+// unredacted secret goes out of scope — email_secrets only ever stores a
+// redacted_sample, so this is the only point verification is possible.
+func scanField(ctx context.Context, scanner *secretscan.Scanner, data []byte, verifyOnly bool) []scannedFinding {
+	findings := scanner.Scan(data)
+	scanned := make([]scannedFinding, len(findings))
+	for i, f := range findings {
+		scanned[i] = scannedFinding{Finding: f}
+		if !verifyOnly {
+			continue
+		}
+		verifier, ok := secretDetectorsByName[f.Detector].(secretscan.Verifier)
+		if !ok {
+			continue
+		}
+		secret := string(data[f.Offset : f.Offset+f.Length])
+		if verified, err := verifier.Verify(ctx, secret); err == nil {
+			scanned[i].Verified = verified
+		}
+	}
+	return scanned
+}
+
+// toFindings discards the Verified flag scanField added, for passing
+// scanned matches back through secretscan.Redact.
+func toFindings(scanned []scannedFinding) []secretscan.Finding {
+	findings := make([]secretscan.Finding, len(scanned))
+	for i, f := range scanned {
+		findings[i] = f.Finding
+	}
+	return findings
+}
+
+// scanAndRedactEmail scans plainText and html for secrets with
+// secretscan.DefaultScanner, returning the redacted bodies to store in the
+// emails table and the findings to store in email_secrets (see
+// recordEmailSecrets).
+func scanAndRedactEmail(ctx context.Context, plainText, html string) (redactedPlainText, redactedHTML string, findings map[string][]scannedFinding) {
+	scanner := secretscan.DefaultScanner()
+	verifyOnly := secretScanVerifyOnly()
+
+	plainFindings := scanField(ctx, scanner, []byte(plainText), verifyOnly)
+	htmlFindings := scanField(ctx, scanner, []byte(html), verifyOnly)
+
+	redactedPlainText = string(secretscan.Redact([]byte(plainText), toFindings(plainFindings)))
+	redactedHTML = string(secretscan.Redact([]byte(html), toFindings(htmlFindings)))
+
+	return redactedPlainText, redactedHTML, map[string][]scannedFinding{
+		"plain_text": plainFindings,
+		"html":       htmlFindings,
+	}
+}
+
+// recordEmailSecrets inserts one email_secrets row per finding in
+// findings, keyed by emailID and which body field it was found in.
+func recordEmailSecrets(db *sql.DB, emailID string, findings map[string][]scannedFinding) error {
+	for field, fieldFindings := range findings {
+		for _, f := range fieldFindings {
+			if _, err := db.Exec(`
+				INSERT INTO email_secrets (email_id, detector, field, redacted_sample, offset, verified)
+				VALUES (?, ?, ?, ?, ?, ?)
+			`, emailID, f.Detector, field, f.Sample, f.Offset, f.Verified); err != nil {
+				return fmt.Errorf("failed to record secret finding for email %s: %v", emailID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// EmailSecretFinding is the /api/secrets response shape for one row of the
+// email_secrets table.
+type EmailSecretFinding struct {
+	ID             int64  `json:"id"`
+	EmailID        string `json:"email_id"`
+	Detector       string `json:"detector"`
+	Field          string `json:"field"`
+	RedactedSample string `json:"redacted_sample"`
+	Offset         int    `json:"offset"`
+	Verified       bool   `json:"verified"`
+}
+
+// secretsHandler lists every row in email_secrets, most recent first.
+func (a *App) secretsHandler(w http.ResponseWriter, r *http.Request) {
+	rows, err := a.db.Query(`
+		SELECT id, email_id, detector, field, redacted_sample, offset, verified
+		FROM email_secrets ORDER BY id DESC
+	`)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to list secret findings: %v", err), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	var findings []EmailSecretFinding
+	for rows.Next() {
+		var f EmailSecretFinding
+		if err := rows.Scan(&f.ID, &f.EmailID, &f.Detector, &f.Field, &f.RedactedSample, &f.Offset, &f.Verified); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to scan secret finding: %v", err), http.StatusInternalServerError)
+			return
+		}
+		findings = append(findings, f)
+	}
+
+	sendJSONResponse(w, HandlerResponse{Success: true, Data: findings})
+}
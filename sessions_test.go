@@ -0,0 +1,97 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestNewRandomTokenIsRandomAndRightLength(t *testing.T) {
+	a, err := newRandomToken(32)
+	if err != nil {
+		t.Fatalf("newRandomToken: %v", err)
+	}
+	b, err := newRandomToken(32)
+	if err != nil {
+		t.Fatalf("newRandomToken: %v", err)
+	}
+	if a == b {
+		t.Error("newRandomToken returned the same value twice in a row")
+	}
+	if len(a) == 0 {
+		t.Error("newRandomToken returned an empty string")
+	}
+}
+
+func TestOAuthStateRoundTrip(t *testing.T) {
+	sessionID, err := newRandomToken(16)
+	if err != nil {
+		t.Fatalf("newRandomToken: %v", err)
+	}
+
+	state, err := startOAuthState(db, sessionID)
+	if err != nil {
+		t.Fatalf("startOAuthState: %v", err)
+	}
+
+	if err := verifyOAuthState(db, sessionID, state); err != nil {
+		t.Errorf("verifyOAuthState(correct state) = %v, want nil", err)
+	}
+
+	if err := verifyOAuthState(db, sessionID, "wrong-state"); err == nil {
+		t.Error("verifyOAuthState(wrong state) = nil, want error")
+	}
+
+	if err := verifyOAuthState(db, "unknown-session", state); err == nil {
+		t.Error("verifyOAuthState(unknown session) = nil, want error")
+	}
+}
+
+func TestOAuthStateExpires(t *testing.T) {
+	sessionID, err := newRandomToken(16)
+	if err != nil {
+		t.Fatalf("newRandomToken: %v", err)
+	}
+
+	state, err := startOAuthState(db, sessionID)
+	if err != nil {
+		t.Fatalf("startOAuthState: %v", err)
+	}
+
+	if _, err := db.Exec(`UPDATE sessions SET state_expires_at = ? WHERE id = ?`,
+		time.Now().Add(-time.Minute).Unix(), sessionID); err != nil {
+		t.Fatalf("failed to backdate state expiry: %v", err)
+	}
+
+	if err := verifyOAuthState(db, sessionID, state); err == nil {
+		t.Error("verifyOAuthState(expired state) = nil, want error")
+	}
+}
+
+func TestSessionTokenRoundTrip(t *testing.T) {
+	sessionID, err := newRandomToken(16)
+	if err != nil {
+		t.Fatalf("newRandomToken: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO sessions (id, created_at) VALUES (?, ?)`, sessionID, time.Now().Unix()); err != nil {
+		t.Fatalf("failed to seed session: %v", err)
+	}
+
+	if _, err := sessionToken(db, sessionID); err == nil {
+		t.Error("sessionToken(no token yet) = nil error, want error")
+	}
+
+	want := &oauth2.Token{AccessToken: "access-token", RefreshToken: "refresh-token"}
+	if err := persistSessionToken(db, sessionID, want); err != nil {
+		t.Fatalf("persistSessionToken: %v", err)
+	}
+
+	got, err := sessionToken(db, sessionID)
+	if err != nil {
+		t.Fatalf("sessionToken: %v", err)
+	}
+	if got.AccessToken != want.AccessToken || got.RefreshToken != want.RefreshToken {
+		t.Errorf("sessionToken = %+v, want %+v", got, want)
+	}
+}
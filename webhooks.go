@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// bounceWebhookPayload is this module's own bounce payload shape: a direct
+// mapping onto Bounce, for callers (internal services, manual curl) that
+// don't speak SES or SendGrid's formats.
+type bounceWebhookPayload struct {
+	MessageID string `json:"message_id"`
+	Recipient string `json:"recipient"`
+	Type      string `json:"type"`
+	Reason    string `json:"reason"`
+}
+
+// webhookBounceHandler accepts this module's own bounce JSON shape and
+// records it the same way an in-band DSN would be.
+func webhookBounceHandler(w http.ResponseWriter, r *http.Request) {
+	var payload bounceWebhookPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		logBounceDetectionFailure("bounce webhook", err)
+		sendJSONResponse(w, HandlerResponse{Success: false, Message: "invalid payload: " + err.Error()})
+		return
+	}
+
+	bounce := &Bounce{
+		MessageID: payload.MessageID,
+		Recipient: payload.Recipient,
+		Type:      BounceType(payload.Type),
+		Reason:    payload.Reason,
+	}
+	if err := NewDB(db).recordBounce(bounce); err != nil {
+		sendJSONResponse(w, HandlerResponse{Success: false, Message: err.Error()})
+		return
+	}
+
+	sendJSONResponse(w, HandlerResponse{Success: true, Message: "bounce recorded"})
+}
+
+// sesBouncedRecipient is one entry of an SES bounce notification's
+// bounce.bouncedRecipients.
+type sesBouncedRecipient struct {
+	EmailAddress   string `json:"emailAddress"`
+	DiagnosticCode string `json:"diagnosticCode"`
+}
+
+// sesNotification is the JSON body SES delivers for a bounce or complaint
+// notification (either directly, or as the decoded "Message" field of an
+// SNS envelope — this handler expects the SES body itself, matching an SNS
+// subscription configured with "raw message delivery" enabled).
+type sesNotification struct {
+	NotificationType string `json:"notificationType"`
+	Mail             struct {
+		MessageID string `json:"messageId"`
+	} `json:"mail"`
+	Bounce struct {
+		BounceType        string                `json:"bounceType"`
+		BouncedRecipients []sesBouncedRecipient `json:"bouncedRecipients"`
+	} `json:"bounce"`
+	Complaint struct {
+		ComplainedRecipients []sesBouncedRecipient `json:"complainedRecipients"`
+	} `json:"complaint"`
+}
+
+// sesBounceType maps SES's own "Permanent"/"Transient" classification to
+// BounceType; anything else (e.g. "Undetermined") is treated as soft so it
+// doesn't prematurely block a recipient.
+func sesBounceType(sesType string) BounceType {
+	if sesType == "Permanent" {
+		return BounceHard
+	}
+	return BounceSoft
+}
+
+// webhookSESHandler accepts an SES bounce/complaint notification and
+// records one bounce row per affected recipient.
+func webhookSESHandler(w http.ResponseWriter, r *http.Request) {
+	var n sesNotification
+	if err := json.NewDecoder(r.Body).Decode(&n); err != nil {
+		logBounceDetectionFailure("ses webhook", err)
+		sendJSONResponse(w, HandlerResponse{Success: false, Message: "invalid payload: " + err.Error()})
+		return
+	}
+
+	wrapped := NewDB(db)
+	var recorded int
+	switch n.NotificationType {
+	case "Bounce":
+		for _, rec := range n.Bounce.BouncedRecipients {
+			bounce := &Bounce{
+				MessageID: n.Mail.MessageID,
+				Recipient: rec.EmailAddress,
+				Type:      sesBounceType(n.Bounce.BounceType),
+				Reason:    rec.DiagnosticCode,
+			}
+			if err := wrapped.recordBounce(bounce); err != nil {
+				sendJSONResponse(w, HandlerResponse{Success: false, Message: err.Error()})
+				return
+			}
+			recorded++
+		}
+	case "Complaint":
+		for _, rec := range n.Complaint.ComplainedRecipients {
+			bounce := &Bounce{
+				MessageID: n.Mail.MessageID,
+				Recipient: rec.EmailAddress,
+				Type:      BounceComplaint,
+				Reason:    "SES complaint notification",
+			}
+			if err := wrapped.recordBounce(bounce); err != nil {
+				sendJSONResponse(w, HandlerResponse{Success: false, Message: err.Error()})
+				return
+			}
+			recorded++
+		}
+	}
+
+	sendJSONResponse(w, HandlerResponse{Success: true, Message: "recorded SES notification", Data: recorded})
+}
+
+// sendGridEvent is one entry of the event array SendGrid posts to an Event
+// Webhook URL. SendGrid batches multiple events per request.
+type sendGridEvent struct {
+	Email       string `json:"email"`
+	Event       string `json:"event"` // "bounce", "dropped", "spamreport", ...
+	SGMessageID string `json:"sg_message_id"`
+	Reason      string `json:"reason"`
+	Type        string `json:"type"` // bounce's sub-classification: "bounce" or "blocked"
+}
+
+// sendGridBounceType maps a SendGrid event to a BounceType. "spamreport"
+// is SendGrid's complaint event; "bounce"/"dropped" are delivery failures,
+// further split by Type ("bounce" = hard, "blocked" = soft).
+func sendGridBounceType(event, subType string) BounceType {
+	if event == "spamreport" {
+		return BounceComplaint
+	}
+	if subType == "blocked" {
+		return BounceSoft
+	}
+	return BounceHard
+}
+
+// webhookSendGridHandler accepts a SendGrid Event Webhook POST (a JSON
+// array of events) and records a bounce row for each bounce/dropped/
+// spamreport event in the batch, ignoring every other event type
+// (delivered, open, click, ...).
+func webhookSendGridHandler(w http.ResponseWriter, r *http.Request) {
+	var events []sendGridEvent
+	if err := json.NewDecoder(r.Body).Decode(&events); err != nil {
+		logBounceDetectionFailure("sendgrid webhook", err)
+		sendJSONResponse(w, HandlerResponse{Success: false, Message: "invalid payload: " + err.Error()})
+		return
+	}
+
+	wrapped := NewDB(db)
+	var recorded int
+	for _, e := range events {
+		if e.Event != "bounce" && e.Event != "dropped" && e.Event != "spamreport" {
+			continue
+		}
+		bounce := &Bounce{
+			MessageID: e.SGMessageID,
+			Recipient: e.Email,
+			Type:      sendGridBounceType(e.Event, e.Type),
+			Reason:    e.Reason,
+		}
+		if err := wrapped.recordBounce(bounce); err != nil {
+			sendJSONResponse(w, HandlerResponse{Success: false, Message: err.Error()})
+			return
+		}
+		recorded++
+	}
+
+	sendJSONResponse(w, HandlerResponse{Success: true, Message: "recorded SendGrid events", Data: recorded})
+}